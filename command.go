@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"product-app/persistence/migration"
+	"strconv"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+const usage = `usage:
+  product-app                     start the HTTP server (applies pending migrations)
+  product-app migrate up          apply all pending migrations
+  product-app migrate down [N]    revert the last N migrations (default 1)
+  product-app seed                insert sample categories, products and a demo user`
+
+// runCommand executes a one-off CLI command instead of starting the server.
+func runCommand(ctx context.Context, dbPool *pgxpool.Pool, args []string) error {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(ctx, dbPool, args[1:])
+	case "seed":
+		return runSeed(ctx, dbPool)
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+}
+
+func runMigrate(ctx context.Context, dbPool *pgxpool.Pool, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate direction\n%s", usage)
+	}
+
+	switch args[0] {
+	case "up":
+		return migration.Up(ctx, dbPool)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			parsedSteps, err := strconv.Atoi(args[1])
+			if err != nil || parsedSteps < 1 {
+				return fmt.Errorf("migrate down expects a positive number of steps, got %q", args[1])
+			}
+			steps = parsedSteps
+		}
+		return migration.Down(ctx, dbPool, steps)
+	default:
+		return fmt.Errorf("unknown migrate direction %q\n%s", args[0], usage)
+	}
+}
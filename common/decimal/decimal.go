@@ -0,0 +1,236 @@
+package decimal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgtype"
+)
+
+// Scale is the number of fractional digits a Decimal keeps. Prices are stored with it
+// in NUMERIC(12, 2) columns and discounts in NUMERIC(5, 2).
+const Scale = 2
+
+const unit = 100 // 10^Scale
+
+var ErrInvalidDecimal = errors.New("invalid decimal")
+
+// Decimal is an exact fixed-point number with two fractional digits, used for prices
+// and discount percentages so that amounts such as 0.10 do not pick up binary
+// floating point rounding errors. The zero value is 0.00.
+type Decimal struct {
+	hundredths int64
+}
+
+var Zero = Decimal{}
+
+func NewFromInt(value int64) Decimal {
+	return Decimal{hundredths: value * unit}
+}
+
+// NewFromFloat rounds value to two fractional digits, half away from zero. It is meant
+// for configuration values; amounts from clients and the database are parsed exactly.
+func NewFromFloat(value float64) Decimal {
+	return Decimal{hundredths: int64(math.Round(value * unit))}
+}
+
+// Parse reads a plain decimal such as "12", "-3.5" or "1999.99". More than two
+// fractional digits, exponents and thousands separators are rejected rather than
+// rounded, so a client never gets charged an amount it did not send.
+func Parse(value string) (Decimal, error) {
+	digits := value
+	negative := false
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		negative = digits[0] == '-'
+		digits = digits[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(digits, ".")
+	if (whole == "" && fraction == "") || len(fraction) > Scale || !isDigits(whole) || !isDigits(fraction) {
+		return Decimal{}, fmt.Errorf("%w %q", ErrInvalidDecimal, value)
+	}
+	fraction += strings.Repeat("0", Scale-len(fraction))
+
+	hundredths, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("%w %q: out of range", ErrInvalidDecimal, value)
+	}
+	if negative {
+		hundredths = -hundredths
+	}
+	return Decimal{hundredths: hundredths}, nil
+}
+
+// MustParse is Parse for constants; it panics on invalid input.
+func MustParse(value string) Decimal {
+	decimal, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+	return decimal
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the value with exactly two fractional digits, e.g. "1500.00".
+func (decimal Decimal) String() string {
+	sign := ""
+	hundredths := decimal.hundredths
+	if hundredths < 0 {
+		sign = "-"
+		hundredths = -hundredths
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, hundredths/unit, hundredths%unit)
+}
+
+// Cmp returns -1, 0 or +1 as decimal is less than, equal to or greater than other.
+func (decimal Decimal) Cmp(other Decimal) int {
+	switch {
+	case decimal.hundredths < other.hundredths:
+		return -1
+	case decimal.hundredths > other.hundredths:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (decimal Decimal) Sign() int {
+	return decimal.Cmp(Zero)
+}
+
+func (decimal Decimal) IsZero() bool {
+	return decimal.hundredths == 0
+}
+
+func (decimal Decimal) Add(other Decimal) Decimal {
+	return Decimal{hundredths: decimal.hundredths + other.hundredths}
+}
+
+func (decimal Decimal) Sub(other Decimal) Decimal {
+	return Decimal{hundredths: decimal.hundredths - other.hundredths}
+}
+
+// DivInt divides by divisor, rounding half away from zero; it is used for averages.
+func (decimal Decimal) DivInt(divisor int64) Decimal {
+	quotient, remainder := decimal.hundredths/divisor, decimal.hundredths%divisor
+	if remainder < 0 {
+		remainder = -remainder
+	}
+	if 2*remainder >= abs(divisor) {
+		if (decimal.hundredths < 0) != (divisor < 0) {
+			quotient--
+		} else {
+			quotient++
+		}
+	}
+	return Decimal{hundredths: quotient}
+}
+
+// ApplyDiscount returns the value reduced by percent, rounded to two fractional digits
+// half away from zero, e.g. 0.02 with a 70 percent discount is 0.01.
+func (decimal Decimal) ApplyDiscount(percent Decimal) Decimal {
+	return Decimal{hundredths: decimal.hundredths * (100*unit - percent.hundredths)}.DivInt(100 * unit)
+}
+
+func abs(value int64) int64 {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// Float64 is for logging and display only; never compute amounts with it.
+func (decimal Decimal) Float64() float64 {
+	return float64(decimal.hundredths) / unit
+}
+
+// MarshalJSON writes the value as a string, e.g. "1500.00", so JSON clients that parse
+// numbers as binary floats keep every digit.
+func (decimal Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(decimal.String())
+}
+
+// UnmarshalJSON accepts both a string and a plain JSON number, so existing clients
+// sending numeric prices keep working. null leaves the value unchanged.
+func (decimal *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	text := string(data)
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*decimal = parsed
+	return nil
+}
+
+// EncodeText lets pgx send the value as a NUMERIC parameter without going through float64.
+func (decimal Decimal) EncodeText(_ *pgtype.ConnInfo, buf []byte) ([]byte, error) {
+	return append(buf, decimal.String()...), nil
+}
+
+// DecodeText and DecodeBinary let pgx scan NUMERIC columns directly. NULL scans as
+// zero. Values with more than two fractional digits, such as an unrounded AVG, fail
+// to scan; round them in SQL.
+func (decimal *Decimal) DecodeText(ci *pgtype.ConnInfo, src []byte) error {
+	var numeric pgtype.Numeric
+	if err := numeric.DecodeText(ci, src); err != nil {
+		return err
+	}
+	return decimal.setNumeric(numeric)
+}
+
+func (decimal *Decimal) DecodeBinary(ci *pgtype.ConnInfo, src []byte) error {
+	var numeric pgtype.Numeric
+	if err := numeric.DecodeBinary(ci, src); err != nil {
+		return err
+	}
+	return decimal.setNumeric(numeric)
+}
+
+func (decimal *Decimal) setNumeric(numeric pgtype.Numeric) error {
+	if numeric.Status == pgtype.Null || numeric.Int == nil {
+		*decimal = Zero
+		return nil
+	}
+	if numeric.NaN || numeric.InfinityModifier != pgtype.None {
+		return fmt.Errorf("%w: cannot scan a non-finite numeric", ErrInvalidDecimal)
+	}
+
+	// The numeric is Int * 10^Exp; rescale it to hundredths without losing digits
+	hundredths := new(big.Int).Set(numeric.Int)
+	if shift := int64(numeric.Exp) + Scale; shift >= 0 {
+		hundredths.Mul(hundredths, new(big.Int).Exp(big.NewInt(10), big.NewInt(shift), nil))
+	} else {
+		remainder := new(big.Int)
+		hundredths.QuoRem(hundredths, new(big.Int).Exp(big.NewInt(10), big.NewInt(-shift), nil), remainder)
+		if remainder.Sign() != 0 {
+			return fmt.Errorf("%w: %s has more than %d fractional digits", ErrInvalidDecimal, numeric.Int.String()+"e"+strconv.Itoa(int(numeric.Exp)), Scale)
+		}
+	}
+	if !hundredths.IsInt64() {
+		return fmt.Errorf("%w: numeric out of range", ErrInvalidDecimal)
+	}
+	*decimal = Decimal{hundredths: hundredths.Int64()}
+	return nil
+}
@@ -0,0 +1,129 @@
+package logging
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+const (
+	textHeader = `${time_rfc3339} ${level}`
+	jsonHeader = `{"time":"${time_rfc3339_nano}","level":"${level}"}`
+)
+
+// Fields are the structured values attached to an entry, such as product_id or error.
+type Fields map[string]interface{}
+
+var levels = map[string]log.Lvl{
+	"debug": log.DEBUG,
+	"info":  log.INFO,
+	"warn":  log.WARN,
+	"error": log.ERROR,
+}
+
+var jsonFormat bool
+
+// IsValidFormat and IsValidLevel report whether Configure accepts the value.
+func IsValidFormat(format string) bool {
+	return format == FormatText || format == FormatJSON
+}
+
+func IsValidLevel(level string) bool {
+	_, ok := levels[level]
+	return ok
+}
+
+// Configure switches the global logger to the given format and minimum level. It is
+// meant to be called once at startup, before anything else logs concurrently.
+func Configure(format string, level string) error {
+	lvl, ok := levels[level]
+	if !ok {
+		return fmt.Errorf("unknown log level %q", level)
+	}
+
+	switch format {
+	case FormatText:
+		log.SetHeader(textHeader)
+		jsonFormat = false
+	case FormatJSON:
+		log.SetHeader(jsonHeader)
+		jsonFormat = true
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+
+	log.SetLevel(lvl)
+	return nil
+}
+
+func Debug(msg string, fields Fields) {
+	if jsonFormat {
+		log.Debugj(entry(msg, fields))
+		return
+	}
+	log.Debug(text(msg, fields))
+}
+
+func Info(msg string, fields Fields) {
+	if jsonFormat {
+		log.Infoj(entry(msg, fields))
+		return
+	}
+	log.Info(text(msg, fields))
+}
+
+func Warn(msg string, fields Fields) {
+	if jsonFormat {
+		log.Warnj(entry(msg, fields))
+		return
+	}
+	log.Warn(text(msg, fields))
+}
+
+func Error(msg string, fields Fields) {
+	if jsonFormat {
+		log.Errorj(entry(msg, fields))
+		return
+	}
+	log.Error(text(msg, fields))
+}
+
+// entry builds the JSON body of an entry. Errors are stored as their message since
+// encoding/json would otherwise render most of them as {}.
+func entry(msg string, fields Fields) log.JSON {
+	j := log.JSON{"msg": msg}
+	for key, value := range fields {
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		j[key] = value
+	}
+	return j
+}
+
+// text renders msg followed by the fields as key=value pairs in key order.
+func text(msg string, fields Fields) string {
+	if len(fields) == 0 {
+		return msg
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	builder.WriteString(msg)
+	for _, key := range keys {
+		fmt.Fprintf(&builder, " %s=%v", key, fields[key])
+	}
+	return builder.String()
+}
@@ -1,5 +1,12 @@
 package postgresql
 
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
 type Config struct {
 	Host                  string
 	Port                  string
@@ -8,4 +15,67 @@ type Config struct {
 	DbName                string
 	MaxConnections        string
 	MaxConnectionIdleTime string
+	// Optional read replica; ReplicaPort defaults to Port. Without ReplicaHost every
+	// query goes to the primary.
+	ReplicaHost string
+	ReplicaPort string
+}
+
+func (config Config) replicaPort() string {
+	if config.ReplicaPort == "" {
+		return config.Port
+	}
+	return config.ReplicaPort
+}
+
+// Validate reports every problem with the configuration at once so misconfiguration
+// can be fixed in a single pass.
+func (config Config) Validate() error {
+	var problems []error
+
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"Host", config.Host},
+		{"Port", config.Port},
+		{"UserName", config.UserName},
+		{"DbName", config.DbName},
+		{"MaxConnections", config.MaxConnections},
+		{"MaxConnectionIdleTime", config.MaxConnectionIdleTime},
+	}
+	for _, field := range required {
+		if field.value == "" {
+			problems = append(problems, fmt.Errorf("%s is required", field.name))
+		}
+	}
+
+	if config.Port != "" {
+		if port, err := strconv.Atoi(config.Port); err != nil || port < 1 || port > 65535 {
+			problems = append(problems, fmt.Errorf("Port must be an integer between 1 and 65535, got %q", config.Port))
+		}
+	}
+
+	if config.ReplicaPort != "" {
+		if port, err := strconv.Atoi(config.ReplicaPort); err != nil || port < 1 || port > 65535 {
+			problems = append(problems, fmt.Errorf("ReplicaPort must be an integer between 1 and 65535, got %q", config.ReplicaPort))
+		}
+	}
+
+	if config.MaxConnections != "" {
+		if maxConnections, err := strconv.Atoi(config.MaxConnections); err != nil || maxConnections < 1 {
+			problems = append(problems, fmt.Errorf("MaxConnections must be a positive integer, got %q", config.MaxConnections))
+		}
+	}
+
+	if config.MaxConnectionIdleTime != "" {
+		if _, err := time.ParseDuration(config.MaxConnectionIdleTime); err != nil {
+			problems = append(problems, fmt.Errorf("MaxConnectionIdleTime must be a duration such as \"30s\", got %q", config.MaxConnectionIdleTime))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid PostgreSQL configuration: %w", errors.Join(problems...))
+	}
+	return nil
 }
@@ -8,9 +8,34 @@ import (
 )
 
 func GetConnectionPool(context context.Context, config Config) *pgxpool.Pool {
+	if err := config.Validate(); err != nil {
+		log.Errorf("%v", err)
+		panic(err)
+	}
+
+	return connect(context, config, config.Host, config.Port, false)
+}
+
+// GetReplicaConnectionPool connects to the read replica described by ReplicaHost and
+// ReplicaPort, with the same credentials and limits as the primary. Its sessions are
+// read-only so a write routed there by mistake fails instead of diverging. It returns
+// nil when no replica is configured.
+func GetReplicaConnectionPool(context context.Context, config Config) *pgxpool.Pool {
+	if config.ReplicaHost == "" {
+		return nil
+	}
+	if err := config.Validate(); err != nil {
+		log.Errorf("%v", err)
+		panic(err)
+	}
+
+	return connect(context, config, config.ReplicaHost, config.replicaPort(), true)
+}
+
+func connect(context context.Context, config Config, host string, port string, readOnly bool) *pgxpool.Pool {
 	connString := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable statement_cache_mode=describe pool_max_conns=%s pool_max_conn_idle_time=%s",
-		config.Host,
-		config.Port,
+		host,
+		port,
 		config.UserName,
 		config.Password,
 		config.DbName,
@@ -21,10 +46,13 @@ func GetConnectionPool(context context.Context, config Config) *pgxpool.Pool {
 	if parseConfigErr != nil {
 		panic(parseConfigErr)
 	}
+	if readOnly {
+		connConfig.ConnConfig.RuntimeParams["default_transaction_read_only"] = "on"
+	}
 
 	conn, err := pgxpool.ConnectConfig(context, connConfig)
 	if err != nil {
-		log.Error("Unable to connect to database: %v\n", err)
+		log.Errorf("Unable to connect to database at %s:%s: %v", host, port, err)
 		panic(err)
 	}
 
@@ -0,0 +1,61 @@
+package postgresql
+
+import (
+	"context"
+	"product-app/common/logging"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type PoolStats struct {
+	AcquiredConns int32 `json:"acquired_conns"`
+	IdleConns     int32 `json:"idle_conns"`
+	TotalConns    int32 `json:"total_conns"`
+	MaxConns      int32 `json:"max_conns"`
+	AcquireCount  int64 `json:"acquire_count"`
+	EmptyAcquires int64 `json:"empty_acquire_count"`
+	AcquireTimeMs int64 `json:"acquire_duration_ms"`
+}
+
+func GetPoolStats(pool *pgxpool.Pool) PoolStats {
+	stat := pool.Stat()
+	return PoolStats{
+		AcquiredConns: stat.AcquiredConns(),
+		IdleConns:     stat.IdleConns(),
+		TotalConns:    stat.TotalConns(),
+		MaxConns:      stat.MaxConns(),
+		AcquireCount:  stat.AcquireCount(),
+		EmptyAcquires: stat.EmptyAcquireCount(),
+		AcquireTimeMs: stat.AcquireDuration().Milliseconds(),
+	}
+}
+
+// StartPoolStatsLogger logs pool statistics every interval until ctx is cancelled.
+// A non-positive interval disables logging.
+func StartPoolStatsLogger(ctx context.Context, pool *pgxpool.Pool, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				stats := GetPoolStats(pool)
+				logging.Info("db pool stats", logging.Fields{
+					"acquired_conns":      stats.AcquiredConns,
+					"idle_conns":          stats.IdleConns,
+					"total_conns":         stats.TotalConns,
+					"max_conns":           stats.MaxConns,
+					"empty_acquire_count": stats.EmptyAcquires,
+				})
+			}
+		}
+	}()
+}
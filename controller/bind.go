@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"product-app/common/decimal"
+	"reflect"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// bindJSON decodes the request body into target, rejecting unknown fields so that
+// typos such as "prcie" are reported instead of silently defaulting to zero.
+// Decoding errors are rewritten into field-oriented messages that don't leak Go types.
+func bindJSON(c echo.Context, target interface{}) error {
+	decoder := json.NewDecoder(c.Request().Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(target); err != nil {
+		return bindError(err)
+	}
+	if decoder.More() {
+		return errors.New("request body must contain a single JSON value")
+	}
+	return nil
+}
+
+// errBatchTooLarge is returned by bindJSONArray for an array longer than its limit.
+var errBatchTooLarge = errors.New("batch is too large")
+
+// bindJSONArray decodes a request body holding a JSON array one element at a time and
+// stops with errBatchTooLarge as soon as it holds more than maxItems elements, so an
+// oversized batch is rejected without reading the rest of the body.
+func bindJSONArray[T any](c echo.Context, maxItems int) ([]T, error) {
+	decoder := json.NewDecoder(c.Request().Body)
+	decoder.DisallowUnknownFields()
+
+	if token, err := decoder.Token(); err != nil {
+		return nil, bindError(err)
+	} else if token != json.Delim('[') {
+		return nil, errors.New("request body must be a JSON array")
+	}
+	items := []T{}
+	for decoder.More() {
+		if len(items) == maxItems {
+			return nil, errBatchTooLarge
+		}
+		var item T
+		if err := decoder.Decode(&item); err != nil {
+			return nil, bindError(err)
+		}
+		items = append(items, item)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, bindError(err)
+	}
+	if decoder.More() {
+		return nil, errors.New("request body must contain a single JSON value")
+	}
+	return items, nil
+}
+
+func bindError(err error) error {
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return errors.New("request body is required")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return errors.New("request body contains malformed JSON")
+	case errors.As(err, &syntaxError):
+		return fmt.Errorf("request body contains malformed JSON at position %d", syntaxError.Offset)
+	case errors.As(err, &typeError):
+		if typeError.Field == "" {
+			return fmt.Errorf("request body must be a JSON %s", jsonTypeName(typeError.Type))
+		}
+		return fmt.Errorf("field %q must be a %s", typeError.Field, jsonTypeName(typeError.Type))
+	case errors.Is(err, decimal.ErrInvalidDecimal):
+		// encoding/json does not report the field for errors from custom decoders
+		return fmt.Errorf("%v: prices and discounts must be numbers with at most %d decimal places", err, decimal.Scale)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return fmt.Errorf("unknown field %s", strings.TrimPrefix(err.Error(), "json: unknown field "))
+	default:
+		return errors.New("request body is invalid")
+	}
+}
+
+func jsonTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
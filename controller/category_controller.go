@@ -1,8 +1,12 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"product-app/controller/request"
+	"product-app/controller/response"
 	"product-app/domain"
+	"product-app/middleware"
 	"product-app/service"
 	"strconv"
 
@@ -11,23 +15,54 @@ import (
 
 type CategoryController struct {
 	categoryService service.ICategoryService
+	pageSize        PageSize
 }
 
-func NewCategoryController(categoryService service.ICategoryService) *CategoryController {
-	return &CategoryController{categoryService: categoryService}
+func NewCategoryController(categoryService service.ICategoryService, pageSize PageSize) *CategoryController {
+	return &CategoryController{categoryService: categoryService, pageSize: pageSize}
 }
 
 func (categoryController *CategoryController) RegisterRoutes(e *echo.Echo) {
 	e.GET("/api/v1/categories", categoryController.GetAllCategories)
+	e.GET("/api/v1/categories/tree", categoryController.GetCategoryTree)
 	e.GET("/api/v1/categories/:id", categoryController.GetCategoryById)
-	e.POST("/api/v1/categories", categoryController.AddCategory)
-	e.PUT("/api/v1/categories/:id", categoryController.UpdateCategory)
-	e.DELETE("/api/v1/categories/:id", categoryController.DeleteCategoryById)
+
+	// Protected routes (authentication and the admin role required)
+	protected := e.Group("/api/v1/categories", middleware.JWTMiddleware(), middleware.RequireRole(domain.RoleAdmin))
+	protected.POST("", categoryController.AddCategory)
+	protected.PUT("/:id", categoryController.UpdateCategory)
+	protected.DELETE("/:id", categoryController.DeleteCategoryById)
+	protected.POST("/:id/restore", categoryController.RestoreCategory)
 }
 
+// GetAllCategories pages through the categories with limit and offset; sort is ignored.
 func (categoryController *CategoryController) GetAllCategories(c echo.Context) error {
+	pagination, err := request.ParsePagination(c, categoryController.pageSize)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	pageRequest := pagination.PageRequest()
+
 	categories := categoryController.categoryService.GetAllCategories()
-	return c.JSON(http.StatusOK, categories)
+	total := int64(len(categories))
+	start := min(pageRequest.Offset, len(categories))
+	end := min(start+pageRequest.Limit, len(categories))
+
+	setPaginationHeaders(c, total, pageRequest)
+	return c.JSON(http.StatusOK, toPage(categories[start:end], total, pageRequest))
+}
+
+// GetCategoryTree returns every category nested under its parent, unpaginated.
+func (categoryController *CategoryController) GetCategoryTree(c echo.Context) error {
+	tree, err := categoryController.categoryService.GetCategoryTree()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, tree)
 }
 
 func (categoryController *CategoryController) GetCategoryById(c echo.Context) error {
@@ -58,15 +93,22 @@ func (categoryController *CategoryController) AddCategory(c echo.Context) error
 		})
 	}
 
-	if err := categoryController.categoryService.AddCategory(category); err != nil {
+	userId, _ := c.Get("user_id").(int64)
+	category.CreatedBy = &userId
+
+	created, err := categoryController.categoryService.AddCategory(category)
+	if errors.Is(err, domain.ErrCategoryNameTaken) {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
 		return c.JSON(http.StatusUnprocessableEntity, map[string]string{
 			"error": err.Error(),
 		})
 	}
 
-	return c.JSON(http.StatusCreated, map[string]string{
-		"message": "Category created successfully",
-	})
+	return c.JSON(http.StatusCreated, response.NewMutationResponse(created.Id, created))
 }
 
 func (categoryController *CategoryController) UpdateCategory(c echo.Context) error {
@@ -89,16 +131,29 @@ func (categoryController *CategoryController) UpdateCategory(c echo.Context) err
 	category.Id = int64(categoryId)
 
 	if err := categoryController.categoryService.UpdateCategory(category); err != nil {
+		if errors.Is(err, domain.ErrUncategorizedProtected) || errors.Is(err, domain.ErrCategoryNameTaken) {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": err.Error(),
+			})
+		}
 		return c.JSON(http.StatusUnprocessableEntity, map[string]string{
 			"error": err.Error(),
 		})
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{
-		"message": "Category updated successfully",
-	})
+	updated, err := categoryController.categoryService.GetCurrentById(category.Id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, response.NewMutationResponse(updated.Id, updated))
 }
 
+// DeleteCategoryById soft-deletes an empty category, which RestoreCategory can undo. With
+// ?force=true a category that still has products is deleted for good, after moving its
+// products to Uncategorized.
 func (categoryController *CategoryController) DeleteCategoryById(c echo.Context) error {
 	param := c.Param("id")
 	categoryId, err := strconv.Atoi(param)
@@ -109,13 +164,71 @@ func (categoryController *CategoryController) DeleteCategoryById(c echo.Context)
 		})
 	}
 
-	if err := categoryController.categoryService.DeleteById(int64(categoryId)); err != nil {
+	force := false
+	if forceParam := c.QueryParam("force"); forceParam != "" {
+		if force, err = strconv.ParseBool(forceParam); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "force must be true or false",
+			})
+		}
+	}
+
+	if !force {
+		err = categoryController.categoryService.DeleteById(int64(categoryId))
+		if err == nil {
+			return c.NoContent(http.StatusNoContent)
+		}
+		return categoryDeleteError(c, err)
+	}
+
+	reassigned, err := categoryController.categoryService.ForceDeleteById(int64(categoryId))
+	if err != nil {
+		return categoryDeleteError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]int64{
+		"reassigned_products": reassigned,
+	})
+}
+
+// RestoreCategory undoes the soft delete of a category and returns it.
+func (categoryController *CategoryController) RestoreCategory(c echo.Context) error {
+	param := c.Param("id")
+	categoryId, err := strconv.Atoi(param)
+
+	if err != nil || categoryId <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid category ID",
+		})
+	}
+
+	restored, err := categoryController.categoryService.RestoreCategory(int64(categoryId))
+	if errors.Is(err, domain.ErrCategoryNotFound) {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": err.Error(),
 		})
 	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
 
-	return c.JSON(http.StatusOK, map[string]string{
-		"message": "Category deleted successfully",
-	})
+	return c.JSON(http.StatusOK, response.NewMutationResponse(restored.Id, restored))
+}
+
+func categoryDeleteError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrCategoryNotEmpty), errors.Is(err, domain.ErrUncategorizedProtected):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
 }
@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"net/http"
+	"product-app/common/postgresql"
+	"product-app/domain"
+	"product-app/middleware"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+// DebugController exposes operational diagnostics to administrators
+type DebugController struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewDebugController(dbPool *pgxpool.Pool) *DebugController {
+	return &DebugController{dbPool: dbPool}
+}
+
+// RegisterRoutes registers admin-only debug routes:
+//   - GET /debug/pool - Current database connection pool statistics
+func (debugController *DebugController) RegisterRoutes(e *echo.Echo) {
+	debug := e.Group("/debug", middleware.JWTMiddleware(), middleware.RequireRole(domain.RoleAdmin))
+	debug.GET("/pool", debugController.GetPoolStats)
+}
+
+func (debugController *DebugController) GetPoolStats(c echo.Context) error {
+	return c.JSON(http.StatusOK, postgresql.GetPoolStats(debugController.dbPool))
+}
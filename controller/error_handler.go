@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"product-app/controller/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NewHTTPErrorHandler returns an error handler that answers routing errors (unknown
+// path or unsupported method) with the same ErrorResponse body the handlers use.
+// Every other error is left to echo's default handler.
+func NewHTTPErrorHandler(e *echo.Echo) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		var status int
+		var description string
+		switch {
+		case errors.Is(err, echo.ErrNotFound):
+			status = http.StatusNotFound
+			description = fmt.Sprintf("Error: no route for %s %s", c.Request().Method, c.Request().URL.Path)
+		case errors.Is(err, echo.ErrMethodNotAllowed):
+			status = http.StatusMethodNotAllowed
+			description = fmt.Sprintf("Error: method %s is not allowed for %s", c.Request().Method, c.Request().URL.Path)
+		default:
+			e.DefaultHTTPErrorHandler(err, c)
+			return
+		}
+
+		var writeErr error
+		if c.Request().Method == http.MethodHead {
+			writeErr = c.NoContent(status)
+		} else {
+			writeErr = c.JSON(status, response.ErrorResponse{ErrorDescription: description})
+		}
+		if writeErr != nil {
+			e.Logger.Error(writeErr)
+		}
+	}
+}
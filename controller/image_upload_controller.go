@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"product-app/controller/request"
+	"product-app/controller/response"
+	"product-app/domain"
+	"product-app/middleware"
+	"product-app/service"
+	"product-app/service/model"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ImageUploadController lets clients upload product images straight to object storage
+// through signed URLs instead of hosting them elsewhere.
+type ImageUploadController struct {
+	imageUploadService service.IImageUploadService
+	productService     service.IProductService
+}
+
+func NewImageUploadController(imageUploadService service.IImageUploadService, productService service.IProductService) *ImageUploadController {
+	return &ImageUploadController{
+		imageUploadService: imageUploadService,
+		productService:     productService,
+	}
+}
+
+// RegisterRoutes registers the upload routes (JWT required):
+//   - POST /api/v1/products/:id/images/upload-url - Sign an upload for a new product image
+//   - POST /api/v1/products/:id/images/confirm - Add an uploaded image to the product
+func (imageUploadController *ImageUploadController) RegisterRoutes(e *echo.Echo) {
+	protected := e.Group("/api/v1/products", middleware.JWTMiddleware())
+	protected.POST("/:id/images/upload-url", imageUploadController.CreateUploadUrl)
+	protected.POST("/:id/images/confirm", imageUploadController.ConfirmUpload)
+}
+
+func (imageUploadController *ImageUploadController) CreateUploadUrl(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	var uploadUrlRequest request.UploadUrlRequest
+	if bindErr := bindJSON(c, &uploadUrlRequest); bindErr != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: bindErr.Error(),
+		})
+	}
+
+	imageUpload, err := imageUploadController.imageUploadService.CreateUpload(int64(productId), uploadUrlRequest.MimeType)
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusCreated, response.ToImageUploadResponse(imageUpload))
+	case errors.Is(err, service.ErrUnsupportedUploadType):
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	case errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+}
+
+func (imageUploadController *ImageUploadController) ConfirmUpload(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	var uploadConfirm model.UploadConfirm
+	if bindErr := bindJSON(c, &uploadConfirm); bindErr != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: bindErr.Error(),
+		})
+	}
+
+	err = imageUploadController.imageUploadService.ConfirmUpload(int64(productId), uploadConfirm)
+	switch {
+	case err == nil:
+		product, err := imageUploadController.productService.GetCurrentById(int64(productId))
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+				ErrorDescription: err.Error(),
+			})
+		}
+		return c.JSON(http.StatusOK, response.NewMutationResponse(product.Id, response.ToResponse(product)))
+	case errors.Is(err, service.ErrInvalidUploadKey):
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	case errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	case errors.Is(err, model.ErrInvalidImage):
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+}
@@ -1,43 +1,111 @@
 package controller
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"product-app/common/decimal"
+	"product-app/common/logging"
 	"product-app/controller/request"
 	"product-app/controller/response"
+	"product-app/domain"
 	"product-app/middleware"
 	"product-app/service"
+	"product-app/service/model"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
 )
 
+// DefaultMaxPageSize is the largest limit paginated listings return when not configured.
+const DefaultMaxPageSize = 100
+
+// PageSize bounds the limit of a paginated listing, see request.PageSize.
+type PageSize = request.PageSize
+
+// DefaultMaxBatchSize is the most items a batch request may carry when not configured.
+const DefaultMaxBatchSize = 500
+
+// maxIdempotencyKeyLength mirrors the length of idempotency_keys.key.
+const maxIdempotencyKeyLength = 255
+
+// Page sizes of the product and category listings when not configured.
+var (
+	DefaultProductPageSize  = PageSize{Default: 20, Max: DefaultMaxPageSize}
+	DefaultCategoryPageSize = PageSize{Default: 50, Max: DefaultMaxPageSize}
+)
+
 // ProductController handles HTTP requests for product operations
 // It provides endpoints for CRUD operations on products with authentication support
 type ProductController struct {
-	productService service.IProductService
+	productService     service.IProductService
+	categoryService    service.ICategoryService
+	idempotencyService service.IIdempotencyService
+	pageSize           PageSize
+	maxBatchSize       int
+	// requireAuthForCreate puts product creation behind the JWT middleware; demos may turn it off
+	requireAuthForCreate bool
 }
 
 // NewProductController creates a new instance of ProductController
 // Parameters:
 //   - productService: Service interface for product business logic
+//   - categoryService: Service interface used to resolve categories by slug
+//   - idempotencyService: Service interface used to deduplicate product creation retries
+//   - pageSize: Default and largest page of the paginated listings
+//   - maxBatchSize: Most ids a batch request may list
+//   - requireAuthForCreate: Whether POST /api/v1/products needs a token
 //
 // Returns:
 //   - *ProductController: New controller instance
-func NewProductController(productService service.IProductService) *ProductController {
-	return &ProductController{productService: productService}
+func NewProductController(productService service.IProductService, categoryService service.ICategoryService, idempotencyService service.IIdempotencyService, pageSize PageSize, maxBatchSize int, requireAuthForCreate bool) *ProductController {
+	return &ProductController{
+		productService:       productService,
+		categoryService:      categoryService,
+		idempotencyService:   idempotencyService,
+		pageSize:             pageSize,
+		maxBatchSize:         maxBatchSize,
+		requireAuthForCreate: requireAuthForCreate,
+	}
 }
 
 // RegisterRoutes registers all product-related HTTP routes
 // Public routes (no authentication):
+//   - GET /api/v1/categories/:id/products - Get products by category ID
+//   - GET /api/v1/categories/:id/products/count - Count the products of a category without loading them
+//   - GET /api/v1/categories/slug/:slug/products - Get products by category slug
+//   - GET /api/v1/categories/:id/price-stats - Get min/max/avg product price of a category
+//   - GET /api/v1/products/newest - Get the most recently added products
+//   - GET /api/v1/products/featured - Get the hand-picked featured products
+//   - GET /api/v1/products/changes - Get products changed or deleted since a time, for client sync
+//   - GET /api/v1/products/by-ids - Get products by id, in the order the ids are given
+//   - GET /api/v1/products/slug/:slug - Get single product by slug
 //   - GET /api/v1/products/:id - Get single product by ID
-//   - GET /api/v1/products - Get all products (with optional store filter)
+//   - GET /api/v1/products/:id/related - Get other products from the same category
+//   - GET /api/v1/products/:id/images - Get the images of a product in display order
+//   - GET /api/v1/products/:id/availability - Tell whether a product can be bought right now
+//   - GET /api/v1/products - Get all active products (with optional q, store and categoryId filters combined, or a tag filter; admins may pass status)
 //
 // Protected routes (JWT required):
-//   - POST /api/v1/products - Create new product
+//   - POST /api/v1/products - Create new product owned by the caller (honours the Idempotency-Key header); public when requireAuthForCreate is off
 //   - PUT /api/v1/products/:id - Update product price
+//   - DELETE /api/v1/products/:id/category - Take a product out of its category
+//   - PUT /api/v1/products/:id/status - Change the lifecycle status of a product
+//   - PUT /api/v1/products/:id/featured - Flag or unflag a product as featured (admin role required)
+//   - PATCH /api/v1/products/:id - Partially update product fields
+//   - POST /api/v1/products/:id/tags - Attach tags to a product
+//   - DELETE /api/v1/products/:id/tags/:tag - Detach a tag from a product
 //   - DELETE /api/v1/products/:id - Delete product by ID
 //   - DELETE /api/v1/products/deleteAll - Delete all products
+//   - DELETE /api/v1/products - Delete a batch of products by id (admin role required)
+//   - GET /api/v1/products/stats - Catalog-wide product counts and average price (admin role required)
+//   - POST /api/v1/products/:id/images/normalize - Renumber a product's image display order without gaps (admin role required)
+//   - POST /api/v1/products/images/normalize - Renumber the image display order of every product (admin role required)
 //   - GET /api/v1/products/my-products - Get current user's products
 //
 // Parameters:
@@ -45,15 +113,46 @@ func NewProductController(productService service.IProductService) *ProductContro
 func (productController *ProductController) RegisterRoutes(e *echo.Echo) {
 	// Public routes (no authentication required)
 	e.GET("/api/v1/categories/:id/products", productController.GetProductsByCategoryId)
+	e.GET("/api/v1/categories/:id/products/count", productController.CountProductsByCategory)
+	e.GET("/api/v1/categories/slug/:slug/products", productController.GetProductsByCategorySlug)
+	e.GET("/api/v1/categories/:id/price-stats", productController.GetPriceStatsByCategory)
+	e.GET("/api/v1/products/newest", productController.GetNewestProducts)
+	e.GET("/api/v1/products/featured", productController.GetFeaturedProducts)
+	e.GET("/api/v1/products/changes", productController.GetProductChanges)
+	e.GET("/api/v1/products/by-ids", productController.GetProductsByIds)
+	e.GET("/api/v1/products/slug/:slug", productController.GetProductBySlug)
 	e.GET("/api/v1/products/:id", productController.GetProductById)
-	e.GET("/api/v1/products", productController.GetAllProducts)
-	e.POST("/api/v1/products", productController.AddProduct)
+	e.GET("/api/v1/products/:id/related", productController.GetRelatedProducts)
+	e.GET("/api/v1/products/:id/images", productController.GetProductImages)
+	e.GET("/api/v1/products/:id/availability", productController.GetProductAvailability)
+	e.GET("/api/v1/products/:id/price-history", productController.GetPriceHistory)
+	e.GET("/api/v1/products", productController.GetAllProducts, middleware.OptionalJWTMiddleware())
 
 	// Protected routes (authentication required)
 	protected := e.Group("/api/v1/products", middleware.JWTMiddleware())
+	if productController.requireAuthForCreate {
+		protected.POST("", productController.AddProduct)
+	} else {
+		// A token is still honoured so signed-in callers own what they create
+		e.POST("/api/v1/products", productController.AddProduct, middleware.OptionalJWTMiddleware())
+	}
+	// Static paths are registered before the /:id routes so "deleteAll" is never read as an id
+	protected.DELETE("/deleteAll", productController.DeleteAllProducts)
+	protected.DELETE("", productController.DeleteProductsByIds, middleware.RequireRole(domain.RoleAdmin))
+	protected.GET("/stats", productController.GetProductStats, middleware.RequireRole(domain.RoleAdmin))
+	protected.GET("/my-products", productController.GetMyProducts)
+	protected.POST("/images/normalize", productController.NormalizeAllImageOrders, middleware.RequireRole(domain.RoleAdmin))
 	protected.PUT("/:id", productController.UpdatePrice)
+	protected.PATCH("/:id", productController.PatchProduct)
+	protected.PUT("/:id/category", productController.UpdateProductCategory)
+	protected.DELETE("/:id/category", productController.ClearProductCategory)
+	protected.PUT("/:id/status", productController.UpdateProductStatus)
+	protected.PUT("/:id/featured", productController.SetFeatured, middleware.RequireRole(domain.RoleAdmin))
+	protected.POST("/:id/images", productController.AddProductImages)
+	protected.POST("/:id/images/normalize", productController.NormalizeImageOrder, middleware.RequireRole(domain.RoleAdmin))
+	protected.POST("/:id/tags", productController.AttachTags)
+	protected.DELETE("/:id/tags/:tag", productController.DetachTag)
 	protected.DELETE("/:id", productController.DeleteProductById)
-	protected.DELETE("/deleteAll", productController.DeleteAllProducts)
 }
 
 func (productController *ProductController) GetProductsByCategoryId(c echo.Context) error {
@@ -62,105 +161,1280 @@ func (productController *ProductController) GetProductsByCategoryId(c echo.Conte
 
 	if err != nil || categoryId <= 0 {
 		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-			ErrorDescription: "Error: " + err.Error(),
+			ErrorDescription: "Error: invalid category ID",
 		})
 	}
 
-	products, err := productController.productService.GetProductsByCategoryId(int64(categoryId))
+	pagination, err := request.ParsePagination(c, productController.pageSize)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Error: " + err.Error(),
+		})
+	}
+	pageRequest := pagination.PageRequest()
+	priceRange, err := parsePriceRange(c)
 	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Error: " + err.Error(),
+		})
+	}
+
+	products, total, err := productController.productService.GetProductsByCategoryId(int64(categoryId), priceRange, pageRequest)
+	if isInvalidListingRequest(err) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Error: " + err.Error(),
+		})
+	}
+	if errors.Is(err, domain.ErrCategoryNotFound) {
 		return c.JSON(http.StatusNotFound, response.ErrorResponse{
 			ErrorDescription: "Error: " + err.Error(),
 		})
 	}
-	return c.JSON(http.StatusOK, response.ToResponseList(products))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: "Error: " + err.Error(),
+		})
+	}
+	setPaginationHeaders(c, total, pageRequest)
+	return c.JSON(http.StatusOK, toPage(response.ToResponseList(products), total, pageRequest))
 }
 
-func (productController *ProductController) GetProductById(c echo.Context) error {
-	param := c.Param("id")
-	productId, err := strconv.Atoi(param)
+func (productController *ProductController) GetProductStats(c echo.Context) error {
+	productStats, err := productController.productService.GetProductStats(parseCurrency(c))
+	if errors.Is(err, model.ErrUnsupportedCurrency) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, productStats)
+}
+
+func (productController *ProductController) CountProductsByCategory(c echo.Context) error {
+	categoryId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || categoryId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Error: invalid category ID",
+		})
+	}
+
+	count, err := productController.productService.CountProductsByCategory(int64(categoryId))
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.CountResponse{Count: count})
+}
+
+func (productController *ProductController) GetPriceStatsByCategory(c echo.Context) error {
+	categoryId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || categoryId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Error: invalid category ID",
+		})
+	}
+
+	priceStats, err := productController.productService.GetPriceStatsByCategory(int64(categoryId), parseCurrency(c))
+	if errors.Is(err, model.ErrUnsupportedCurrency) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToPriceStatsResponse(priceStats))
+}
+
+func (productController *ProductController) GetProductsByCategorySlug(c echo.Context) error {
+	slug := c.Param("slug")
+
+	category, err := productController.categoryService.GetBySlug(slug)
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+
+	pagination, err := request.ParsePagination(c, productController.pageSize)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	pageRequest := pagination.PageRequest()
+	priceRange, err := parsePriceRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+
+	products, total, err := productController.productService.GetProductsByCategoryId(category.Id, priceRange, pageRequest)
+	if isInvalidListingRequest(err) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	// The slug still resolves while its category is soft-deleted
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	setPaginationHeaders(c, total, pageRequest)
+	return c.JSON(http.StatusOK, toPage(response.ToResponseList(products), total, pageRequest))
+}
 
+// GetProductById answers 400 for an id that is not a positive integer and 404 for one
+// no product has. Lookup failures are logged rather than returned to the client.
+func (productController *ProductController) GetProductById(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "id must be a positive integer",
+		})
+	}
+
+	expand, err := parseExpand(c.QueryParam("expand"))
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
 			ErrorDescription: "Error: " + err.Error(),
 		})
 	}
+	// The review aggregate is only computed when asked for, after the product is found
+	loadRating := func(productResponse *response.ProductResponse) error {
+		if !expand["rating"] {
+			return nil
+		}
+		rating, err := productController.productService.GetRating(productId)
+		productResponse.ProductRating = &rating
+		return err
+	}
+	if expand["category"] {
+		productWithCategory, err := productController.productService.GetByIdWithCategory(productId)
+		if err != nil {
+			return productLookupFailed(c, productId, err)
+		}
+		productResponse := response.ToResponseWithCategory(productWithCategory)
+		if err := loadRating(&productResponse.ProductResponse); err != nil {
+			return productLookupFailed(c, productId, err)
+		}
+		return c.JSON(http.StatusOK, productResponse)
+	}
+
+	product, err := productController.productService.GetById(productId)
+	if err != nil {
+		return productLookupFailed(c, productId, err)
+	}
+	productResponse := response.ToResponse(product)
+	if err := loadRating(&productResponse); err != nil {
+		return productLookupFailed(c, productId, err)
+	}
+	return c.JSON(http.StatusOK, productResponse)
+}
+
+// GetProductAvailability answers the product page's "can I buy this" question, so web
+// and mobile clients share one rule instead of each checking status and stock.
+func (productController *ProductController) GetProductAvailability(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "id must be a positive integer",
+		})
+	}
 
-	product, err := productController.productService.GetById(int64(productId))
+	availability, err := productController.productService.GetAvailability(productId)
 	if err != nil {
+		return productLookupFailed(c, productId, err)
+	}
+	return c.JSON(http.StatusOK, availability)
+}
+
+func productLookupFailed(c echo.Context, productId int64, err error) error {
+	if errors.Is(err, domain.ErrProductNotFound) {
 		return c.JSON(http.StatusNotFound, response.ErrorResponse{
-			ErrorDescription: "Error:  " + err.Error(),
+			ErrorDescription: fmt.Sprintf("product %d not found", productId),
 		})
 	}
-	return c.JSON(http.StatusOK, response.ToResponse(product))
+	logging.Error("error while getting product", logging.Fields{"product_id": productId, "error": err})
+	return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+		ErrorDescription: "failed to load product",
+	})
 }
 
-func (productController *ProductController) GetAllProducts(c echo.Context) error {
-	store := c.QueryParam("store")
+// productExpansions are the values accepted by ?expand= on the product detail endpoint.
+// "rating" adds average_rating and review_count, zeros for products without reviews.
+var productExpansions = map[string]bool{"category": true, "rating": true}
 
-	if len(store) == 0 {
-		allProducts := productController.productService.GetAllProducts()
-		return c.JSON(http.StatusOK, response.ToResponseList(allProducts))
+// parseExpand reads a comma separated expand list such as "category,rating".
+func parseExpand(param string) (map[string]bool, error) {
+	expand := map[string]bool{}
+	if param == "" {
+		return expand, nil
+	}
+	for _, value := range strings.Split(param, ",") {
+		value = strings.TrimSpace(value)
+		if !productExpansions[value] {
+			return nil, fmt.Errorf("unsupported expand value %s", strconv.Quote(value))
+		}
+		expand[value] = true
 	}
-	productsWithGivenStore := productController.productService.GetAllProductsByStore(store)
-	return c.JSON(http.StatusOK, response.ToResponseList(productsWithGivenStore))
+	return expand, nil
 }
 
-func (productController *ProductController) AddProduct(c echo.Context) error {
-	var addProductRequest request.AddProductRequest
-	bindErr := c.Bind(&addProductRequest)
-	if bindErr != nil {
+// GetProductsByIds answers ?ids=3,1,2 with the active products in exactly that order,
+// skipping ids that do not match one. At most maxBatchSize ids are accepted.
+func (productController *ProductController) GetProductsByIds(c echo.Context) error {
+	productIds, err := parseIds(c.QueryParam("ids"))
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-			ErrorDescription: bindErr.Error(),
+			ErrorDescription: err.Error(),
+		})
+	}
+	if len(productIds) > productController.maxBatchSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, response.ErrorResponse{
+			ErrorDescription: batchTooLargeMessage(productController.maxBatchSize),
 		})
 	}
-	err := productController.productService.Add(addProductRequest.ToModel())
 
+	products, err := productController.productService.GetByIds(productIds)
 	if err != nil {
-		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
 			ErrorDescription: err.Error(),
 		})
 	}
-	return c.NoContent(http.StatusCreated)
+	return c.JSON(http.StatusOK, response.ToResponseList(products))
 }
-func (productController *ProductController) UpdatePrice(c echo.Context) error {
-	param := c.Param("id")
-	productId, _ := strconv.Atoi(param)
 
-	newPrice := c.QueryParam("newPrice")
-	if len(newPrice) == 0 {
-		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-			ErrorDescription: "Parameter newPrice is required!",
+// parseIds reads a comma separated list of positive ids, such as "3,1,2".
+func parseIds(param string) ([]int64, error) {
+	if param == "" {
+		return nil, errors.New("ids is required")
+	}
+	values := strings.Split(param, ",")
+	ids := make([]int64, len(values))
+	for i, value := range values {
+		id, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("ids must be positive integers, got %s", strconv.Quote(value))
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func (productController *ProductController) GetProductBySlug(c echo.Context) error {
+	product, err := productController.productService.GetBySlug(c.Param("slug"))
+	if errors.Is(err, domain.ErrProductNotFound) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: "Error: " + err.Error(),
 		})
 	}
-	convertedPrice, err := strconv.ParseFloat(newPrice, 32)
 	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: "Error: " + err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToResponse(product))
+}
+
+func (productController *ProductController) GetRelatedProducts(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productId <= 0 {
 		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
-			ErrorDescription: "NewPrice Format Disrupted!",
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	limit := 4
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+				ErrorDescription: "limit must be an integer",
+			})
+		}
+	}
+
+	relatedProducts, err := productController.productService.GetRelatedProducts(int64(productId), limit)
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, response.ToResponseList(relatedProducts))
+	case errors.Is(err, service.ErrInvalidPageRequest):
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	case errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
 		})
 	}
-	productController.productService.UpdatePrice(int64(productId), float32(convertedPrice))
-	return c.NoContent(http.StatusOK)
 }
 
-func (productController *ProductController) DeleteProductById(c echo.Context) error {
-	param := c.Param("id")
-	productId, _ := strconv.Atoi(param)
-	err := productController.productService.DeleteById(int64(productId))
+func (productController *ProductController) GetNewestProducts(c echo.Context) error {
+	limit := 10
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+				ErrorDescription: "limit must be an integer",
+			})
+		}
+	}
+
+	newestProducts, err := productController.productService.GetNewestProducts(limit)
+	if errors.Is(err, service.ErrInvalidPageRequest) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
 	if err != nil {
-		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
 			ErrorDescription: err.Error(),
 		})
 	}
-	return c.NoContent(http.StatusOK)
+	return c.JSON(http.StatusOK, response.ToResponseList(newestProducts))
 }
 
-func (productController *ProductController) DeleteAllProducts(c echo.Context) error {
-	err := productController.productService.DeleteAllProducts()
+func (productController *ProductController) GetFeaturedProducts(c echo.Context) error {
+	featuredProducts, err := productController.productService.GetFeaturedProducts()
 	if err != nil {
-		log.Printf("DeleteAllProducts error: %v", err)
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToResponseList(featuredProducts))
+}
+
+func (productController *ProductController) GetPriceHistory(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	history, err := productController.productService.GetPriceHistory(int64(productId))
+	if errors.Is(err, domain.ErrProductNotFound) {
 		return c.JSON(http.StatusNotFound, response.ErrorResponse{
 			ErrorDescription: err.Error(),
 		})
 	}
-	return c.NoContent(http.StatusOK)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.EmptyIfNil(history))
+}
+
+func (productController *ProductController) GetAllProducts(c echo.Context) error {
+	if c.QueryParams().Has("status") {
+		return productController.getProductsByStatus(c)
+	}
+
+	if c.QueryParams().Has("tag") {
+		productsWithGivenTag, err := productController.productService.GetAllProductsByTag(c.QueryParam("tag"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+				ErrorDescription: err.Error(),
+			})
+		}
+		return c.JSON(http.StatusOK, response.ToResponseList(productsWithGivenTag))
+	}
+
+	if c.QueryParams().Has("createdFrom") || c.QueryParams().Has("createdTo") {
+		return productController.getProductsCreatedBetween(c)
+	}
+
+	store := c.QueryParam("store")
+	filtered := store != "" || c.QueryParams().Has("q") || c.QueryParams().Has("categoryId")
+
+	if c.QueryParams().Has("cursor") {
+		if c.QueryParams().Has("q") || c.QueryParams().Has("categoryId") {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+				ErrorDescription: "cursor can only be combined with store",
+			})
+		}
+		return productController.getProductsByCursor(c, store)
+	}
+	if !filtered {
+		return productController.getAllProducts(c)
+	}
+	return productController.getFilteredProducts(c, store)
+}
+
+// getAllProducts serves the listing without filters: every active product in one array,
+// ordered by ?sort= or, without it, the configured default sort.
+func (productController *ProductController) getAllProducts(c echo.Context) error {
+	sort := c.QueryParam("sort")
+	if sort == "" {
+		sort = productController.pageSize.Sort
+	}
+	products, _, err := productController.productService.FindProducts(model.ProductFilter{PageRequest: model.PageRequest{Sort: sort}})
+	if errors.Is(err, service.ErrInvalidPageRequest) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToResponseList(products))
+}
+
+// getProductsByCursor serves ?cursor=, keyset pagination in id order. An empty cursor
+// starts at the beginning; each page's next_cursor continues after its last product.
+func (productController *ProductController) getProductsByCursor(c echo.Context, store string) error {
+	var afterId int64
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		var err error
+		if afterId, err = strconv.ParseInt(cursor, 10, 64); err != nil || afterId < 0 {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+				ErrorDescription: "cursor must be a product id from next_cursor",
+			})
+		}
+	}
+	if c.QueryParams().Has("offset") || c.QueryParams().Has("sort") {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "cursor cannot be combined with offset or sort",
+		})
+	}
+	pagination, err := request.ParsePagination(c, productController.pageSize)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+
+	products, next, err := productController.productService.GetProductsAfter(store, afterId, pagination.Limit)
+	if errors.Is(err, service.ErrInvalidPageRequest) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+
+	page := response.CursorPage[response.ProductResponse]{Items: response.ToResponseList(products)}
+	if next != nil {
+		nextCursor := strconv.FormatInt(*next, 10)
+		page.NextCursor = &nextCursor
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+// getFilteredProducts serves any combination of ?q=, ?store= and ?categoryId= as a page
+// of the products matching all of them, with the total number of matches.
+func (productController *ProductController) getFilteredProducts(c echo.Context, store string) error {
+	pagination, err := request.ParsePagination(c, productController.pageSize)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	pageRequest := pagination.PageRequest()
+	filter := model.ProductFilter{Search: c.QueryParam("q"), Store: store, PageRequest: pageRequest}
+	if categoryParam := c.QueryParam("categoryId"); c.QueryParams().Has("categoryId") {
+		if filter.CategoryId, err = strconv.ParseInt(categoryParam, 10, 64); err != nil || filter.CategoryId <= 0 {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+				ErrorDescription: "categoryId must be a positive integer",
+			})
+		}
+	}
+
+	products, total, err := productController.productService.FindProducts(filter)
+	if errors.Is(err, service.ErrInvalidPageRequest) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	setPaginationHeaders(c, total, pageRequest)
+	return c.JSON(http.StatusOK, toPage(response.ToResponseList(products), total, pageRequest))
+}
+
+// GetMyProducts pages through the products created by the signed-in user, drafts included.
+func (productController *ProductController) GetMyProducts(c echo.Context) error {
+	userId, _ := c.Get("user_id").(int64)
+	// A zero id would drop the owner filter and list every product
+	if userId <= 0 {
+		return c.JSON(http.StatusUnauthorized, response.ErrorResponse{
+			ErrorDescription: "Error: user not authenticated",
+		})
+	}
+
+	pagination, err := request.ParsePagination(c, productController.pageSize)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	pageRequest := pagination.PageRequest()
+
+	products, total, err := productController.productService.GetProductsByUser(userId, pageRequest)
+	if errors.Is(err, service.ErrInvalidPageRequest) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	setPaginationHeaders(c, total, pageRequest)
+	return c.JSON(http.StatusOK, toPage(response.ToResponseList(products), total, pageRequest))
+}
+
+// GetProductChanges serves the sync feed. The first request passes ?since= as an RFC3339
+// timestamp; later pages pass the next_cursor of the previous response as ?cursor=.
+func (productController *ProductController) GetProductChanges(c echo.Context) error {
+	var after model.ChangeCursor
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		var err error
+		if after, err = decodeChangeCursor(cursor); err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+				ErrorDescription: "cursor is invalid; pass the next_cursor of a previous response",
+			})
+		}
+	} else {
+		since, err := time.Parse(time.RFC3339, c.QueryParam("since"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+				ErrorDescription: "since must be an RFC3339 timestamp",
+			})
+		}
+		// The highest id makes the cursor exclude changes made exactly at since
+		after = model.ChangeCursor{ChangedAt: since.UTC(), ProductId: math.MaxInt64}
+	}
+
+	pagination, err := request.ParsePagination(c, productController.pageSize)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+
+	changes, next, err := productController.productService.GetProductChanges(after, pagination.Limit)
+	if errors.Is(err, service.ErrInvalidPageRequest) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+
+	var nextCursor *string
+	if next != nil {
+		encoded := encodeChangeCursor(*next)
+		nextCursor = &encoded
+	}
+	return c.JSON(http.StatusOK, response.ToProductChangesResponse(changes, nextCursor))
+}
+
+// encodeChangeCursor packs a feed position into an opaque, URL-safe token.
+func encodeChangeCursor(cursor model.ChangeCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", cursor.ChangedAt.UnixMicro(), cursor.ProductId)))
+}
+
+func decodeChangeCursor(token string) (model.ChangeCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return model.ChangeCursor{}, err
+	}
+	micros, productId, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return model.ChangeCursor{}, errors.New("malformed cursor")
+	}
+	changedAt, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return model.ChangeCursor{}, err
+	}
+	id, err := strconv.ParseInt(productId, 10, 64)
+	if err != nil {
+		return model.ChangeCursor{}, err
+	}
+	return model.ChangeCursor{ChangedAt: time.UnixMicro(changedAt).UTC(), ProductId: id}, nil
+}
+
+// getProductsByStatus serves ?status= for admins: a lifecycle status or "all". Other
+// listings only ever show active products.
+func (productController *ProductController) getProductsByStatus(c echo.Context) error {
+	if role, _ := c.Get("role").(string); role != domain.RoleAdmin {
+		return c.JSON(http.StatusForbidden, response.ErrorResponse{
+			ErrorDescription: "only admins can list products by status",
+		})
+	}
+
+	products, err := productController.productService.GetProductsByStatus(c.QueryParam("status"))
+	if errors.Is(err, model.ErrInvalidStatus) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error() + " (or all)",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToResponseList(products))
+}
+
+// getProductsCreatedBetween serves ?createdFrom=&createdTo=, both required RFC3339 timestamps.
+func (productController *ProductController) getProductsCreatedBetween(c echo.Context) error {
+	from, err := time.Parse(time.RFC3339, c.QueryParam("createdFrom"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "createdFrom must be an RFC3339 timestamp",
+		})
+	}
+	to, err := time.Parse(time.RFC3339, c.QueryParam("createdTo"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "createdTo must be an RFC3339 timestamp",
+		})
+	}
+
+	products, err := productController.productService.GetProductsCreatedBetween(from, to)
+	if errors.Is(err, service.ErrInvalidDateRange) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.ToResponseList(products))
+}
+
+func (productController *ProductController) AddProduct(c echo.Context) error {
+	var addProductRequest request.AddProductRequest
+	bindErr := bindJSON(c, &addProductRequest)
+	if bindErr != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: bindErr.Error(),
+		})
+	}
+
+	productCreate := addProductRequest.ToModel()
+	productCreate.UserId, _ = c.Get("user_id").(int64)
+
+	idempotencyKey := c.Request().Header.Get("Idempotency-Key")
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: fmt.Sprintf("Idempotency-Key can be at most %d characters", maxIdempotencyKeyLength),
+		})
+	}
+	if idempotencyKey != "" {
+		productId, replayed, err := productController.idempotencyService.CreateProduct(idempotencyKey, productCreate)
+		if errors.Is(err, service.ErrIdempotencyKeyReused) || errors.Is(err, service.ErrIdempotencyKeyInProgress) || errors.Is(err, service.ErrSlugTaken) {
+			return c.JSON(http.StatusConflict, response.ErrorResponse{
+				ErrorDescription: err.Error(),
+			})
+		}
+		if err != nil {
+			return c.JSON(http.StatusUnprocessableEntity, response.NewValidationErrorResponse(err))
+		}
+		if replayed {
+			c.Response().Header().Set("Idempotent-Replayed", "true")
+		}
+		return productController.respondWithProduct(c, http.StatusCreated, productId)
+	}
+
+	productId, err := productController.productService.Add(productCreate)
+	if errors.Is(err, service.ErrSlugTaken) {
+		return c.JSON(http.StatusConflict, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, response.NewValidationErrorResponse(err))
+	}
+	return productController.respondWithProduct(c, http.StatusCreated, productId)
+}
+
+// respondWithProduct answers a successful create or update with the product as it is
+// stored now, so clients get server-assigned fields such as the slug and version. It is
+// read from the primary, which a lagging replica could answer with 404 or stale data.
+func (productController *ProductController) respondWithProduct(c echo.Context, status int, productId int64) error {
+	product, err := productController.productService.GetCurrentById(productId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return c.JSON(status, response.NewMutationResponse(product.Id, response.ToResponse(product)))
+}
+
+func (productController *ProductController) UpdatePrice(c echo.Context) error {
+	param := c.Param("id")
+	productId, _ := strconv.Atoi(param)
+
+	newPrice := c.QueryParam("newPrice")
+	if len(newPrice) == 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Parameter newPrice is required!",
+		})
+	}
+	convertedPrice, err := decimal.Parse(newPrice)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "NewPrice Format Disrupted!",
+		})
+	}
+	version, err := strconv.Atoi(c.QueryParam("version"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Parameter version is required!",
+		})
+	}
+	userId, _ := c.Get("user_id").(int64)
+	err = productController.productService.UpdatePrice(int64(productId), convertedPrice, version, userId)
+	var validationError *model.ValidationError
+	if errors.As(err, &validationError) {
+		return c.JSON(http.StatusUnprocessableEntity, response.NewValidationErrorResponse(err))
+	}
+	if errors.Is(err, domain.ErrProductVersionConflict) {
+		return c.JSON(http.StatusConflict, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if errors.Is(err, domain.ErrProductNotFound) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return productController.respondWithProduct(c, http.StatusOK, int64(productId))
+}
+
+func (productController *ProductController) PatchProduct(c echo.Context) error {
+	param := c.Param("id")
+	productId, err := strconv.Atoi(param)
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	var patchProductRequest request.PatchProductRequest
+	if bindErr := bindJSON(c, &patchProductRequest); bindErr != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: bindErr.Error(),
+		})
+	}
+	if patchProductRequest.Version == nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "version is required",
+		})
+	}
+
+	userId, _ := c.Get("user_id").(int64)
+	err = productController.productService.UpdateProductPartial(int64(productId), patchProductRequest.ToModel(), userId)
+	switch {
+	case err == nil:
+		return productController.respondWithProduct(c, http.StatusOK, int64(productId))
+	case errors.Is(err, service.ErrEmptyProductPatch):
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	case errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	case errors.Is(err, domain.ErrProductVersionConflict), errors.Is(err, service.ErrSlugTaken):
+		return c.JSON(http.StatusConflict, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	default:
+		return c.JSON(http.StatusUnprocessableEntity, response.NewValidationErrorResponse(err))
+	}
+}
+
+func (productController *ProductController) UpdateProductStatus(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	var statusRequest request.ProductStatusRequest
+	if bindErr := bindJSON(c, &statusRequest); bindErr != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: bindErr.Error(),
+		})
+	}
+
+	err = productController.productService.UpdateProductStatus(int64(productId), statusRequest.Status)
+	switch {
+	case err == nil:
+		return productController.respondWithProduct(c, http.StatusOK, int64(productId))
+	case errors.Is(err, model.ErrInvalidStatus):
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	case errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+}
+
+func (productController *ProductController) SetFeatured(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	var featuredRequest request.FeaturedRequest
+	if bindErr := bindJSON(c, &featuredRequest); bindErr != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: bindErr.Error(),
+		})
+	}
+	if featuredRequest.Featured == nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "featured is required",
+		})
+	}
+
+	err = productController.productService.SetFeatured(int64(productId), *featuredRequest.Featured)
+	switch {
+	case err == nil:
+		return productController.respondWithProduct(c, http.StatusOK, int64(productId))
+	case errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+}
+
+func (productController *ProductController) UpdateProductCategory(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	var categoryRequest request.ProductCategoryRequest
+	if bindErr := bindJSON(c, &categoryRequest); bindErr != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: bindErr.Error(),
+		})
+	}
+	if categoryRequest.CategoryID <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "category_id must be a positive integer",
+		})
+	}
+
+	err = productController.productService.UpdateProductCategory(int64(productId), categoryRequest.CategoryID)
+	if errors.Is(err, domain.ErrProductNotFound) || errors.Is(err, domain.ErrCategoryNotFound) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return productController.respondWithProduct(c, http.StatusOK, int64(productId))
+}
+
+func (productController *ProductController) ClearProductCategory(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	err = productController.productService.ClearProductCategory(int64(productId))
+	if errors.Is(err, domain.ErrProductNotFound) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return productController.respondWithProduct(c, http.StatusOK, int64(productId))
+}
+
+func (productController *ProductController) GetProductImages(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	images, err := productController.productService.GetProductImages(int64(productId))
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, response.EmptyIfNil(images))
+	case errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+}
+
+func (productController *ProductController) AddProductImages(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	var imagesRequest request.AddImagesRequest
+	if bindErr := bindJSON(c, &imagesRequest); bindErr != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: bindErr.Error(),
+		})
+	}
+
+	err = productController.productService.AddProductImages(int64(productId), imagesRequest.Images)
+	switch {
+	case err == nil:
+		return productController.respondWithProduct(c, http.StatusOK, int64(productId))
+	case errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	case errors.Is(err, model.ErrInvalidImage):
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+}
+
+// NormalizeImageOrder renumbers the product's image display orders to 0..n-1 and returns
+// the images in their new order.
+func (productController *ProductController) NormalizeImageOrder(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	images, err := productController.productService.NormalizeImageOrder(int64(productId))
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, response.EmptyIfNil(images))
+	case errors.Is(err, domain.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+}
+
+// NormalizeAllImageOrders repairs the image order of every product at once and reports
+// how many products were renumbered.
+func (productController *ProductController) NormalizeAllImageOrders(c echo.Context) error {
+	normalized, err := productController.productService.NormalizeAllImageOrders()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]int64{
+		"normalized_products": normalized,
+	})
+}
+
+func (productController *ProductController) AttachTags(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	var tagsRequest request.TagsRequest
+	if bindErr := bindJSON(c, &tagsRequest); bindErr != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: bindErr.Error(),
+		})
+	}
+
+	err = productController.productService.AttachTags(int64(productId), tagsRequest.Tags)
+	if errors.Is(err, service.ErrEmptyTag) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if errors.Is(err, domain.ErrProductNotFound) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return productController.respondWithProduct(c, http.StatusOK, int64(productId))
+}
+
+func (productController *ProductController) DetachTag(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	err = productController.productService.DetachTag(int64(productId), c.Param("tag"))
+	if errors.Is(err, service.ErrEmptyTag) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return productController.respondWithProduct(c, http.StatusOK, int64(productId))
+}
+
+func (productController *ProductController) DeleteProductById(c echo.Context) error {
+	param := c.Param("id")
+	productId, err := strconv.Atoi(param)
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+	err = productController.productService.DeleteById(int64(productId))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteProductsByIds deletes a batch of products. With ?dryRun=true nothing is deleted
+// and the same report is returned, so admins can check a batch before running it.
+func (productController *ProductController) DeleteProductsByIds(c echo.Context) error {
+	dryRun := false
+	if dryRunParam := c.QueryParam("dryRun"); dryRunParam != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(dryRunParam); err != nil {
+			return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+				ErrorDescription: "dryRun must be true or false",
+			})
+		}
+	}
+
+	productIds, err := bindJSONArray[int64](c, productController.maxBatchSize)
+	if errors.Is(err, errBatchTooLarge) {
+		return c.JSON(http.StatusRequestEntityTooLarge, response.ErrorResponse{
+			ErrorDescription: batchTooLargeMessage(productController.maxBatchSize),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Request body must be a JSON array of product ids",
+		})
+	}
+
+	deleted, notFoundIds, err := productController.productService.DeleteByIds(productIds, dryRun)
+	if errors.Is(err, service.ErrEmptyIdList) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.BatchDeleteResponse{
+		Deleted:     deleted,
+		NotFoundIds: response.EmptyIfNil(notFoundIds),
+	})
+}
+
+func batchTooLargeMessage(maxBatchSize int) string {
+	return fmt.Sprintf("a batch can hold at most %d ids; split the request into smaller batches", maxBatchSize)
+}
+
+func (productController *ProductController) DeleteAllProducts(c echo.Context) error {
+	err := productController.productService.DeleteAllProducts()
+	if err != nil {
+		log.Printf("DeleteAllProducts error: %v", err)
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// toPage wraps one window of a listing, deriving the 1-based page number from the offset.
+func toPage[T any](items []T, total int64, pageRequest model.PageRequest) response.Page[T] {
+	page := 1
+	if pageRequest.Limit > 0 {
+		page = pageRequest.Offset/pageRequest.Limit + 1
+	}
+	return response.NewPage(items, total, page, pageRequest.Limit)
+}
+
+// setPaginationHeaders sets X-Total-Count and an RFC 8288 Link header with first, prev,
+// next and last pages. The links keep every query parameter of the request and only
+// replace offset and limit, so filters and sort carry over.
+func setPaginationHeaders(c echo.Context, total int64, pageRequest model.PageRequest) {
+	header := c.Response().Header()
+	header.Set("X-Total-Count", strconv.FormatInt(total, 10))
+	if pageRequest.Limit <= 0 {
+		return
+	}
+
+	limit := int64(pageRequest.Limit)
+	offset := int64(pageRequest.Offset)
+	lastOffset := int64(0)
+	if total > 0 {
+		lastOffset = (total - 1) / limit * limit
+	}
+
+	pageLink := func(offset int64, rel string) string {
+		url := *c.Request().URL
+		query := url.Query()
+		query.Set("offset", strconv.FormatInt(offset, 10))
+		query.Set("limit", strconv.FormatInt(limit, 10))
+		url.RawQuery = query.Encode()
+		return fmt.Sprintf(`<%s>; rel="%s"`, url.RequestURI(), rel)
+	}
+
+	links := []string{pageLink(0, "first")}
+	if offset > 0 {
+		links = append(links, pageLink(max(offset-limit, 0), "prev"))
+	}
+	if offset+limit < total {
+		links = append(links, pageLink(offset+limit, "next"))
+	}
+	links = append(links, pageLink(lastOffset, "last"))
+	header.Set("Link", strings.Join(links, ", "))
+}
+
+// parsePriceRange reads the optional minPrice, maxPrice and currency query parameters.
+func parsePriceRange(c echo.Context) (model.PriceRange, error) {
+	minPrice, err := parseOptionalPrice(c, "minPrice")
+	if err != nil {
+		return model.PriceRange{}, err
+	}
+	maxPrice, err := parseOptionalPrice(c, "maxPrice")
+	if err != nil {
+		return model.PriceRange{}, err
+	}
+	return model.PriceRange{
+		Min:      minPrice,
+		Max:      maxPrice,
+		Currency: parseCurrency(c),
+	}, nil
+}
+
+// parseCurrency reads the optional currency query parameter as an upper-case code.
+func parseCurrency(c echo.Context) string {
+	return strings.ToUpper(strings.TrimSpace(c.QueryParam("currency")))
+}
+
+func parseOptionalPrice(c echo.Context, name string) (*decimal.Decimal, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+	price, err := decimal.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number with at most %d decimal places", name, decimal.Scale)
+	}
+	return &price, nil
+}
+
+// isInvalidListingRequest reports service errors caused by the listing's query parameters.
+func isInvalidListingRequest(err error) bool {
+	return errors.Is(err, service.ErrInvalidPageRequest) ||
+		errors.Is(err, service.ErrInvalidPriceRange) ||
+		errors.Is(err, model.ErrUnsupportedCurrency)
 }
@@ -0,0 +1,64 @@
+package request
+
+import (
+	"errors"
+	"product-app/service/model"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PageSize bounds the limit of a paginated listing: Default is used when a request has
+// no limit and larger limits are clamped to Max. Sort is the order of a request without
+// a sort parameter; empty leaves the listing's own default.
+type PageSize struct {
+	Default int
+	Max     int
+	Sort    string
+}
+
+// Pagination is the window a listing request asks for through its limit, offset and
+// sort query parameters, after defaults and the maximum page size are applied.
+type Pagination struct {
+	Limit  int
+	Offset int
+	Sort   string
+}
+
+func (pagination Pagination) PageRequest() model.PageRequest {
+	return model.PageRequest{Limit: pagination.Limit, Offset: pagination.Offset, Sort: pagination.Sort}
+}
+
+// ParsePagination reads the optional limit, offset and sort query parameters. A missing
+// limit falls back to pageSize.Default, and one above pageSize.Max is clamped rather
+// than rejected; a limit below one and a negative offset are errors. A missing sort
+// falls back to pageSize.Sort, otherwise it is returned as given and each listing checks
+// it against the keys it supports.
+func ParsePagination(c echo.Context, pageSize PageSize) (Pagination, error) {
+	pagination := Pagination{Limit: min(pageSize.Default, pageSize.Max)}
+	var err error
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		if pagination.Limit, err = strconv.Atoi(limit); err != nil {
+			return Pagination{}, errors.New("limit must be an integer")
+		}
+		if pagination.Limit <= 0 {
+			return Pagination{}, errors.New("limit must be greater than zero")
+		}
+		pagination.Limit = min(pagination.Limit, pageSize.Max)
+	}
+	if offset := c.QueryParam("offset"); offset != "" {
+		if pagination.Offset, err = strconv.Atoi(offset); err != nil {
+			return Pagination{}, errors.New("offset must be an integer")
+		}
+		if pagination.Offset < 0 {
+			return Pagination{}, errors.New("offset must not be negative")
+		}
+	}
+	pagination.Sort = c.QueryParam("sort")
+	if pagination.Sort == "" {
+		pagination.Sort = pageSize.Sort
+	}
+
+	return pagination, nil
+}
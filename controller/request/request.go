@@ -1,25 +1,98 @@
 package request
 
-import "product-app/service/model"
+import (
+	"product-app/common/decimal"
+	"product-app/service/model"
+)
 
 type AddProductRequest struct {
-	Name        string   `json:"name"`
-	Price       float32  `json:"price"`
-	Description string   `json:"description"`
-	Discount    float32  `json:"discount"`
-	Store       string   `json:"store"`
-	ImageUrls   []string `json:"image_urls"`
-	CategoryID  int64    `json:"category_id"`
+	Name        string          `json:"name"`
+	Slug        string          `json:"slug"`
+	Price       decimal.Decimal `json:"price"`
+	Currency    string          `json:"currency"`
+	Description string          `json:"description"`
+	Discount    decimal.Decimal `json:"discount"`
+	Store       string          `json:"store"`
+	ImageUrls   []string        `json:"image_urls"`
+	CategoryID  int64           `json:"category_id"`
+	Tags        []string        `json:"tags"`
+	Status      string          `json:"status"`
+	Stock       *int            `json:"stock"`
 }
 
 func (addProductRequest AddProductRequest) ToModel() model.ProductCreate {
 	return model.ProductCreate{
 		Name:        addProductRequest.Name,
+		Slug:        addProductRequest.Slug,
 		Price:       addProductRequest.Price,
+		Currency:    addProductRequest.Currency,
 		Description: addProductRequest.Description,
 		Discount:    addProductRequest.Discount,
 		Store:       addProductRequest.Store,
 		ImageUrls:   addProductRequest.ImageUrls,
 		CategoryID:  addProductRequest.CategoryID,
+		Tags:        addProductRequest.Tags,
+		Status:      addProductRequest.Status,
+		Stock:       addProductRequest.Stock,
 	}
 }
+
+type PatchProductRequest struct {
+	Version     *int             `json:"version"`
+	Name        *string          `json:"name"`
+	Slug        *string          `json:"slug"`
+	Price       *decimal.Decimal `json:"price"`
+	Description *string          `json:"description"`
+	Discount    *decimal.Decimal `json:"discount"`
+	Store       *string          `json:"store"`
+	CategoryID  *int64           `json:"category_id"`
+	Stock       *int             `json:"stock"`
+}
+
+func (patchProductRequest PatchProductRequest) ToModel() model.ProductPatch {
+	var version int
+	if patchProductRequest.Version != nil {
+		version = *patchProductRequest.Version
+	}
+	return model.ProductPatch{
+		Version:     version,
+		Name:        patchProductRequest.Name,
+		Slug:        patchProductRequest.Slug,
+		Price:       patchProductRequest.Price,
+		Description: patchProductRequest.Description,
+		Discount:    patchProductRequest.Discount,
+		Store:       patchProductRequest.Store,
+		CategoryID:  patchProductRequest.CategoryID,
+		Stock:       patchProductRequest.Stock,
+	}
+}
+
+type AddReviewRequest struct {
+	Rating  int    `json:"rating"`
+	Comment string `json:"comment"`
+}
+
+type TagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+type AddImagesRequest struct {
+	Images []model.ImageCreate `json:"images"`
+}
+
+type ProductStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// FeaturedRequest requires featured, so an empty body is not read as unflagging.
+type FeaturedRequest struct {
+	Featured *bool `json:"featured"`
+}
+
+type ProductCategoryRequest struct {
+	CategoryID int64 `json:"category_id"`
+}
+
+type UploadUrlRequest struct {
+	MimeType string `json:"mime_type"`
+}
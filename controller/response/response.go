@@ -1,32 +1,89 @@
 package response
 
-import "product-app/domain"
+import (
+	"errors"
+	"product-app/common/decimal"
+	"product-app/domain"
+	"product-app/service/model"
+	"time"
+)
 
+// ErrorResponse is the error body of product endpoints. Details is only set when
+// validation failed and lists the failing fields with stable codes.
 type ErrorResponse struct {
-	ErrorDescription string `json:"errorDescription"`
+	ErrorDescription string             `json:"errorDescription"`
+	Details          []model.FieldError `json:"details,omitempty"`
+}
+
+// NewValidationErrorResponse describes err, adding the failing fields when err is a
+// validation error.
+func NewValidationErrorResponse(err error) ErrorResponse {
+	errorResponse := ErrorResponse{ErrorDescription: err.Error()}
+	var validationError *model.ValidationError
+	if errors.As(err, &validationError) {
+		errorResponse.Details = validationError.Fields
+	}
+	return errorResponse
 }
 
 type ProductResponse struct {
-	Name        string   `json:"name"`
-	Price       float32  `json:"price"`
-	Description string   `json:"description"`
-	Discount    float32  `json:"discount"`
-	Store       string   `json:"store"`
-	ImageUrls   []string `json:"image_urls"`
-	CategoryID  int64    `json:"category_id"`
+	Name        string                `json:"name"`
+	Slug        string                `json:"slug"`
+	Price       decimal.Decimal       `json:"price"`
+	Currency    string                `json:"currency"`
+	Description string                `json:"description"`
+	Discount    decimal.Decimal       `json:"discount"`
+	Store       string                `json:"store"`
+	ImageUrls   []string              `json:"image_urls"`
+	MainImage   *string               `json:"main_image"`
+	Images      []domain.ProductImage `json:"images"`
+	CategoryID  int64                 `json:"category_id"`
+	Tags        []string              `json:"tags"`
+	Version     int                   `json:"version"`
+	Status      string                `json:"status"`
+	IsFeatured  bool                  `json:"is_featured"`
+	Stock       *int                  `json:"stock"`
+	// Set only for ?expand=rating; nil leaves average_rating and review_count out
+	*domain.ProductRating
 }
 
 func ToResponse(product domain.Product) ProductResponse {
+	var mainImage *string
+	if url, ok := product.MainImage(); ok {
+		mainImage = &url
+	}
 	return ProductResponse{
 		Name:        product.Name,
+		Slug:        product.Slug,
 		Price:       product.Price,
+		Currency:    product.Currency,
 		Description: product.Description,
 		Discount:    product.Discount,
 		Store:       product.Store,
-		ImageUrls:   product.ImageUrls,
+		ImageUrls:   EmptyIfNil(product.ImageUrls),
+		MainImage:   mainImage,
+		Images:      EmptyIfNil(product.Images),
 		CategoryID:  product.CategoryID,
+		Tags:        EmptyIfNil(product.Tags),
+		Version:     product.Version,
+		Status:      product.Status,
+		IsFeatured:  product.IsFeatured,
+		Stock:       product.Stock,
 	}
 }
+
+type ProductWithCategoryResponse struct {
+	ProductResponse
+	Category *domain.Category `json:"category"`
+}
+
+func ToResponseWithCategory(productWithCategory domain.ProductWithCategory) ProductWithCategoryResponse {
+	return ProductWithCategoryResponse{
+		ProductResponse: ToResponse(productWithCategory.Product),
+		Category:        productWithCategory.Category,
+	}
+}
+
 func ToResponseList(products []domain.Product) []ProductResponse {
 	var productResponseList = []ProductResponse{}
 	for _, product := range products {
@@ -34,3 +91,180 @@ func ToResponseList(products []domain.Product) []ProductResponse {
 	}
 	return productResponseList
 }
+
+type PriceStatsResponse struct {
+	CategoryId   int64           `json:"category_id"`
+	Currency     string          `json:"currency"`
+	HasProducts  bool            `json:"has_products"`
+	ProductCount int64           `json:"product_count"`
+	MinPrice     decimal.Decimal `json:"min_price"`
+	MaxPrice     decimal.Decimal `json:"max_price"`
+	AvgPrice     decimal.Decimal `json:"avg_price"`
+}
+
+func ToPriceStatsResponse(priceStats domain.PriceStats) PriceStatsResponse {
+	return PriceStatsResponse{
+		CategoryId:   priceStats.CategoryId,
+		Currency:     priceStats.Currency,
+		HasProducts:  priceStats.HasProducts(),
+		ProductCount: priceStats.ProductCount,
+		MinPrice:     priceStats.MinPrice,
+		MaxPrice:     priceStats.MaxPrice,
+		AvgPrice:     priceStats.AvgPrice,
+	}
+}
+
+// ImageUploadResponse tells the client where and how to upload an image: send Method to
+// UploadUrl with Headers set, then confirm Key.
+type ImageUploadResponse struct {
+	Key       string            `json:"key"`
+	UploadUrl string            `json:"upload_url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	PublicUrl string            `json:"public_url"`
+}
+
+func ToImageUploadResponse(imageUpload model.ImageUpload) ImageUploadResponse {
+	return ImageUploadResponse{
+		Key:       imageUpload.Key,
+		UploadUrl: imageUpload.Upload.Url,
+		Method:    imageUpload.Upload.Method,
+		Headers:   imageUpload.Upload.Headers,
+		ExpiresAt: imageUpload.Upload.ExpiresAt,
+		PublicUrl: imageUpload.PublicUrl,
+	}
+}
+
+// UserResponse is the public view of a user; it has no password field at all.
+type UserResponse struct {
+	Id          int64      `json:"id"`
+	Username    string     `json:"username"`
+	Email       string     `json:"email"`
+	FirstName   string     `json:"first_name"`
+	LastName    string     `json:"last_name"`
+	Role        string     `json:"role"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+func ToUserResponse(user domain.User) UserResponse {
+	return UserResponse{
+		Id:          user.Id,
+		Username:    user.Username,
+		Email:       user.Email,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Role:        user.Role,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+		LastLoginAt: user.LastLoginAt,
+	}
+}
+
+type SearchResponse struct {
+	Products   []ProductResponse `json:"products"`
+	Categories []domain.Category `json:"categories"`
+}
+
+type ReviewListResponse struct {
+	AverageRating float64         `json:"average_rating"`
+	ReviewCount   int             `json:"review_count"`
+	Reviews       []domain.Review `json:"reviews"`
+}
+
+type BatchDeleteResponse struct {
+	Deleted     int64   `json:"deleted"`
+	NotFoundIds []int64 `json:"not_found_ids"`
+}
+
+type CountResponse struct {
+	Count int64 `json:"count"`
+}
+
+// ProductChangeResponse is one entry of the product change feed. Product is null when
+// Deleted is set.
+type ProductChangeResponse struct {
+	Id        int64            `json:"id"`
+	Deleted   bool             `json:"deleted"`
+	ChangedAt time.Time        `json:"changed_at"`
+	Product   *ProductResponse `json:"product"`
+}
+
+// ProductChangesResponse is a page of the change feed. NextCursor is null on the last
+// page; otherwise it is passed back as ?cursor= for the next one.
+type ProductChangesResponse struct {
+	Changes    []ProductChangeResponse `json:"changes"`
+	NextCursor *string                 `json:"next_cursor"`
+}
+
+func ToProductChangesResponse(changes []domain.ProductChange, nextCursor *string) ProductChangesResponse {
+	responses := make([]ProductChangeResponse, len(changes))
+	for i, change := range changes {
+		responses[i] = ProductChangeResponse{Id: change.ProductId, Deleted: change.Deleted, ChangedAt: change.ChangedAt}
+		if change.Product != nil {
+			product := ToResponse(*change.Product)
+			responses[i].Product = &product
+		}
+	}
+	return ProductChangesResponse{Changes: responses, NextCursor: nextCursor}
+}
+
+// MutationResponse is the envelope returned by create and update endpoints: the id
+// of the affected resource and its state after the change.
+type MutationResponse[T any] struct {
+	Id   int64 `json:"id"`
+	Data T     `json:"data"`
+}
+
+func NewMutationResponse[T any](id int64, data T) MutationResponse[T] {
+	return MutationResponse[T]{Id: id, Data: data}
+}
+
+// Page is the envelope returned by paginated listings.
+type Page[T any] struct {
+	Items      []T   `json:"items"`
+	Total      int64 `json:"total"`
+	Page       int   `json:"page"`
+	Size       int   `json:"size"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// CursorPage is the envelope of keyset-paginated listings. NextCursor is null on the last
+// page; otherwise it is passed back as ?cursor= for the next one.
+type CursorPage[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// EmptyIfNil returns items, or an empty slice when items is nil, so a list without
+// entries is written as [] instead of null. Every slice a response serializes goes
+// through it; repositories already start their result slices empty.
+func EmptyIfNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// NewPage wraps items of the given 1-based page. A size of zero means the listing
+// is not limited, so everything fits on a single page.
+func NewPage[T any](items []T, total int64, page int, size int) Page[T] {
+	items = EmptyIfNil(items)
+
+	totalPages := 0
+	if size > 0 {
+		totalPages = int((total + int64(size) - 1) / int64(size))
+	} else if total > 0 {
+		totalPages = 1
+	}
+
+	return Page[T]{
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		Size:       size,
+		TotalPages: totalPages,
+	}
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"product-app/controller/request"
+	"product-app/controller/response"
+	"product-app/domain"
+	"product-app/middleware"
+	"product-app/service"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReviewController handles HTTP requests for product reviews and ratings
+type ReviewController struct {
+	reviewService service.IReviewService
+}
+
+func NewReviewController(reviewService service.IReviewService) *ReviewController {
+	return &ReviewController{reviewService: reviewService}
+}
+
+// RegisterRoutes registers review routes:
+//   - GET /api/v1/products/:id/reviews - List reviews with average rating (public)
+//   - POST /api/v1/products/:id/reviews - Create or update the caller's review (JWT required)
+func (reviewController *ReviewController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/v1/products/:id/reviews", reviewController.GetReviewsByProduct)
+	e.POST("/api/v1/products/:id/reviews", reviewController.AddReview, middleware.JWTMiddleware())
+}
+
+func (reviewController *ReviewController) AddReview(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	var addReviewRequest request.AddReviewRequest
+	if bindErr := c.Bind(&addReviewRequest); bindErr != nil {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: bindErr.Error(),
+		})
+	}
+
+	userId, _ := c.Get("user_id").(int64)
+	review, err := reviewController.reviewService.AddReview(int64(productId), userId, addReviewRequest.Rating, addReviewRequest.Comment)
+	if errors.Is(err, domain.ErrProductNotFound) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusCreated, response.NewMutationResponse(review.Id, review))
+}
+
+func (reviewController *ReviewController) GetReviewsByProduct(c echo.Context) error {
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || productId <= 0 {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: "Invalid product ID",
+		})
+	}
+
+	reviews, err := reviewController.reviewService.GetReviewsByProduct(int64(productId))
+	if errors.Is(err, domain.ErrProductNotFound) {
+		return c.JSON(http.StatusNotFound, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+
+	averageRating, err := reviewController.reviewService.GetAverageRating(int64(productId))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, response.ReviewListResponse{
+		AverageRating: averageRating,
+		ReviewCount:   len(reviews),
+		Reviews:       response.EmptyIfNil(reviews),
+	})
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"product-app/controller/response"
+	"product-app/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SearchController serves the combined product and category search
+type SearchController struct {
+	productService  service.IProductService
+	categoryService service.ICategoryService
+	resultLimit     int
+}
+
+// NewSearchController creates a new instance of SearchController
+// resultLimit caps the number of results returned in each section
+func NewSearchController(productService service.IProductService, categoryService service.ICategoryService, resultLimit int) *SearchController {
+	return &SearchController{
+		productService:  productService,
+		categoryService: categoryService,
+		resultLimit:     resultLimit,
+	}
+}
+
+func (searchController *SearchController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/api/v1/search", searchController.Search)
+}
+
+func (searchController *SearchController) Search(c echo.Context) error {
+	query := c.QueryParam("q")
+
+	products, err := searchController.productService.SearchProducts(query, searchController.resultLimit)
+	if errors.Is(err, service.ErrEmptySearchQuery) {
+		return c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+
+	categories, err := searchController.categoryService.SearchCategories(query, searchController.resultLimit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			ErrorDescription: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response.SearchResponse{
+		Products:   response.ToResponseList(products),
+		Categories: response.EmptyIfNil(categories),
+	})
+}
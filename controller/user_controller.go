@@ -1,10 +1,16 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"product-app/controller/response"
+	"product-app/domain"
 	"product-app/middleware"
 	"product-app/service"
+	"product-app/service/model"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -21,11 +27,57 @@ type RegisterRequest struct {
 	LastName  string `json:"last_name"`
 }
 
+// availabilityRequestsPerMinute throttles the availability check per client IP so it
+// can't be used to enumerate accounts quickly.
+const availabilityRequestsPerMinute = 30
+
 type LoginRequest struct {
 	UsernameOrEmail string `json:"username_or_email"`
 	Password        string `json:"password"`
 }
 
+// validate trims the username and email in place and returns a message for each
+// missing or malformed field, keyed by its JSON name. Length and character rules are
+// left to the service.
+func (req *RegisterRequest) validate() map[string]string {
+	req.Username = strings.TrimSpace(req.Username)
+	req.Email = strings.TrimSpace(req.Email)
+
+	fieldErrors := map[string]string{}
+	if req.Username == "" {
+		fieldErrors["username"] = "username is required"
+	}
+	if err := model.ValidateEmail(req.Email); err != nil {
+		fieldErrors["email"] = err.Error()
+	}
+	if req.Password == "" {
+		fieldErrors["password"] = "password is required"
+	}
+	return fieldErrors
+}
+
+func (req *LoginRequest) validate() map[string]string {
+	req.UsernameOrEmail = strings.TrimSpace(req.UsernameOrEmail)
+
+	fieldErrors := map[string]string{}
+	if req.UsernameOrEmail == "" {
+		fieldErrors["username_or_email"] = "username or email is required"
+	}
+	if req.Password == "" {
+		fieldErrors["password"] = "password is required"
+	}
+	return fieldErrors
+}
+
+// invalidFields answers 400 listing the failing fields, e.g.
+// {"error": "Invalid request body", "fields": {"password": "password is required"}}.
+func invalidFields(c echo.Context, fieldErrors map[string]string) error {
+	return c.JSON(http.StatusBadRequest, map[string]interface{}{
+		"error":  "Invalid request body",
+		"fields": fieldErrors,
+	})
+}
+
 func NewUserController(userService service.IUserService) *UserController {
 	return &UserController{userService: userService}
 }
@@ -34,8 +86,11 @@ func (userController *UserController) RegisterRoutes(e *echo.Echo) {
 	// Public routes (no authentication required)
 	e.POST("/api/v1/auth/register", userController.Register)
 	e.POST("/api/v1/auth/login", userController.Login)
+	e.GET("/api/v1/auth/available", userController.CheckAvailability,
+		middleware.RateLimit(availabilityRequestsPerMinute, time.Minute))
 
 	// Protected routes (authentication required)
+	e.GET("/api/v1/me", userController.GetCurrentUser, middleware.JWTMiddleware())
 	protected := e.Group("/api/v1/users", middleware.JWTMiddleware())
 	protected.GET("/:id", userController.GetUserById)
 	protected.PUT("/:id", userController.UpdateUser)
@@ -49,16 +104,18 @@ func (userController *UserController) Register(c echo.Context) error {
 			"error": "Invalid request body",
 		})
 	}
+	if fieldErrors := req.validate(); len(fieldErrors) > 0 {
+		return invalidFields(c, fieldErrors)
+	}
 
-	if err := userController.userService.Register(req.Username, req.Email, req.Password, req.FirstName, req.LastName); err != nil {
+	user, err := userController.userService.Register(req.Username, req.Email, req.Password, req.FirstName, req.LastName)
+	if err != nil {
 		return c.JSON(http.StatusUnprocessableEntity, map[string]string{
 			"error": err.Error(),
 		})
 	}
 
-	return c.JSON(http.StatusCreated, map[string]string{
-		"message": "User registered successfully",
-	})
+	return c.JSON(http.StatusCreated, response.NewMutationResponse(user.Id, response.ToUserResponse(user)))
 }
 
 func (userController *UserController) Login(c echo.Context) error {
@@ -68,6 +125,9 @@ func (userController *UserController) Login(c echo.Context) error {
 			"error": "Invalid request body",
 		})
 	}
+	if fieldErrors := req.validate(); len(fieldErrors) > 0 {
+		return invalidFields(c, fieldErrors)
+	}
 
 	user, err := userController.userService.Login(req.UsernameOrEmail, req.Password)
 	if err != nil {
@@ -77,26 +137,31 @@ func (userController *UserController) Login(c echo.Context) error {
 	}
 
 	// Generate JWT token
-	token, err := middleware.GenerateToken(user.Id, user.Username, user.Email)
+	token, err := middleware.GenerateToken(user.Id, user.Username, user.Email, user.Role)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to generate token",
 		})
 	}
 
-	// Return user info without password and include token
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"message": "Login successful",
 		"token":   token,
-		"user": map[string]interface{}{
-			"id":         user.Id,
-			"username":   user.Username,
-			"email":      user.Email,
-			"first_name": user.FirstName,
-			"last_name":  user.LastName,
-			"created_at": user.CreatedAt,
-			"updated_at": user.UpdatedAt,
-		},
+		"user":    response.ToUserResponse(user),
+	})
+}
+
+func (userController *UserController) CheckAvailability(c echo.Context) error {
+	usernameAvailable, emailAvailable, err := userController.userService.CheckAvailability(c.QueryParam("username"), c.QueryParam("email"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to check availability",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]*bool{
+		"username_available": usernameAvailable,
+		"email_available":    emailAvailable,
 	})
 }
 
@@ -117,16 +182,27 @@ func (userController *UserController) GetUserById(c echo.Context) error {
 		})
 	}
 
-	// Return user info without password
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"id":         user.Id,
-		"username":   user.Username,
-		"email":      user.Email,
-		"first_name": user.FirstName,
-		"last_name":  user.LastName,
-		"created_at": user.CreatedAt,
-		"updated_at": user.UpdatedAt,
-	})
+	return c.JSON(http.StatusOK, response.ToUserResponse(user))
+}
+
+// GetCurrentUser returns the profile of the user the token was issued to, so clients
+// don't need to know their own id.
+func (userController *UserController) GetCurrentUser(c echo.Context) error {
+	userId, _ := c.Get("user_id").(int64)
+
+	user, err := userController.userService.GetById(userId)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, response.ToUserResponse(user))
 }
 
 func (userController *UserController) UpdateUser(c echo.Context) error {
@@ -172,9 +248,14 @@ func (userController *UserController) UpdateUser(c echo.Context) error {
 		})
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{
-		"message": "User updated successfully",
-	})
+	updated, err := userController.userService.GetById(user.Id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, response.NewMutationResponse(updated.Id, response.ToUserResponse(updated)))
 }
 
 func (userController *UserController) DeleteUser(c echo.Context) error {
@@ -193,7 +274,5 @@ func (userController *UserController) DeleteUser(c echo.Context) error {
 		})
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{
-		"message": "User deleted successfully",
-	})
+	return c.NoContent(http.StatusNoContent)
 }
@@ -1,7 +1,27 @@
 package domain
 
+import "time"
+
+// The Uncategorized category holds products that have no other category. It is
+// created at startup and cannot be deleted.
+const (
+	UncategorizedName = "Uncategorized"
+	UncategorizedSlug = "uncategorized"
+)
+
 type Category struct {
-	Id          int64  `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-}
\ No newline at end of file
+	Id          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description"`
+	ParentId    *int64    `json:"parent_id"`
+	CreatedBy   *int64    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CategoryNode is a category together with its subcategories, as returned by the tree endpoint.
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+type IdempotencyKey struct {
+	Key         string    `json:"key"`
+	RequestHash string    `json:"request_hash"`
+	ProductId   int64     `json:"product_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
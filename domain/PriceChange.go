@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"product-app/common/decimal"
+	"time"
+)
+
+// PriceChange records one price update of a product. ChangedBy is nil when the
+// user who made the change has since been deleted.
+type PriceChange struct {
+	Id        int64           `json:"id"`
+	ProductId int64           `json:"product_id"`
+	OldPrice  decimal.Decimal `json:"old_price"`
+	NewPrice  decimal.Decimal `json:"new_price"`
+	ChangedAt time.Time       `json:"changed_at"`
+	ChangedBy *int64          `json:"changed_by"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "product-app/common/decimal"
+
+// PriceStats summarizes the prices of a category's products in Currency; products
+// priced in other currencies are not counted. When ProductCount is zero the prices are
+// left at zero.
+type PriceStats struct {
+	CategoryId   int64           `json:"category_id"`
+	Currency     string          `json:"currency"`
+	ProductCount int64           `json:"product_count"`
+	MinPrice     decimal.Decimal `json:"min_price"`
+	MaxPrice     decimal.Decimal `json:"max_price"`
+	AvgPrice     decimal.Decimal `json:"avg_price"`
+}
+
+func (priceStats PriceStats) HasProducts() bool {
+	return priceStats.ProductCount > 0
+}
@@ -1,12 +1,86 @@
 package domain
 
+import "product-app/common/decimal"
+
+// Product lifecycle statuses. Only active products appear in public listings; the
+// others stay reachable by id and slug.
+const (
+	ProductStatusDraft        = "draft"
+	ProductStatusActive       = "active"
+	ProductStatusDiscontinued = "discontinued"
+)
+
+func IsValidProductStatus(status string) bool {
+	return status == ProductStatusDraft || status == ProductStatusActive || status == ProductStatusDiscontinued
+}
+
 type Product struct {
-	Id          int64    `json:"id"`
-	Name        string   `json:"name"`
-	Price       float32  `json:"price"`
-	Description string   `json:"description"`
-	Discount    float32  `json:"discount"`
-	Store       string   `json:"store"`
-	ImageUrls   []string `json:"image_urls"`
-	CategoryID  int64    `json:"category_id"`
+	Id          int64           `json:"id"`
+	Name        string          `json:"name"`
+	Slug        string          `json:"slug"`
+	Price       decimal.Decimal `json:"price"`
+	Currency    string          `json:"currency"`
+	Description string          `json:"description"`
+	Discount    decimal.Decimal `json:"discount"`
+	Store       string          `json:"store"`
+	ImageUrls   []string        `json:"image_urls"`
+	Images      []ProductImage  `json:"images"`
+	CategoryID  int64           `json:"category_id"`
+	Tags        []string        `json:"tags"`
+	Version     int             `json:"version"`
+	Status      string          `json:"status"`
+	IsFeatured  bool            `json:"is_featured"`
+	// Stock is the number of units on hand; nil means stock is not tracked.
+	Stock *int `json:"stock"`
+	// UserId is the user who created the product, 0 for none. It is written on insert
+	// and only used to filter listings, so it is never serialized.
+	UserId int64 `json:"-"`
+}
+
+// Reasons reported by Product.Availability.
+const (
+	AvailabilityInStock    = "in_stock"
+	AvailabilityOutOfStock = "out_of_stock"
+	AvailabilityNotActive  = "not_active"
+)
+
+// Availability answers whether a product can be bought; Reason says why in a
+// machine-readable form.
+type Availability struct {
+	Available bool   `json:"available"`
+	Reason    string `json:"reason"`
+}
+
+// Availability is the single "can I buy this" rule: the product must be active and, when
+// its stock is tracked, have at least one unit left.
+func (product Product) Availability() Availability {
+	switch {
+	case product.Status != ProductStatusActive:
+		return Availability{Available: false, Reason: AvailabilityNotActive}
+	case product.Stock != nil && *product.Stock <= 0:
+		return Availability{Available: false, Reason: AvailabilityOutOfStock}
+	default:
+		return Availability{Available: true, Reason: AvailabilityInStock}
+	}
+}
+
+// MainImage returns the URL of the image flagged as main, falling back to the first image
+// in display order. ok is false when the product has no images.
+func (product Product) MainImage() (url string, ok bool) {
+	for _, image := range product.Images {
+		if image.IsMain {
+			return image.Url, true
+		}
+	}
+	if len(product.ImageUrls) > 0 {
+		return product.ImageUrls[0], true
+	}
+	return "", false
+}
+
+// ProductWithCategory is a product together with its category; Category is nil
+// for uncategorized products.
+type ProductWithCategory struct {
+	Product
+	Category *Category `json:"category"`
 }
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// ProductChange is one entry of the product sync feed. Deleted is set when the product
+// no longer exists or is no longer publicly listed; Product is only set otherwise.
+type ProductChange struct {
+	ProductId int64
+	ChangedAt time.Time
+	Deleted   bool
+	Product   *Product
+}
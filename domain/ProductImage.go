@@ -0,0 +1,14 @@
+package domain
+
+// ProductImage is one image of a product; Product.Images lists them in the same order
+// as Product.ImageUrls. Width, Height and MimeType are optional metadata supplied by
+// the client; nil means unknown. Id and DisplayOrder are assigned when it is stored.
+type ProductImage struct {
+	Id           int64   `json:"id"`
+	Url          string  `json:"url"`
+	IsMain       bool    `json:"is_main_image"`
+	DisplayOrder int     `json:"display_order"`
+	Width        *int    `json:"width"`
+	Height       *int    `json:"height"`
+	MimeType     *string `json:"mime_type"`
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "product-app/common/decimal"
+
+// ProductStats are catalog-wide headline numbers. AvgPrice only covers products priced
+// in Currency. On an empty catalog every field but Currency is zero.
+type ProductStats struct {
+	TotalProducts      int64           `json:"total_products"`
+	DistinctStores     int64           `json:"distinct_stores"`
+	Currency           string          `json:"currency"`
+	AvgPrice           decimal.Decimal `json:"avg_price"`
+	DiscountedProducts int64           `json:"discounted_products"`
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+type Review struct {
+	Id        int64     `json:"id"`
+	ProductId int64     `json:"product_id"`
+	UserId    int64     `json:"user_id"`
+	Rating    int       `json:"rating"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ProductRating aggregates a product's reviews; both fields are zero without reviews.
+type ProductRating struct {
+	AverageRating float64 `json:"average_rating"`
+	ReviewCount   int64   `json:"review_count"`
+}
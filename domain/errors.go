@@ -0,0 +1,18 @@
+package domain
+
+import "errors"
+
+var (
+	ErrProductNotFound  = errors.New("product not found")
+	ErrUserNotFound     = errors.New("user not found")
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryNotEmpty = errors.New("category still has products")
+	ErrCategoryCycle    = errors.New("category parent would create a cycle")
+	// ErrCategoryNameTaken is returned for names that match an existing category once
+	// case and surrounding spaces are ignored.
+	ErrCategoryNameTaken = errors.New("a category with this name already exists")
+
+	ErrUncategorizedProtected = errors.New("the uncategorized category cannot be changed or deleted")
+
+	ErrProductVersionConflict = errors.New("product was modified by someone else")
+)
@@ -3,39 +3,149 @@ package main
 import (
 	"context"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"os"
 	"product-app/common/app"
+	"product-app/common/logging"
 	"product-app/common/postgresql"
 	"product-app/controller"
+	"product-app/middleware"
 	"product-app/persistence"
+	"product-app/persistence/migration"
 	"product-app/service"
+	"product-app/service/model"
+	"product-app/storage"
 )
 
 func main() {
 	ctx := context.Background()
-	e := echo.New()
 
 	configurationManager := app.NewConfigurationManager()
+	if err := logging.Configure(configurationManager.LogFormat, configurationManager.LogLevel); err != nil {
+		log.Fatalf("Unable to configure logging: %v", err)
+	}
+	if configurationManager.LogStartupConfig {
+		logging.Info("configuration loaded", configurationManager.LogFields())
+	}
+	middleware.ConfigureTokenClaims(configurationManager.JwtIssuer, configurationManager.JwtAudience)
 	dbPool := postgresql.GetConnectionPool(ctx, configurationManager.PostgreSqlConfig)
 
-	// Product
-	productRepository := persistence.NewProductRepository(dbPool)
-	productService := service.NewProductService(productRepository)
-	productController := controller.NewProductController(productService)
+	if len(os.Args) > 1 {
+		if err := runCommand(ctx, dbPool, os.Args[1:]); err != nil {
+			log.Fatalf("%v", err)
+		}
+		return
+	}
+
+	if err := migration.Up(ctx, dbPool); err != nil {
+		log.Fatalf("Unable to apply database migrations: %v", err)
+	}
+
+	pools := persistence.SinglePool(dbPool)
+	if configurationManager.DbReadSplit {
+		pools = persistence.NewPools(dbPool, postgresql.GetReplicaConnectionPool(ctx, configurationManager.PostgreSqlConfig))
+	}
+
+	readRetry := persistence.RetryPolicy{
+		Retries: configurationManager.DbReadRetries,
+		Backoff: configurationManager.DbReadRetryBackoff,
+	}
+
+	e := echo.New()
+	e.HTTPErrorHandler = controller.NewHTTPErrorHandler(e)
+	ipExtractor, err := middleware.ClientIPExtractor(configurationManager.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	e.IPExtractor = ipExtractor
+	if len(configurationManager.CorsAllowedOrigins) > 0 {
+		e.Use(middleware.CORS(configurationManager.CorsAllowedOrigins, configurationManager.CorsMaxAge))
+	}
+	e.Use(middleware.RequireJSON())
+	e.Use(middleware.Timeout(configurationManager.RequestTimeout))
+	postgresql.StartPoolStatsLogger(ctx, dbPool, configurationManager.PoolStatsLogInterval)
 
 	// Category
-	categoryRepository := persistence.NewCategoryRepository(dbPool)
-	categoryService := service.NewCategoryService(categoryRepository)
-	categoryController := controller.NewCategoryController(categoryService)
+	categoryRepository := persistence.NewCategoryRepository(pools, readRetry)
+	categoryService := service.NewCategoryService(categoryRepository, service.CategorySettings{
+		RequireDescription:   configurationManager.CategoryDescriptionRequired,
+		MaxDescriptionLength: configurationManager.MaxDescriptionLength,
+	})
+	categoryController := controller.NewCategoryController(categoryService, controller.PageSize{
+		Default: configurationManager.DefaultCategoryPageSize,
+		Max:     configurationManager.MaxPageSize,
+	})
+	uncategorized, err := categoryService.EnsureUncategorized()
+	if err != nil {
+		log.Fatalf("Unable to create the uncategorized category: %v", err)
+	}
 
-	// User
-	userRepository := persistence.NewUserRepository(dbPool)
-	userService := service.NewUserService(userRepository)
+	// Product
+	productRepository := persistence.NewProductRepository(pools, readRetry, configurationManager.MaxImagesPerProduct)
+	if !model.IsSupportedCurrency(configurationManager.DefaultCurrency) {
+		log.Fatalf("Unsupported default currency %s", configurationManager.DefaultCurrency)
+	}
+	productService := service.NewProductService(productRepository, service.ProductSettings{
+		MaxDiscount:              configurationManager.MaxDiscount,
+		DefaultCurrency:          configurationManager.DefaultCurrency,
+		MaxNewestProducts:        configurationManager.MaxNewestProducts,
+		MaxFeaturedProducts:      configurationManager.MaxFeaturedProducts,
+		MaxDescriptionLength:     configurationManager.MaxDescriptionLength,
+		RejectDuplicateImageUrls: configurationManager.RejectDuplicateImageUrls,
+		UncategorizedCategoryId:  uncategorized.Id,
+	})
+	idempotencyRepository := persistence.NewIdempotencyRepository(dbPool)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepository, productService, configurationManager.IdempotencyKeyTTL)
+	productController := controller.NewProductController(productService, categoryService, idempotencyService, controller.PageSize{
+		Default: configurationManager.DefaultProductPageSize,
+		Max:     configurationManager.MaxPageSize,
+		Sort:    configurationManager.DefaultProductSort,
+	}, configurationManager.MaxBatchSize, configurationManager.RequireAuthForProductCreate)
+
+	// Review
+	reviewRepository := persistence.NewReviewRepository(pools, readRetry)
+	reviewService := service.NewReviewService(reviewRepository, productRepository)
+	reviewController := controller.NewReviewController(reviewService)
+
+	// User; kept on the primary so logins see a registration or password change at once
+	userRepository := persistence.NewUserRepository(dbPool, readRetry)
+	userService := service.NewUserService(userRepository, service.PasswordHashParams{
+		Memory:      configurationManager.PasswordHashMemoryKiB,
+		Iterations:  configurationManager.PasswordHashIterations,
+		Parallelism: service.DefaultPasswordHashParams.Parallelism,
+	})
 	userController := controller.NewUserController(userService)
 
+	// Search
+	searchController := controller.NewSearchController(productService, categoryService, configurationManager.SearchResultLimit)
+
+	// Debug
+	debugController := controller.NewDebugController(dbPool)
+
+	// Image upload
+	var imageUploadController *controller.ImageUploadController
+	if configurationManager.S3Config.Bucket != "" {
+		s3Storage, err := storage.NewS3Storage(configurationManager.S3Config)
+		if err != nil {
+			log.Fatalf("Unable to configure image storage: %v", err)
+		}
+		if configurationManager.UploadUrlTTL <= 0 || configurationManager.UploadUrlTTL > storage.MaxPresignTTL {
+			log.Fatalf("UPLOAD_URL_TTL must be between 1s and %s", storage.MaxPresignTTL)
+		}
+		imageUploadService := service.NewImageUploadService(s3Storage, productService, configurationManager.UploadUrlTTL)
+		imageUploadController = controller.NewImageUploadController(imageUploadService, productService)
+	}
+
 	// Register routes
 	productController.RegisterRoutes(e)
 	categoryController.RegisterRoutes(e)
 	userController.RegisterRoutes(e)
+	reviewController.RegisterRoutes(e)
+	searchController.RegisterRoutes(e)
+	debugController.RegisterRoutes(e)
+	if imageUploadController != nil {
+		imageUploadController.RegisterRoutes(e)
+	}
 
-	e.Start("localhost:8080")
+	e.Start(configurationManager.ServerAddress)
 }
@@ -13,10 +13,36 @@ import (
 
 var jwtSecret = []byte(getJWTSecret())
 
+// Issuer and audience written into tokens and required on requests until
+// ConfigureTokenClaims sets others.
+const (
+	DefaultTokenIssuer   = "product-api"
+	DefaultTokenAudience = "product-api"
+)
+
+var (
+	tokenIssuer   = DefaultTokenIssuer
+	tokenAudience = DefaultTokenAudience
+)
+
+// ConfigureTokenClaims sets the iss and aud claims of new tokens and the values incoming
+// tokens must carry, e.g. to match what an API gateway in front of the service expects.
+// Call it at startup, before serving requests. Empty values keep the defaults.
+func ConfigureTokenClaims(issuer string, audience string) {
+	tokenIssuer, tokenAudience = DefaultTokenIssuer, DefaultTokenAudience
+	if issuer != "" {
+		tokenIssuer = issuer
+	}
+	if audience != "" {
+		tokenAudience = audience
+	}
+}
+
 type Claims struct {
 	UserId   int64  `json:"user_id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Role     string `json:"role"`
 	jwt.RegisteredClaims
 }
 
@@ -30,14 +56,17 @@ func getJWTSecret() string {
 }
 
 // GenerateToken creates a JWT token for a user
-func GenerateToken(userId int64, username, email string) (string, error) {
+func GenerateToken(userId int64, username, email, role string) (string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour) // Token expires in 24 hours
 
 	claims := &Claims{
 		UserId:   userId,
 		Username: username,
 		Email:    email,
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Audience:  jwt.ClaimStrings{tokenAudience},
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -57,35 +86,73 @@ func JWTMiddleware() echo.MiddlewareFunc {
 					"error": "Missing authorization header",
 				})
 			}
-
-			// Extract token from "Bearer <token>" format
-			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-			if tokenString == authHeader {
+			if message := authenticate(c, authHeader); message != "" {
 				return c.JSON(http.StatusUnauthorized, map[string]string{
-					"error": "Invalid authorization header format",
+					"error": message,
 				})
 			}
+			return next(c)
+		}
+	}
+}
 
-			// Parse and validate token
-			claims := &Claims{}
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-				}
-				return jwtSecret, nil
-			})
-
-			if err != nil || !token.Valid {
+// OptionalJWTMiddleware lets anonymous requests through to public routes that show more
+// to some users, such as admins. A token that is sent must still be valid.
+func OptionalJWTMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authHeader := c.Request().Header.Get("Authorization")
+			if authHeader == "" {
+				return next(c)
+			}
+			if message := authenticate(c, authHeader); message != "" {
 				return c.JSON(http.StatusUnauthorized, map[string]string{
-					"error": "Invalid or expired token",
+					"error": message,
 				})
 			}
+			return next(c)
+		}
+	}
+}
+
+// authenticate validates a "Bearer <token>" header and stores the user information in
+// the context for use in handlers. It returns why the header was rejected, or "" if
+// it was accepted.
+func authenticate(c echo.Context, authHeader string) string {
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == authHeader {
+		return "Invalid authorization header format"
+	}
 
-			// Store user information in context for use in handlers
-			c.Set("user_id", claims.UserId)
-			c.Set("username", claims.Username)
-			c.Set("email", claims.Email)
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return jwtSecret, nil
+	}, jwt.WithIssuer(tokenIssuer), jwt.WithAudience(tokenAudience))
+	// A missing or different iss or aud fails validation like an expired token
+	if err != nil || !token.Valid {
+		return "Invalid or expired token"
+	}
+
+	c.Set("user_id", claims.UserId)
+	c.Set("username", claims.Username)
+	c.Set("email", claims.Email)
+	c.Set("role", claims.Role)
+	return ""
+}
 
+// RequireRole rejects requests whose token does not carry the given role.
+// It must be chained after JWTMiddleware.
+func RequireRole(role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if userRole, _ := c.Get("role").(string); userRole != role {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "Insufficient permissions",
+				})
+			}
 			return next(c)
 		}
 	}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ClientIPExtractor decides where c.RealIP(), and so the rate limiter, reads the client
+// address from. X-Forwarded-For is only honoured when the connection comes from one of
+// trustedProxies, given as IPs or CIDR ranges such as "10.0.0.0/8"; its entries are
+// read right to left and the first address that is not a trusted proxy wins, so a
+// client cannot pose as someone else by sending the header itself. Without trusted
+// proxies the header is ignored and the connection's address is used.
+func ClientIPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	trustOptions := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range trustedProxies {
+		ipRange, err := parseIPRange(proxy)
+		if err != nil {
+			return nil, err
+		}
+		trustOptions = append(trustOptions, echo.TrustIPRange(ipRange))
+	}
+	return echo.ExtractIPFromXFFHeader(trustOptions...), nil
+}
+
+// parseIPRange reads a CIDR range, or a single IP as a range of one address.
+func parseIPRange(value string) (*net.IPNet, error) {
+	if strings.Contains(value, "/") {
+		_, ipRange, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy range %q: %w", value, err)
+		}
+		return ipRange, nil
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid trusted proxy address %q", value)
+	}
+	bits := 8 * net.IPv6len
+	if ip.To4() != nil {
+		ip, bits = ip.To4(), 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireJSON answers 415 Unsupported Media Type when a request carries a body that is
+// not declared as application/json, so that a form-encoded POST is reported instead
+// of binding to zero values. Requests without a body, such as most deletes, pass.
+// exemptRoutes lists route paths (as registered, e.g. "/api/v1/products/import")
+// that accept other media types like multipart uploads.
+func RequireJSON(exemptRoutes ...string) echo.MiddlewareFunc {
+	exempt := make(map[string]bool, len(exemptRoutes))
+	for _, route := range exemptRoutes {
+		exempt[route] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if !hasBody(req) || exempt[c.Path()] {
+				return next(c)
+			}
+			switch req.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				return next(c)
+			}
+
+			mediaType, _, err := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+			if err != nil || mediaType != echo.MIMEApplicationJSON {
+				return c.JSON(http.StatusUnsupportedMediaType, map[string]string{
+					"error": "Content-Type must be application/json",
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// hasBody reports whether the request announces a body, either by length or by
+// chunked transfer encoding.
+func hasBody(req *http.Request) bool {
+	return req.ContentLength > 0 || len(req.TransferEncoding) > 0
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	corsAllowedMethods = "GET, POST, PUT, PATCH, DELETE"
+	// corsExposedHeaders are the response headers browsers may read besides the safelisted ones
+	corsExposedHeaders = "X-Total-Count, Link, Idempotent-Replayed"
+)
+
+// CORS lets browsers on allowedOrigins ("*" allows any) call the API. Preflight requests
+// are answered here with 204. maxAge is how many seconds a browser may cache a preflight
+// answer; 0 asks it not to cache, which suits development.
+func CORS(allowedOrigins []string, maxAge int) echo.MiddlewareFunc {
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[origin] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			header := c.Response().Header()
+			header.Add(echo.HeaderVary, echo.HeaderOrigin)
+
+			origin := req.Header.Get(echo.HeaderOrigin)
+			if origin == "" || !(allowed["*"] || allowed[origin]) {
+				return next(c)
+			}
+			header.Set(echo.HeaderAccessControlAllowOrigin, origin)
+
+			isPreflight := req.Method == http.MethodOptions && req.Header.Get(echo.HeaderAccessControlRequestMethod) != ""
+			if !isPreflight {
+				header.Set(echo.HeaderAccessControlExposeHeaders, corsExposedHeaders)
+				return next(c)
+			}
+
+			header.Set(echo.HeaderAccessControlAllowMethods, corsAllowedMethods)
+			if requestedHeaders := req.Header.Get(echo.HeaderAccessControlRequestHeaders); requestedHeaders != "" {
+				header.Set(echo.HeaderAccessControlAllowHeaders, requestedHeaders)
+			}
+			header.Set(echo.HeaderAccessControlMaxAge, strconv.Itoa(maxAge))
+			return c.NoContent(http.StatusNoContent)
+		}
+	}
+}
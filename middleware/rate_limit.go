@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type rateLimitWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimit allows at most limit requests per client IP within each fixed window
+// and answers 429 Too Many Requests beyond that.
+func RateLimit(limit int, window time.Duration) echo.MiddlewareFunc {
+	var mutex sync.Mutex
+	windows := map[string]*rateLimitWindow{}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			now := time.Now()
+			clientIp := c.RealIP()
+
+			mutex.Lock()
+			current, ok := windows[clientIp]
+			if !ok || now.Sub(current.start) >= window {
+				// Drop expired windows so the map does not grow with every client ever seen
+				for ip, w := range windows {
+					if now.Sub(w.start) >= window {
+						delete(windows, ip)
+					}
+				}
+				current = &rateLimitWindow{start: now}
+				windows[clientIp] = current
+			}
+			current.count++
+			exceeded := current.count > limit
+			retryAfter := window - now.Sub(current.start)
+			mutex.Unlock()
+
+			if exceeded {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+				return c.JSON(http.StatusTooManyRequests, map[string]string{
+					"error": "Too many requests",
+				})
+			}
+			return next(c)
+		}
+	}
+}
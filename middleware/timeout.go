@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"product-app/common/logging"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+// timeoutBody is written by http.TimeoutHandler, which takes a fixed string.
+var timeoutBody, _ = json.Marshal(map[string]string{"error": "Request timed out"})
+
+// Timeout answers 503 Service Unavailable when a handler runs longer than timeout,
+// so a pathological request cannot hold its connection indefinitely. The handler's
+// request context is cancelled at the deadline. A non-positive timeout disables the
+// limit. exemptRoutes lists route paths (as registered) that may run longer, such as
+// streaming responses.
+func Timeout(timeout time.Duration, exemptRoutes ...string) echo.MiddlewareFunc {
+	if timeout <= 0 {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+
+	exempt := make(map[string]bool, len(exemptRoutes))
+	for _, route := range exemptRoutes {
+		exempt[route] = true
+	}
+
+	timeoutMiddleware := echomiddleware.TimeoutWithConfig(echomiddleware.TimeoutConfig{
+		Skipper: func(c echo.Context) bool {
+			return exempt[c.Path()]
+		},
+		Timeout:      timeout,
+		ErrorMessage: string(timeoutBody),
+		OnTimeoutRouteErrorHandler: func(err error, c echo.Context) {
+			logging.Warn("request timed out", logging.Fields{"method": c.Request().Method, "path": c.Path(), "error": err})
+		},
+	})
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		handler := timeoutMiddleware(next)
+		return func(c echo.Context) error {
+			c.Response().Writer = &timeoutContentTypeWriter{ResponseWriter: c.Response().Writer}
+			return handler(c)
+		}
+	}
+}
+
+// timeoutContentTypeWriter labels the timeout body as JSON. http.TimeoutHandler writes
+// it without a Content-Type, while handler responses always carry their own.
+type timeoutContentTypeWriter struct {
+	http.ResponseWriter
+}
+
+func (writer *timeoutContentTypeWriter) WriteHeader(code int) {
+	if code == http.StatusServiceUnavailable && writer.Header().Get(echo.HeaderContentType) == "" {
+		writer.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	writer.ResponseWriter.WriteHeader(code)
+}
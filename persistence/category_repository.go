@@ -4,48 +4,72 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"product-app/common/logging"
 	"product-app/domain"
 
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
-	"github.com/labstack/gommon/log"
 )
 
 type ICategoryRepository interface {
 	GetAllCategories() []domain.Category
 	GetById(categoryId int64) (domain.Category, error)
-	AddCategory(category domain.Category) error
+	GetBySlug(slug string) (domain.Category, error)
+	// GetCurrentById and GetCurrentBySlug read from the primary, for reads that follow a
+	// write or validate one.
+	GetCurrentById(categoryId int64) (domain.Category, error)
+	GetCurrentBySlug(slug string) (domain.Category, error)
+	SearchCategories(query string, limit int) ([]domain.Category, error)
+	AddCategory(category domain.Category) (int64, error)
 	UpdateCategory(category domain.Category) error
+	// DeleteById soft-deletes the category: it disappears from every read except
+	// GetBySlug and CategoryExistsByName, and RestoreCategory brings it back. A category
+	// that still has products is left alone with ErrCategoryNotEmpty.
 	DeleteById(categoryId int64) error
+	RestoreCategory(categoryId int64) error
+	CountProducts(categoryId int64) (int64, error)
+	// CategoryExistsByName reports whether a category other than excludeCategoryId has
+	// the name, ignoring case and surrounding spaces.
+	CategoryExistsByName(name string, excludeCategoryId int64) (bool, error)
+	// ReassignProductsAndDelete moves every product of one category to another and
+	// deletes the emptied category atomically, returning how many products were moved.
+	ReassignProductsAndDelete(fromCategoryId int64, toCategoryId int64) (int64, error)
 }
 
+// categoryColumns is the select list shared by every category query; keep it in sync with scanCategory.
+const categoryColumns = `id, name, slug, COALESCE(description, ''), parent_id, created_by, created_at, updated_at`
+
 type CategoryRepository struct {
-	dbPool *pgxpool.Pool
+	dbPool        *pgxpool.Pool
+	reader        Querier
+	primaryReader Querier
 }
 
-func NewCategoryRepository(dbPool *pgxpool.Pool) ICategoryRepository {
+func NewCategoryRepository(pools Pools, readRetry RetryPolicy) ICategoryRepository {
 	return &CategoryRepository{
-		dbPool: dbPool,
+		dbPool:        pools.Primary,
+		reader:        NewRetryingReader(pools.Read, readRetry),
+		primaryReader: NewRetryingReader(pools.Primary, readRetry),
 	}
 }
 
 func (categoryRepository *CategoryRepository) GetAllCategories() []domain.Category {
 	ctx := context.Background()
-	categoryRows, err := categoryRepository.dbPool.Query(ctx, "SELECT id, name, description FROM categories")
+	categoryRows, err := categoryRepository.reader.Query(ctx, "SELECT "+categoryColumns+" FROM categories WHERE deleted_at IS NULL")
 
 	if err != nil {
-		log.Errorf("Error while getting all categories %v", err)
+		logging.Error("error while getting all categories", logging.Fields{"error": err})
 		return []domain.Category{}
 	}
 
 	defer categoryRows.Close()
-	var categories []domain.Category
+	categories := []domain.Category{}
 
 	for categoryRows.Next() {
-		var c domain.Category
-		err := categoryRows.Scan(&c.Id, &c.Name, &c.Description)
+		c, err := scanCategory(categoryRows)
 		if err != nil {
-			log.Errorf("Error while scanning category: %v", err)
+			logging.Error("error while scanning category", logging.Fields{"error": err})
 			continue
 		}
 		categories = append(categories, c)
@@ -55,16 +79,23 @@ func (categoryRepository *CategoryRepository) GetAllCategories() []domain.Catego
 }
 
 func (categoryRepository *CategoryRepository) GetById(categoryId int64) (domain.Category, error) {
+	return categoryRepository.getById(categoryRepository.reader, categoryId)
+}
+
+func (categoryRepository *CategoryRepository) GetCurrentById(categoryId int64) (domain.Category, error) {
+	return categoryRepository.getById(categoryRepository.primaryReader, categoryId)
+}
+
+func (categoryRepository *CategoryRepository) getById(querier Querier, categoryId int64) (domain.Category, error) {
 	ctx := context.Background()
 
-	getByIdSql := `SELECT id, name, description FROM categories WHERE id = $1`
-	queryRow := categoryRepository.dbPool.QueryRow(ctx, getByIdSql, categoryId)
+	getByIdSql := `SELECT ` + categoryColumns + ` FROM categories WHERE id = $1 AND deleted_at IS NULL`
+	queryRow := querier.QueryRow(ctx, getByIdSql, categoryId)
 
-	var category domain.Category
-	scanErr := queryRow.Scan(&category.Id, &category.Name, &category.Description)
+	category, scanErr := scanCategory(queryRow)
 
 	if errors.Is(scanErr, pgx.ErrNoRows) {
-		return domain.Category{}, fmt.Errorf("category not found with id %d: %w", categoryId, scanErr)
+		return domain.Category{}, fmt.Errorf("%w with id %d", domain.ErrCategoryNotFound, categoryId)
 	}
 
 	if scanErr != nil {
@@ -74,64 +105,238 @@ func (categoryRepository *CategoryRepository) GetById(categoryId int64) (domain.
 	return category, nil
 }
 
-func (categoryRepository *CategoryRepository) AddCategory(category domain.Category) error {
+// GetBySlug also finds soft-deleted categories, whose slugs stay reserved until they are
+// restored.
+func (categoryRepository *CategoryRepository) GetBySlug(slug string) (domain.Category, error) {
+	return categoryRepository.getBySlug(categoryRepository.reader, slug)
+}
+
+func (categoryRepository *CategoryRepository) GetCurrentBySlug(slug string) (domain.Category, error) {
+	return categoryRepository.getBySlug(categoryRepository.primaryReader, slug)
+}
+
+func (categoryRepository *CategoryRepository) getBySlug(querier Querier, slug string) (domain.Category, error) {
+	ctx := context.Background()
+
+	getBySlugSql := `SELECT ` + categoryColumns + ` FROM categories WHERE slug = $1`
+	queryRow := querier.QueryRow(ctx, getBySlugSql, slug)
+
+	category, scanErr := scanCategory(queryRow)
+
+	if errors.Is(scanErr, pgx.ErrNoRows) {
+		return domain.Category{}, fmt.Errorf("%w with slug %s", domain.ErrCategoryNotFound, slug)
+	}
+
+	if scanErr != nil {
+		return domain.Category{}, fmt.Errorf("error while getting category with slug %s: %w", slug, scanErr)
+	}
+
+	return category, nil
+}
+
+func (categoryRepository *CategoryRepository) SearchCategories(query string, limit int) ([]domain.Category, error) {
+	ctx := context.Background()
+
+	searchSql := `SELECT ` + categoryColumns + ` FROM categories
+		WHERE (name ILIKE $1 OR description ILIKE $1) AND deleted_at IS NULL
+		ORDER BY name ASC, id ASC
+		LIMIT $2`
+	categoryRows, err := categoryRepository.reader.Query(ctx, searchSql, "%"+likeEscaper.Replace(query)+"%", limit)
+	if err != nil {
+		logging.Error("error while searching categories", logging.Fields{"query": query, "error": err})
+		return nil, fmt.Errorf("error while searching categories: %w", err)
+	}
+	defer categoryRows.Close()
+
+	categories := []domain.Category{}
+	for categoryRows.Next() {
+		category, err := scanCategory(categoryRows)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning category: %w", err)
+		}
+		categories = append(categories, category)
+	}
+	if err := categoryRows.Err(); err != nil {
+		return nil, fmt.Errorf("error during category row iteration: %w", err)
+	}
+	return categories, nil
+}
+
+func (categoryRepository *CategoryRepository) AddCategory(category domain.Category) (int64, error) {
 	ctx := context.Background()
 
 	insertCategorySQL := `
-		INSERT INTO categories (name, description)
-		VALUES ($1, $2)
+		INSERT INTO categories (name, slug, description, parent_id, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id;
 	`
 
 	var categoryId int64
 	err := categoryRepository.dbPool.QueryRow(ctx, insertCategorySQL,
-		category.Name, category.Description).Scan(&categoryId)
+		category.Name, category.Slug, category.Description, category.ParentId, category.CreatedBy, category.CreatedAt, category.UpdatedAt).Scan(&categoryId)
 
+	if isCategoryNameViolation(err) {
+		return 0, fmt.Errorf("%w: %s", domain.ErrCategoryNameTaken, category.Name)
+	}
 	if err != nil {
-		log.Printf("❌ Error inserting category: %v", err)
-		return fmt.Errorf("failed to insert category: %w", err)
+		logging.Error("error inserting category", logging.Fields{"error": err})
+		return 0, fmt.Errorf("failed to insert category: %w", err)
 	}
 
-	log.Printf("✅ Category inserted with ID: %d", categoryId)
-	return nil
+	logging.Info("category inserted", logging.Fields{"category_id": categoryId})
+	return categoryId, nil
 }
 
 func (categoryRepository *CategoryRepository) UpdateCategory(category domain.Category) error {
 	ctx := context.Background()
 
-	updateSql := `UPDATE categories SET name = $1, description = $2 WHERE id = $3`
+	updateSql := `UPDATE categories SET name = $1, slug = $2, description = $3, parent_id = $4, updated_at = $5 WHERE id = $6 AND deleted_at IS NULL`
 
-	commandTag, err := categoryRepository.dbPool.Exec(ctx, updateSql, category.Name, category.Description, category.Id)
+	commandTag, err := categoryRepository.dbPool.Exec(ctx, updateSql, category.Name, category.Slug, category.Description, category.ParentId, category.UpdatedAt, category.Id)
 
+	if isCategoryNameViolation(err) {
+		return fmt.Errorf("%w: %s", domain.ErrCategoryNameTaken, category.Name)
+	}
 	if err != nil {
 		return fmt.Errorf("error while updating category with id %d: %w", category.Id, err)
 	}
 
 	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("category with id %d not found", category.Id)
+		return fmt.Errorf("%w with id %d", domain.ErrCategoryNotFound, category.Id)
 	}
 
-	log.Printf("✅ Category updated with id %d", category.Id)
+	logging.Info("category updated", logging.Fields{"category_id": category.Id})
 	return nil
 }
 
 func (categoryRepository *CategoryRepository) DeleteById(categoryId int64) error {
 	ctx := context.Background()
 
-	deleteSql := `DELETE FROM categories WHERE id = $1`
+	// Check for products in the same statement, so one assigned between a separate count
+	// and the delete cannot end up in a deleted category
+	deleteSql := `UPDATE categories SET deleted_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM products WHERE category_id = $1)`
 
 	commandTag, err := categoryRepository.dbPool.Exec(ctx, deleteSql, categoryId)
 
 	if err != nil {
-		log.Printf("ERROR: Error while deleting category with id %d: %v", categoryId, err)
+		logging.Error("error while deleting category", logging.Fields{"category_id": categoryId, "error": err})
 		return fmt.Errorf("error while deleting category with id %d: %w", categoryId, err)
 	}
 
 	if commandTag.RowsAffected() == 0 {
-		log.Printf("WARNING: Category with id %d not found for deletion", categoryId)
-		return fmt.Errorf("category with id %d not found", categoryId)
+		productCount, err := categoryRepository.CountProducts(categoryId)
+		if err != nil {
+			return err
+		}
+		if productCount > 0 {
+			return fmt.Errorf("%w: category with id %d has %d products", domain.ErrCategoryNotEmpty, categoryId, productCount)
+		}
+		logging.Warn("category not found for deletion", logging.Fields{"category_id": categoryId})
+		return fmt.Errorf("%w with id %d", domain.ErrCategoryNotFound, categoryId)
+	}
+
+	logging.Info("category deleted", logging.Fields{"category_id": categoryId})
+	return nil
+}
+
+func (categoryRepository *CategoryRepository) RestoreCategory(categoryId int64) error {
+	ctx := context.Background()
+
+	restoreSql := `UPDATE categories SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	commandTag, err := categoryRepository.dbPool.Exec(ctx, restoreSql, categoryId)
+
+	if err != nil {
+		logging.Error("error while restoring category", logging.Fields{"category_id": categoryId, "error": err})
+		return fmt.Errorf("error while restoring category with id %d: %w", categoryId, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		logging.Warn("deleted category not found for restore", logging.Fields{"category_id": categoryId})
+		return fmt.Errorf("deleted %w with id %d", domain.ErrCategoryNotFound, categoryId)
 	}
 
-	log.Printf("INFO: Category deleted with id %d", categoryId)
+	logging.Info("category restored", logging.Fields{"category_id": categoryId})
 	return nil
-}
\ No newline at end of file
+}
+
+// CountProducts reads from the primary: it explains why a delete was refused, and a
+// lagging replica could miss a product added a moment ago.
+func (categoryRepository *CategoryRepository) CountProducts(categoryId int64) (int64, error) {
+	ctx := context.Background()
+
+	var count int64
+	err := categoryRepository.primaryReader.QueryRow(ctx, `SELECT COUNT(*) FROM products WHERE category_id = $1`, categoryId).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error while counting products of category with id %d: %w", categoryId, err)
+	}
+	return count, nil
+}
+
+// CategoryExistsByName reads from the primary so a category created a moment ago is
+// never missed; the unique index on the normalized name backs it up against races.
+// Soft-deleted categories count too, since restoring one must not clash with a newer name.
+func (categoryRepository *CategoryRepository) CategoryExistsByName(name string, excludeCategoryId int64) (bool, error) {
+	ctx := context.Background()
+
+	existsSql := `SELECT EXISTS(SELECT 1 FROM categories WHERE lower(btrim(name)) = lower(btrim($1)) AND id <> $2)`
+
+	var exists bool
+	if err := categoryRepository.dbPool.QueryRow(ctx, existsSql, name, excludeCategoryId).Scan(&exists); err != nil {
+		logging.Error("error while checking category name", logging.Fields{"name": name, "error": err})
+		return false, fmt.Errorf("error while checking category name %q: %w", name, err)
+	}
+	return exists, nil
+}
+
+// ReassignProductsAndDelete runs the reassignment and the delete in one transaction so a
+// failed delete never leaves the products moved to a category they were not meant for.
+// Unlike DeleteById the category is removed for good; it has nothing left to restore.
+func (categoryRepository *CategoryRepository) ReassignProductsAndDelete(fromCategoryId int64, toCategoryId int64) (int64, error) {
+	ctx := context.Background()
+
+	tx, err := categoryRepository.dbPool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error while starting category force delete: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Bump the version like any other product update so stale writers get a conflict
+	reassignSql := `UPDATE products SET category_id = $2, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE category_id = $1`
+
+	reassignTag, err := tx.Exec(ctx, reassignSql, fromCategoryId, toCategoryId)
+	if err != nil {
+		logging.Error("error while reassigning products", logging.Fields{"category_id": fromCategoryId, "target_category_id": toCategoryId, "error": err})
+		return 0, fmt.Errorf("error while reassigning products of category with id %d: %w", fromCategoryId, err)
+	}
+
+	deleteTag, err := tx.Exec(ctx, `DELETE FROM categories WHERE id = $1 AND deleted_at IS NULL`, fromCategoryId)
+	if err != nil {
+		logging.Error("error while deleting category", logging.Fields{"category_id": fromCategoryId, "error": err})
+		return 0, fmt.Errorf("error while deleting category with id %d: %w", fromCategoryId, err)
+	}
+	if deleteTag.RowsAffected() == 0 {
+		logging.Warn("category not found for deletion", logging.Fields{"category_id": fromCategoryId})
+		return 0, fmt.Errorf("%w with id %d", domain.ErrCategoryNotFound, fromCategoryId)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error while committing category force delete: %w", err)
+	}
+
+	logging.Info("category deleted with products reassigned", logging.Fields{"category_id": fromCategoryId, "target_category_id": toCategoryId, "count": reassignTag.RowsAffected()})
+	return reassignTag.RowsAffected(), nil
+}
+
+// isCategoryNameViolation reports a unique_violation (23505) of idx_categories_normalized_name.
+func isCategoryNameViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_categories_normalized_name"
+}
+
+func scanCategory(row pgx.Row) (domain.Category, error) {
+	var c domain.Category
+	err := row.Scan(&c.Id, &c.Name, &c.Slug, &c.Description, &c.ParentId, &c.CreatedBy, &c.CreatedAt, &c.UpdatedAt)
+	return c, err
+}
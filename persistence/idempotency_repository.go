@@ -0,0 +1,104 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"product-app/common/logging"
+	"product-app/domain"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+var ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+
+type IIdempotencyRepository interface {
+	// GetByKey returns the key with ProductId 0 while its product is still being created.
+	GetByKey(key string) (domain.IdempotencyKey, error)
+	// ReserveKey claims the key for a request, or takes over one created before
+	// expiredBefore. It reports false when another request holds the key.
+	ReserveKey(key string, requestHash string, expiredBefore time.Time) (bool, error)
+	// CompleteKey records the product created under a reserved key.
+	CompleteKey(key string, productId int64) error
+	// ReleaseKey drops a reservation whose product could not be created, so a retry
+	// can use the key again.
+	ReleaseKey(key string) error
+}
+
+type IdempotencyRepository struct {
+	dbPool *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(dbPool *pgxpool.Pool) IIdempotencyRepository {
+	return &IdempotencyRepository{
+		dbPool: dbPool,
+	}
+}
+
+func (idempotencyRepository *IdempotencyRepository) GetByKey(key string) (domain.IdempotencyKey, error) {
+	ctx := context.Background()
+
+	getByKeySql := `SELECT key, request_hash, COALESCE(product_id, 0), created_at FROM idempotency_keys WHERE key = $1`
+	queryRow := idempotencyRepository.dbPool.QueryRow(ctx, getByKeySql, key)
+
+	var idempotencyKey domain.IdempotencyKey
+	scanErr := queryRow.Scan(&idempotencyKey.Key, &idempotencyKey.RequestHash, &idempotencyKey.ProductId, &idempotencyKey.CreatedAt)
+
+	if errors.Is(scanErr, pgx.ErrNoRows) {
+		return domain.IdempotencyKey{}, fmt.Errorf("%w: %s", ErrIdempotencyKeyNotFound, key)
+	}
+
+	if scanErr != nil {
+		return domain.IdempotencyKey{}, fmt.Errorf("error while getting idempotency key %s: %w", key, scanErr)
+	}
+
+	return idempotencyKey, nil
+}
+
+func (idempotencyRepository *IdempotencyRepository) ReserveKey(key string, requestHash string, expiredBefore time.Time) (bool, error) {
+	ctx := context.Background()
+
+	// The primary key makes concurrent reservations of the same key race safely: exactly
+	// one of them inserts or takes over the expired row
+	reserveKeySql := `
+		INSERT INTO idempotency_keys (key, request_hash, product_id, created_at)
+		VALUES ($1, $2, NULL, $3)
+		ON CONFLICT (key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, product_id = NULL, created_at = EXCLUDED.created_at
+		WHERE idempotency_keys.created_at < $4
+	`
+
+	commandTag, err := idempotencyRepository.dbPool.Exec(ctx, reserveKeySql, key, requestHash, time.Now(), expiredBefore)
+	if err != nil {
+		logging.Error("error reserving idempotency key", logging.Fields{"idempotency_key": key, "error": err})
+		return false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	return commandTag.RowsAffected() == 1, nil
+}
+
+func (idempotencyRepository *IdempotencyRepository) CompleteKey(key string, productId int64) error {
+	ctx := context.Background()
+
+	_, err := idempotencyRepository.dbPool.Exec(ctx, `UPDATE idempotency_keys SET product_id = $2 WHERE key = $1`, key, productId)
+	if err != nil {
+		logging.Error("error saving idempotency key", logging.Fields{"idempotency_key": key, "product_id": productId, "error": err})
+		return fmt.Errorf("failed to save idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (idempotencyRepository *IdempotencyRepository) ReleaseKey(key string) error {
+	ctx := context.Background()
+
+	_, err := idempotencyRepository.dbPool.Exec(ctx, `DELETE FROM idempotency_keys WHERE key = $1 AND product_id IS NULL`, key)
+	if err != nil {
+		logging.Error("error releasing idempotency key", logging.Fields{"idempotency_key": key, "error": err})
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
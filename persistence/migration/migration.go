@@ -0,0 +1,187 @@
+package migration
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+)
+
+//go:embed sql/*.sql
+var migrationFiles embed.FS
+
+type migration struct {
+	Version int64
+	Name    string
+	UpSql   string
+	DownSql string
+}
+
+const createMigrationsTableSql = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)
+`
+
+// Up applies every migration that has not been applied yet, in version order.
+func Up(ctx context.Context, dbPool *pgxpool.Pool) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	applied, err := appliedVersions(ctx, dbPool)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+
+		tx, err := dbPool.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("error while starting migration %d: %w", m.Version, err)
+		}
+		if _, err := tx.Exec(ctx, m.UpSql); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("error while applying migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.Version, m.Name); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("error while recording migration %d: %w", m.Version, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("error while committing migration %d: %w", m.Version, err)
+		}
+		log.Infof("✅ Applied migration %d_%s", m.Version, m.Name)
+	}
+	return nil
+}
+
+// Down reverts the given number of most recently applied migrations.
+func Down(ctx context.Context, dbPool *pgxpool.Pool, steps int) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	applied, err := appliedVersions(ctx, dbPool)
+	if err != nil {
+		return err
+	}
+
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		m := migrations[i]
+		if !applied[m.Version] {
+			continue
+		}
+
+		tx, err := dbPool.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("error while starting rollback of migration %d: %w", m.Version, err)
+		}
+		if _, err := tx.Exec(ctx, m.DownSql); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("error while reverting migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.Version); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("error while unrecording migration %d: %w", m.Version, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("error while committing rollback of migration %d: %w", m.Version, err)
+		}
+		log.Infof("✅ Reverted migration %d_%s", m.Version, m.Name)
+		steps--
+	}
+	return nil
+}
+
+func appliedVersions(ctx context.Context, dbPool *pgxpool.Pool) (map[int64]bool, error) {
+	if _, err := dbPool.Exec(ctx, createMigrationsTableSql); err != nil {
+		return nil, fmt.Errorf("error while creating schema_migrations table: %w", err)
+	}
+
+	rows, err := dbPool.Query(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("error scanning migration version: %w", err)
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// loadMigrations pairs the embedded NNNN_name.up.sql / NNNN_name.down.sql files.
+func loadMigrations() ([]migration, error) {
+	entries, err := migrationFiles.ReadDir("sql")
+	if err != nil {
+		return nil, fmt.Errorf("error while reading migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		baseName := strings.TrimSuffix(strings.TrimSuffix(fileName, ".sql"), "."+direction)
+		versionPart, name, found := strings.Cut(baseName, "_")
+		if !found {
+			return nil, fmt.Errorf("migration file %s must be named NNNN_name.%s.sql", fileName, direction)
+		}
+		version, err := strconv.ParseInt(versionPart, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration file %s has an invalid version: %w", fileName, err)
+		}
+
+		content, err := migrationFiles.ReadFile("sql/" + fileName)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading migration %s: %w", fileName, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.UpSql = string(content)
+		} else {
+			m.DownSql = string(content)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.UpSql == "" || m.DownSql == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    slug VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_categories_name ON categories(name);
+
+-- Databases set up with the old database_schema.sql already have the table, without a
+-- slug; derive one from the name and suffix later duplicates with their id
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS slug VARCHAR(255);
+
+UPDATE categories c SET slug = s.slug
+FROM (
+    SELECT id, CASE WHEN ROW_NUMBER() OVER (PARTITION BY base ORDER BY id) = 1 THEN base ELSE base || '-' || id END AS slug
+    FROM (
+        SELECT id, COALESCE(NULLIF(TRIM(BOTH '-' FROM REGEXP_REPLACE(LOWER(name), '[^a-z0-9]+', '-', 'g')), ''), 'category') AS base
+        FROM categories
+    ) bases
+) s
+WHERE c.id = s.id AND c.slug IS NULL;
+
+ALTER TABLE categories ALTER COLUMN slug SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS categories_slug_key ON categories(slug);
+CREATE INDEX IF NOT EXISTS idx_categories_slug ON categories(slug);
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS products (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    price DOUBLE PRECISION NOT NULL,
+    description VARCHAR(350) NOT NULL,
+    discount DOUBLE PRECISION,
+    store VARCHAR(255) NOT NULL,
+    version INT NOT NULL DEFAULT 1,
+    category_id BIGINT REFERENCES categories(id) ON DELETE SET NULL,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Databases set up with the old database_schema.sql already have the table. Add the
+-- columns it lacks, and let user_id be NULL and cleared on user delete as above instead
+-- of NOT NULL with a cascading delete
+ALTER TABLE products ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category_id BIGINT REFERENCES categories(id) ON DELETE SET NULL;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS user_id BIGINT;
+ALTER TABLE products ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS fk_products_user;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_user_id_fkey;
+ALTER TABLE products ADD CONSTRAINT products_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products(category_id);
+CREATE INDEX IF NOT EXISTS idx_products_user_id ON products(user_id);
+CREATE INDEX IF NOT EXISTS idx_products_store ON products(store);
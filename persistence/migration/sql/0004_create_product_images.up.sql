@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS product_images (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    image_urls TEXT NOT NULL,
+    is_main_image BOOLEAN DEFAULT FALSE,
+    display_order INT DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product_id ON product_images(product_id, display_order);
@@ -0,0 +1,4 @@
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS created_by,
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN updated_at DROP NOT NULL;
@@ -0,0 +1,7 @@
+UPDATE categories SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE categories SET updated_at = created_at WHERE updated_at IS NULL;
+
+ALTER TABLE categories
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET NOT NULL,
+    ADD COLUMN IF NOT EXISTS created_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
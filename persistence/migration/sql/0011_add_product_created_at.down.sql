@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_created_at;
+
+ALTER TABLE products DROP COLUMN IF EXISTS created_at;
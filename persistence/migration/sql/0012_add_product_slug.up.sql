@@ -0,0 +1,16 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS slug VARCHAR(255);
+
+-- Existing products get a slug from their name; later duplicates are suffixed with their id
+UPDATE products p SET slug = s.slug
+FROM (
+    SELECT id, CASE WHEN ROW_NUMBER() OVER (PARTITION BY base ORDER BY id) = 1 THEN base ELSE base || '-' || id END AS slug
+    FROM (
+        SELECT id, COALESCE(NULLIF(TRIM(BOTH '-' FROM REGEXP_REPLACE(LOWER(name), '[^a-z0-9]+', '-', 'g')), ''), 'product') AS base
+        FROM products
+    ) bases
+) s
+WHERE p.id = s.id AND p.slug IS NULL;
+
+ALTER TABLE products ALTER COLUMN slug SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_slug ON products(slug);
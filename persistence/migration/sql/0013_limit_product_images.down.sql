@@ -0,0 +1 @@
+ALTER TABLE product_images DROP CONSTRAINT IF EXISTS product_images_url_length;
@@ -0,0 +1,3 @@
+-- NOT VALID keeps existing rows untouched; the limit applies to every new or updated image
+ALTER TABLE product_images DROP CONSTRAINT IF EXISTS product_images_url_length;
+ALTER TABLE product_images ADD CONSTRAINT product_images_url_length CHECK (char_length(image_urls) <= 2048) NOT VALID;
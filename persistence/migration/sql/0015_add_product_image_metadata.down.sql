@@ -0,0 +1,4 @@
+ALTER TABLE product_images
+    DROP COLUMN IF EXISTS mime_type,
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS width;
@@ -0,0 +1,4 @@
+ALTER TABLE product_images
+    ADD COLUMN IF NOT EXISTS width INTEGER CHECK (width > 0),
+    ADD COLUMN IF NOT EXISTS height INTEGER CHECK (height > 0),
+    ADD COLUMN IF NOT EXISTS mime_type VARCHAR(100);
@@ -0,0 +1,7 @@
+ALTER TABLE price_history
+    ALTER COLUMN old_price TYPE DOUBLE PRECISION,
+    ALTER COLUMN new_price TYPE DOUBLE PRECISION;
+
+ALTER TABLE products
+    ALTER COLUMN price TYPE DOUBLE PRECISION,
+    ALTER COLUMN discount TYPE DOUBLE PRECISION;
@@ -0,0 +1,7 @@
+ALTER TABLE products
+    ALTER COLUMN price TYPE NUMERIC(12, 2) USING ROUND(price::numeric, 2),
+    ALTER COLUMN discount TYPE NUMERIC(5, 2) USING ROUND(discount::numeric, 2);
+
+ALTER TABLE price_history
+    ALTER COLUMN old_price TYPE NUMERIC(12, 2) USING ROUND(old_price::numeric, 2),
+    ALTER COLUMN new_price TYPE NUMERIC(12, 2) USING ROUND(new_price::numeric, 2);
@@ -0,0 +1,10 @@
+-- Categories that only differ in case or surrounding spaces would block the index;
+-- keep the oldest one's name and suffix the others with their id.
+UPDATE categories c
+SET name = c.name || ' ' || c.id
+WHERE EXISTS (
+    SELECT 1 FROM categories older
+    WHERE lower(btrim(older.name)) = lower(btrim(c.name)) AND older.id < c.id
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_normalized_name ON categories (lower(btrim(name)));
@@ -0,0 +1,14 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- Existing products have not changed since they were created as far as sync clients know
+UPDATE products SET updated_at = created_at;
+
+CREATE INDEX IF NOT EXISTS idx_products_updated_at ON products(updated_at, id);
+
+-- Tombstones for deleted products, so sync clients learn about deletions
+CREATE TABLE IF NOT EXISTS product_deletions (
+    product_id BIGINT PRIMARY KEY,
+    deleted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_deletions_deleted_at ON product_deletions(deleted_at, product_id);
@@ -0,0 +1,4 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS is_featured BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The featured listing only ever reads the handful of flagged products
+CREATE INDEX IF NOT EXISTS idx_products_featured ON products(created_at DESC, id DESC) WHERE is_featured;
@@ -0,0 +1,2 @@
+-- NULL means stock is not tracked for the product, so existing products stay purchasable
+ALTER TABLE products ADD COLUMN IF NOT EXISTS stock INTEGER CHECK (stock >= 0);
@@ -0,0 +1,2 @@
+DELETE FROM categories WHERE deleted_at IS NOT NULL;
+ALTER TABLE categories DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,2 @@
+-- Deleted categories keep their row, name and slug so they can be restored
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
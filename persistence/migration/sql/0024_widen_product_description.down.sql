@@ -0,0 +1 @@
+ALTER TABLE products ALTER COLUMN description TYPE VARCHAR(350) USING LEFT(description, 350);
@@ -0,0 +1,2 @@
+-- VARCHAR(350) predates MAX_DESCRIPTION_LENGTH; the service enforces the configured limit
+ALTER TABLE products ALTER COLUMN description TYPE TEXT;
@@ -0,0 +1,2 @@
+DELETE FROM idempotency_keys WHERE product_id IS NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN product_id SET NOT NULL;
@@ -0,0 +1,2 @@
+-- A key is reserved before its product is created; product_id stays NULL until then
+ALTER TABLE idempotency_keys ALTER COLUMN product_id DROP NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE product_images ALTER COLUMN display_order DROP NOT NULL;
@@ -0,0 +1,10 @@
+-- Images without a display order sorted last; keep them there, in upload order
+UPDATE product_images i SET display_order = o.display_order
+FROM (
+    SELECT id, COALESCE(MAX(display_order) OVER (PARTITION BY product_id), -1)
+               + ROW_NUMBER() OVER (PARTITION BY product_id, display_order IS NULL ORDER BY id) AS display_order
+    FROM product_images
+) o
+WHERE i.id = o.id AND i.display_order IS NULL;
+
+ALTER TABLE product_images ALTER COLUMN display_order SET NOT NULL;
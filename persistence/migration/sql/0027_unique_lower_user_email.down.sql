@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS users_email_lower_key;
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
@@ -0,0 +1,13 @@
+-- Accounts whose emails differ only in case cannot be merged automatically
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users GROUP BY LOWER(email) HAVING COUNT(*) > 1) THEN
+        RAISE EXCEPTION 'some users share an email that differs only in case; resolve them before migrating';
+    END IF;
+END
+$$;
+
+UPDATE users SET email = LOWER(email) WHERE email <> LOWER(email);
+
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_key ON users (LOWER(email));
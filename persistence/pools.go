@@ -0,0 +1,23 @@
+package persistence
+
+import "github.com/jackc/pgx/v4/pgxpool"
+
+// Pools pairs the primary pool, which takes every write, with the pool that plain reads
+// such as listings and lookups by id are sent to.
+type Pools struct {
+	Primary *pgxpool.Pool
+	Read    *pgxpool.Pool
+}
+
+// NewPools routes reads to replica, or to primary when replica is nil.
+func NewPools(primary *pgxpool.Pool, replica *pgxpool.Pool) Pools {
+	if replica == nil {
+		return Pools{Primary: primary, Read: primary}
+	}
+	return Pools{Primary: primary, Read: replica}
+}
+
+// SinglePool sends reads and writes to the same pool.
+func SinglePool(pool *pgxpool.Pool) Pools {
+	return NewPools(pool, nil)
+}
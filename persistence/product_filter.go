@@ -0,0 +1,82 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"product-app/domain"
+	"product-app/service/model"
+	"strings"
+)
+
+// likeEscaper escapes LIKE wildcards so user input is matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ErrPriceRangeWithoutCurrency is returned when a filter bounds the price without
+// saying which currency the bounds are in.
+var ErrPriceRangeWithoutCurrency = errors.New("price range requires a currency")
+
+// buildProductFilterWhere turns a ProductFilter into a WHERE clause over the "p" alias
+// together with its positional arguments.
+func buildProductFilterWhere(filter model.ProductFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	switch filter.Status {
+	case "":
+		addCondition("p.status = $%d", domain.ProductStatusActive)
+	case model.ProductStatusAll:
+	default:
+		addCondition("p.status = $%d", filter.Status)
+	}
+	if filter.AfterId != nil {
+		addCondition("p.id > $%d", *filter.AfterId)
+	}
+	if filter.Ids != nil {
+		addCondition("p.id = ANY($%d)", filter.Ids)
+	}
+	if filter.Store != "" {
+		addCondition("p.store = $%d", filter.Store)
+	}
+	if filter.CategoryId > 0 {
+		addCondition("p.category_id = $%d", filter.CategoryId)
+	}
+	if filter.UserId > 0 {
+		addCondition("p.user_id = $%d", filter.UserId)
+	}
+	if filter.Tag != "" {
+		addCondition(`EXISTS (
+            SELECT 1 FROM product_tags pt JOIN tags t ON t.id = pt.tag_id
+            WHERE pt.product_id = p.id AND t.name = $%d
+        )`, filter.Tag)
+	}
+	if filter.Currency != "" {
+		addCondition("p.currency = $%d", filter.Currency)
+	}
+	if filter.MinPrice != nil {
+		addCondition("p.price >= $%d", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		addCondition("p.price <= $%d", *filter.MaxPrice)
+	}
+	if filter.CreatedFrom != nil {
+		addCondition("p.created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		addCondition("p.created_at <= $%d", *filter.CreatedTo)
+	}
+	if filter.Featured {
+		conditions = append(conditions, "p.is_featured")
+	}
+	if filter.Search != "" {
+		addCondition("(p.name ILIKE $%[1]d OR p.description ILIKE $%[1]d)", "%"+likeEscaper.Replace(filter.Search)+"%")
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
@@ -4,331 +4,1228 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"product-app/common/decimal"
+	"product-app/common/logging"
 	"product-app/domain"
+	"product-app/service/model"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
-	"github.com/labstack/gommon/log"
 )
 
 type IProductRepository interface {
+	Find(filter model.ProductFilter) ([]domain.Product, int64, error)
 	GettAllProducts() []domain.Product
-	GetProductsByCategoryId(categoryId int64) ([]domain.Product, error)
+	GetProductsByCategoryId(categoryId int64, priceRange model.PriceRange, pageRequest model.PageRequest) ([]domain.Product, int64, error)
+	// Deprecated: GetAllProductsByStore has no pagination or total; use Find with a
+	// store filter.
 	GetAllProductsByStore(storeName string) []domain.Product
-	AddProduct(product domain.Product) error
+	GetAllProductsByUser(userId int64) []domain.Product
+	GetAllProductsByTag(tag string) []domain.Product
+	GetProductsCreatedBetween(from time.Time, to time.Time) ([]domain.Product, error)
+	AddProduct(product domain.Product) (int64, error)
+	AddProductImages(productId int64, images []domain.ProductImage) error
 	GetById(productId int64) (domain.Product, error)
+	GetByIdWithCategory(productId int64) (domain.ProductWithCategory, error)
+	GetBySlug(slug string) (domain.Product, error)
+	// GetCurrentById and GetCurrentBySlug read from the primary. Use them right after a
+	// write and for checks that must not miss one, where a lagging replica would not do.
+	GetCurrentById(productId int64) (domain.Product, error)
+	GetCurrentBySlug(slug string) (domain.Product, error)
+	// GetCurrentByIds loads the products with the given ids from the primary, whatever
+	// their status, in no particular order.
+	GetCurrentByIds(productIds []int64) ([]domain.Product, error)
 	DeleteById(productId int64) error
-	UpdatePrice(productId int64, newPrice float32) error
+	DeleteByIds(productIds []int64, dryRun bool) (deleted int64, notFoundIds []int64, err error)
+	UpdatePrice(productId int64, newPrice decimal.Decimal, version int, changedBy int64) error
+	GetPriceHistory(productId int64) ([]domain.PriceChange, error)
+	UpdateProductPartial(productId int64, patch model.ProductPatch, changedBy int64) error
+	UpdateProductCategory(productId int64, categoryId int64) error
+	UpdateProductStatus(productId int64, status string) error
+	SetFeatured(productId int64, featured bool) error
 	DeleteAllProducts() error
+	AttachTags(productId int64, tags []string) error
+	DetachTag(productId int64, tag string) error
+	CategoryExists(categoryId int64) (bool, error)
+	GetPriceStatsByCategory(categoryId int64, currency string) (domain.PriceStats, error)
+	CountProductsByCategory(categoryId int64) (int64, error)
+	GetProductStats(currency string) (domain.ProductStats, error)
+	GetProductImages(productId int64) ([]domain.ProductImage, error)
+	// GetRating aggregates the product's reviews. Product reads leave the rating out, so
+	// only callers that show it pay for the aggregate.
+	GetRating(productId int64) (domain.ProductRating, error)
+	// NormalizeImageOrder renumbers the product's images 0..n-1 in their current order.
+	NormalizeImageOrder(productId int64) error
+	// NormalizeAllImageOrders does the same for every product and returns how many
+	// products had gaps or duplicates.
+	NormalizeAllImageOrders() (int64, error)
+	// GetProductChanges lists products updated or deleted after the cursor, oldest first.
+	// Only the id, time and Deleted flag of each change are set.
+	GetProductChanges(after model.ChangeCursor, limit int) ([]domain.ProductChange, error)
 }
 
+// productColumns is the select list shared by every product query; keep it in sync with productScanTargets.
+const productColumns = `p.id, p.name, p.slug, p.price, p.description, p.discount, p.store, p.currency, COALESCE(p.category_id, 0), p.version, p.status, p.is_featured, p.stock,
+	(SELECT array_agg(t.name ORDER BY t.name) FROM product_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.product_id = p.id)`
+
+// productSortClauses maps the public sort keys to ORDER BY clauses; id breaks ties so pages are stable.
+var productSortClauses = map[string]string{
+	"":              "p.id ASC",
+	"price_asc":     "p.price ASC, p.id ASC",
+	"price_desc":    "p.price DESC, p.id ASC",
+	"name_asc":      "p.name ASC, p.id ASC",
+	"name_desc":     "p.name DESC, p.id ASC",
+	"discount_desc": "p.discount DESC NULLS LAST, p.id ASC",
+	"newest":        "p.created_at DESC, p.id DESC",
+}
+
+func IsValidProductSort(sort string) bool {
+	_, ok := productSortClauses[sort]
+	return ok
+}
+
+// MaxImageUrlLength mirrors the length check on product_images.image_urls.
+const MaxImageUrlLength = 2048
+
+const DefaultMaxImagesPerProduct = 10
+
+var (
+	ErrTooManyImages   = errors.New("too many images")
+	ErrImageUrlTooLong = errors.New("image url is too long")
+)
+
 type ProductRepository struct {
 	dbPool *pgxpool.Pool
+	// reader serves listings and lookups and may lag behind; primaryReader serves the
+	// reads that must see every committed write
+	reader              Querier
+	primaryReader       Querier
+	maxImagesPerProduct int
 }
 
-func NewProductRepository(dbPool *pgxpool.Pool) IProductRepository {
+func NewProductRepository(pools Pools, readRetry RetryPolicy, maxImagesPerProduct int) IProductRepository {
 	return &ProductRepository{
-		dbPool: dbPool,
+		dbPool:              pools.Primary,
+		reader:              NewRetryingReader(pools.Read, readRetry),
+		primaryReader:       NewRetryingReader(pools.Primary, readRetry),
+		maxImagesPerProduct: maxImagesPerProduct,
 	}
 }
 
-func (productRepository *ProductRepository) GettAllProducts() []domain.Product {
+// Find returns the page of products matching the filter together with the total match count.
+func (productRepository *ProductRepository) Find(filter model.ProductFilter) ([]domain.Product, int64, error) {
 	ctx := context.Background()
-	productRows, err := productRepository.dbPool.Query(ctx, "SELECT id, name, price, description, discount, store, category_id FROM products")
 
-	if err != nil {
-		log.Errorf("Error while getting all products: %v", err)
-		return []domain.Product{}
+	sortClause, ok := productSortClauses[filter.Sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("unsupported sort %q", filter.Sort)
+	}
+	// Prices in different currencies are not comparable, so a price range only makes sense within one.
+	if (filter.MinPrice != nil || filter.MaxPrice != nil) && filter.Currency == "" {
+		return nil, 0, ErrPriceRangeWithoutCurrency
 	}
 
+	if filter.AfterId != nil && filter.Sort != "" {
+		return nil, 0, fmt.Errorf("sort %q cannot be combined with a cursor", filter.Sort)
+	}
+
+	whereClause, args := buildProductFilterWhere(filter)
+
+	// Keyset pages skip the count: it would scan every remaining row on each page
+	var total int64
+	if filter.AfterId == nil {
+		countSql := `SELECT COUNT(*) FROM products p` + whereClause
+		if err := productRepository.reader.QueryRow(ctx, countSql, args...).Scan(&total); err != nil {
+			logging.Error("error while counting products", logging.Fields{"error": err})
+			return nil, 0, fmt.Errorf("error while counting products: %w", err)
+		}
+	}
+
+	args = append(args, filter.Offset)
+	query := `SELECT ` + productColumns + ` FROM products p` + whereClause +
+		` ORDER BY ` + sortClause + fmt.Sprintf(` OFFSET $%d`, len(args))
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	productRows, err := productRepository.reader.Query(ctx, query, args...)
+	if err != nil {
+		logging.Error("error while querying products", logging.Fields{"error": err})
+		return nil, 0, fmt.Errorf("error while querying products: %w", err)
+	}
 	defer productRows.Close()
-	products, err := productRepository.extractProductFromRows(ctx, productRows)
 
+	products, err := productRepository.extractProductFromRows(ctx, productRepository.reader, productRows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+func (productRepository *ProductRepository) GettAllProducts() []domain.Product {
+	products, _, err := productRepository.Find(model.ProductFilter{})
 	if err != nil {
-		log.Errorf("Error while extracting products from rows: %v", err)
+		logging.Error("error while getting all products", logging.Fields{"error": err})
 		return []domain.Product{}
 	}
 	return products
 }
 
+// Deprecated: GetAllProductsByStore has no pagination or total; use Find with a
+// store filter.
 func (productRepository *ProductRepository) GetAllProductsByStore(storeName string) []domain.Product {
+	products, _, err := productRepository.Find(model.ProductFilter{Store: storeName})
+	if err != nil {
+		logging.Error("error while querying products by store", logging.Fields{"store": storeName, "error": err})
+		return []domain.Product{}
+	}
+	return products
+}
+
+func (productRepository *ProductRepository) GetAllProductsByUser(userId int64) []domain.Product {
+	products, _, err := productRepository.Find(model.ProductFilter{UserId: userId, Status: model.ProductStatusAll})
+	if err != nil {
+		logging.Error("error while querying products by user", logging.Fields{"user_id": userId, "error": err})
+		return []domain.Product{}
+	}
+	return products
+}
+
+func (productRepository *ProductRepository) AddProduct(product domain.Product) (int64, error) {
 	ctx := context.Background()
 
-	getProductByStoreNameSql := `
-        SELECT id, name, price, description, discount, store, category_id
-        FROM products
-        WHERE store = $1
+	// CategoryID 0 means "uncategorized" and UserId 0 "created anonymously"; both are
+	// stored as NULL to satisfy the foreign keys
+	insertProductSQL := `
+        INSERT INTO products (name, price, description, discount, store, category_id, currency, slug, status, stock, user_id)
+        VALUES ($1, $2, $3, $4, $5, NULLIF($6::bigint, 0), $7, $8, $9, $10, NULLIF($11::bigint, 0))
+        RETURNING id;
     `
 
-	productRows, err := productRepository.dbPool.Query(ctx, getProductByStoreNameSql, storeName)
-	if err != nil {
-		log.Errorf("❌ Error while querying products by store: %v", err)
-		return []domain.Product{}
+	images := imagesOf(product)
+	// Check the image cap up front so a rejected product is not left half inserted
+	if err := productRepository.checkImages(0, images); err != nil {
+		return 0, err
 	}
-	defer productRows.Close()
 
-	var products []domain.Product
+	var productId int64
+	err := productRepository.dbPool.QueryRow(ctx, insertProductSQL,
+		product.Name, product.Price, product.Description, product.Discount, product.Store, product.CategoryID, product.Currency, product.Slug, product.Status, product.Stock, product.UserId).Scan(&productId)
 
-	for productRows.Next() {
-		var p domain.Product
-		// Scan işleminden user_id kaldırıldı
-		err := productRows.Scan(&p.Id, &p.Name, &p.Price, &p.Description, &p.Discount, &p.Store, &p.CategoryID)
-		if err != nil {
-			log.Errorf("❌ Error while scanning product for store: %v", err)
-			continue
-		}
+	if err != nil {
+		logging.Error("error inserting product", logging.Fields{"error": err})
+		return 0, fmt.Errorf("failed to insert product: %w", err)
+	}
 
-		imageRows, err := productRepository.dbPool.Query(ctx, `
-            SELECT image_urls FROM product_images
-            WHERE product_id = $1
-            ORDER BY display_order
-        `, p.Id)
-		if err != nil {
-			log.Errorf("❌ Error while querying images for store product: %v", err)
-			continue
-		}
+	logging.Info("product inserted", logging.Fields{"product_id": productId})
 
-		var imageUrls []string
-		for imageRows.Next() {
-			var url string
-			if err := imageRows.Scan(&url); err != nil {
-				log.Errorf("❌ Failed to scan image url for store product: %v", err)
-				continue
-			}
-			imageUrls = append(imageUrls, url)
-		}
-		imageRows.Close()
+	if err := productRepository.insertImages(ctx, productRepository.dbPool, productId, 0, true, images); err != nil {
+		return 0, err
+	}
 
-		p.ImageUrls = imageUrls
-		products = append(products, p)
+	if err := productRepository.attachTags(ctx, productId, product.Tags); err != nil {
+		return 0, err
 	}
 
-	return products
+	logging.Info("product and images added", logging.Fields{"product_id": productId, "image_count": len(images)})
+	return productId, nil
 }
 
-func (productRepository *ProductRepository) AddProduct(product domain.Product) error {
+// AddProductImages appends images after the product's existing ones. It rejects the
+// whole batch when the product would end up with more than the configured number of images.
+func (productRepository *ProductRepository) AddProductImages(productId int64, images []domain.ProductImage) error {
 	ctx := context.Background()
 
-	// INSERT sorgusundan user_id kaldırıldı
-	insertProductSQL := `
-        INSERT INTO products (name, price, description, discount, store, category_id)
-        VALUES ($1, $2, $3, $4, $5, $6)
-        RETURNING id;
-    `
+	tx, err := productRepository.dbPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting image upload: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-	var productId int64
-	// QueryRow parametrelerinden product.UserID kaldırıldı
-	err := productRepository.dbPool.QueryRow(ctx, insertProductSQL,
-		product.Name, product.Price, product.Description, product.Discount, product.Store, product.CategoryID).Scan(&productId)
+	// Lock the product so concurrent uploads count and insert one after the other and
+	// cannot both pass the cap
+	var lockedId int64
+	err = tx.QueryRow(ctx, `SELECT id FROM products WHERE id = $1 FOR UPDATE`, productId).Scan(&lockedId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w with id %d", domain.ErrProductNotFound, productId)
+	}
+	if err != nil {
+		return fmt.Errorf("error while locking product with id %d: %w", productId, err)
+	}
 
+	// Orders can have gaps, so new images go after the highest one rather than the count
+	var existingImages, nextDisplayOrder int
+	var hasMainImage bool
+	err = tx.QueryRow(ctx, `
+        SELECT COUNT(*), COALESCE(MAX(display_order), -1) + 1, COALESCE(BOOL_OR(is_main_image), false)
+        FROM product_images WHERE product_id = $1
+    `, productId).Scan(&existingImages, &nextDisplayOrder, &hasMainImage)
 	if err != nil {
-		log.Errorf("❌ Error inserting product: %v", err) // Log mesajı güncellendi
-		return fmt.Errorf("failed to insert product: %w", err)
+		return fmt.Errorf("error while counting images of product with id %d: %w", productId, err)
+	}
+	if err := productRepository.checkImages(existingImages, images); err != nil {
+		return err
+	}
+	if err := productRepository.insertImages(ctx, tx, productId, nextDisplayOrder, !hasMainImage, images); err != nil {
+		return err
+	}
+	if err := productRepository.touch(ctx, tx, productId); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error while committing images of product with id %d: %w", productId, err)
 	}
+	return nil
+}
 
-	log.Printf("✅ Product inserted with ID: %d", productId)
+func (productRepository *ProductRepository) checkImages(existingImages int, images []domain.ProductImage) error {
+	if existingImages+len(images) > productRepository.maxImagesPerProduct {
+		return fmt.Errorf("%w: a product can have at most %d images", ErrTooManyImages, productRepository.maxImagesPerProduct)
+	}
+	for _, image := range images {
+		if len(image.Url) > MaxImageUrlLength {
+			return fmt.Errorf("%w: image urls can be at most %d characters", ErrImageUrlTooLong, MaxImageUrlLength)
+		}
+	}
+	return nil
+}
 
+// insertImages stores images with display orders following firstDisplayOrder. With
+// firstIsMain the first of them becomes the product's main image.
+func (productRepository *ProductRepository) insertImages(ctx context.Context, executor execer, productId int64, firstDisplayOrder int, firstIsMain bool, images []domain.ProductImage) error {
 	insertImageSQL := `
-        INSERT INTO product_images (product_id, image_urls, is_main_image, display_order)
-        VALUES ($1, $2, $3, $4);
+        INSERT INTO product_images (product_id, image_urls, is_main_image, display_order, width, height, mime_type)
+        VALUES ($1, $2, $3, $4, $5, $6, $7);
     `
 
-	for i, url := range product.ImageUrls {
-		isMain := (i == 0)
-		_, err := productRepository.dbPool.Exec(ctx, insertImageSQL, productId, url, isMain, i)
+	for i, image := range images {
+		displayOrder := firstDisplayOrder + i
+		_, err := executor.Exec(ctx, insertImageSQL, productId, image.Url, firstIsMain && i == 0, displayOrder,
+			image.Width, image.Height, image.MimeType)
 		if err != nil {
-			log.Errorf("❌ Error inserting image for product %d: %v", productId, err) // Log mesajı güncellendi
+			logging.Error("error inserting product image", logging.Fields{"product_id": productId, "error": err})
 			return fmt.Errorf("failed to insert image: %w", err)
 		}
 	}
+	return nil
+}
+
+// imagesOf returns the images to store for a new product: Images when given, otherwise
+// ImageUrls without metadata.
+func imagesOf(product domain.Product) []domain.ProductImage {
+	if len(product.Images) > 0 {
+		return product.Images
+	}
+	images := make([]domain.ProductImage, len(product.ImageUrls))
+	for i, url := range product.ImageUrls {
+		images[i] = domain.ProductImage{Url: url}
+	}
+	return images
+}
+
+func (productRepository *ProductRepository) GetAllProductsByTag(tag string) []domain.Product {
+	products, _, err := productRepository.Find(model.ProductFilter{Tag: tag})
+	if err != nil {
+		logging.Error("error while querying products by tag", logging.Fields{"tag": tag, "error": err})
+		return []domain.Product{}
+	}
+	return products
+}
+
+// GetProductsCreatedBetween returns the products created in [from, to], newest first.
+func (productRepository *ProductRepository) GetProductsCreatedBetween(from time.Time, to time.Time) ([]domain.Product, error) {
+	products, _, err := productRepository.Find(model.ProductFilter{
+		CreatedFrom: &from,
+		CreatedTo:   &to,
+		PageRequest: model.PageRequest{Sort: "newest"},
+	})
+	if err != nil {
+		logging.Error("error while querying products by creation date", logging.Fields{"from": from, "to": to, "error": err})
+		return nil, err
+	}
+	return products, nil
+}
+
+func (productRepository *ProductRepository) AttachTags(productId int64, tags []string) error {
+	ctx := context.Background()
+
+	if _, err := productRepository.GetById(productId); err != nil {
+		return err
+	}
+
+	if err := productRepository.attachTags(ctx, productId, tags); err != nil {
+		return err
+	}
+	return productRepository.touch(ctx, productRepository.dbPool, productId)
+}
+
+func (productRepository *ProductRepository) DetachTag(productId int64, tag string) error {
+	ctx := context.Background()
+
+	detachSql := `
+        DELETE FROM product_tags
+        WHERE product_id = $1 AND tag_id = (SELECT id FROM tags WHERE name = $2)
+    `
+
+	commandTag, err := productRepository.dbPool.Exec(ctx, detachSql, productId, tag)
+	if err != nil {
+		logging.Error("error while detaching tag", logging.Fields{"product_id": productId, "tag": tag, "error": err})
+		return fmt.Errorf("error while detaching tag %s from product %d: %w", tag, productId, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("tag %s not found on product %d", tag, productId)
+	}
+	if err := productRepository.touch(ctx, productRepository.dbPool, productId); err != nil {
+		return err
+	}
 
-	log.Printf("✅ Product and images added successfully")
+	logging.Info("tag detached", logging.Fields{"product_id": productId, "tag": tag})
+	return nil
+}
+
+// execer runs a statement on the pool or inside a transaction.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+// touch moves updated_at forward for changes stored outside the products row, such as
+// images and tags, so the change feed picks them up.
+func (productRepository *ProductRepository) touch(ctx context.Context, executor execer, productId int64) error {
+	if _, err := executor.Exec(ctx, `UPDATE products SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, productId); err != nil {
+		return fmt.Errorf("error while updating updated_at of product with id %d: %w", productId, err)
+	}
+	return nil
+}
+
+// attachTags creates missing tags and links them to the product; already linked tags are ignored.
+func (productRepository *ProductRepository) attachTags(ctx context.Context, productId int64, tags []string) error {
+	insertTagSql := `
+        WITH inserted AS (
+            INSERT INTO tags (name) VALUES ($2)
+            ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+            RETURNING id
+        )
+        INSERT INTO product_tags (product_id, tag_id)
+        SELECT $1, id FROM inserted
+        ON CONFLICT DO NOTHING
+    `
+
+	for _, tag := range tags {
+		if _, err := productRepository.dbPool.Exec(ctx, insertTagSql, productId, tag); err != nil {
+			logging.Error("error attaching tag", logging.Fields{"product_id": productId, "tag": tag, "error": err})
+			return fmt.Errorf("failed to attach tag %s: %w", tag, err)
+		}
+	}
 	return nil
 }
 
 func (productRepository *ProductRepository) GetById(productId int64) (domain.Product, error) {
+	return productRepository.getById(productRepository.reader, productId)
+}
+
+func (productRepository *ProductRepository) GetCurrentById(productId int64) (domain.Product, error) {
+	return productRepository.getById(productRepository.primaryReader, productId)
+}
+
+func (productRepository *ProductRepository) getById(querier Querier, productId int64) (domain.Product, error) {
 	ctx := context.Background()
 
-	getByIdSql := `SELECT id, name, price, description, discount, store, category_id FROM products WHERE id = $1`
-	queryRow := productRepository.dbPool.QueryRow(ctx, getByIdSql, productId)
+	getByIdSql := `SELECT ` + productColumns + ` FROM products p WHERE p.id = $1`
+	queryRow := querier.QueryRow(ctx, getByIdSql, productId)
 
-	var product domain.Product
-	scanErr := queryRow.Scan(&product.Id, &product.Name, &product.Price, &product.Description, &product.Discount, &product.Store, &product.CategoryID)
+	product, scanErr := scanProduct(queryRow)
 
 	if errors.Is(scanErr, pgx.ErrNoRows) {
-		return domain.Product{}, fmt.Errorf("product not found with id %d: %w", productId, scanErr)
+		return domain.Product{}, fmt.Errorf("%w with id %d", domain.ErrProductNotFound, productId)
 	}
 
 	if scanErr != nil {
 		return domain.Product{}, fmt.Errorf("error while getting product with id %d: %w", productId, scanErr)
 	}
 
-	imageRows, err := productRepository.dbPool.Query(ctx, `
-        SELECT image_urls FROM product_images
-        WHERE product_id = $1
-        ORDER BY display_order
-    `, productId)
+	products := []domain.Product{product}
+	if err := productRepository.loadImages(ctx, querier, products); err != nil {
+		return domain.Product{}, err
+	}
+	return products[0], nil
+}
+
+func (productRepository *ProductRepository) GetBySlug(slug string) (domain.Product, error) {
+	return productRepository.getBySlug(productRepository.reader, slug)
+}
+
+func (productRepository *ProductRepository) GetCurrentBySlug(slug string) (domain.Product, error) {
+	return productRepository.getBySlug(productRepository.primaryReader, slug)
+}
+
+func (productRepository *ProductRepository) GetCurrentByIds(productIds []int64) ([]domain.Product, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + productColumns + ` FROM products p WHERE p.id = ANY($1)`
+	productRows, err := productRepository.primaryReader.Query(ctx, query, productIds)
 	if err != nil {
-		return domain.Product{}, fmt.Errorf("error querying images for product %d: %w", productId, err)
+		logging.Error("error while querying products by id", logging.Fields{"error": err})
+		return nil, fmt.Errorf("error while querying products by id: %w", err)
 	}
+	defer productRows.Close()
 
-	var imageUrls []string
-	for imageRows.Next() {
-		var url string
-		if err := imageRows.Scan(&url); err != nil {
-			imageRows.Close()
-			return domain.Product{}, fmt.Errorf("error scanning image url for product %d: %w", productId, err)
-		}
-		imageUrls = append(imageUrls, url)
+	return productRepository.extractProductFromRows(ctx, productRepository.primaryReader, productRows)
+}
+
+func (productRepository *ProductRepository) getBySlug(querier Querier, slug string) (domain.Product, error) {
+	ctx := context.Background()
+
+	getBySlugSql := `SELECT ` + productColumns + ` FROM products p WHERE p.slug = $1`
+	product, scanErr := scanProduct(querier.QueryRow(ctx, getBySlugSql, slug))
+
+	if errors.Is(scanErr, pgx.ErrNoRows) {
+		return domain.Product{}, fmt.Errorf("%w with slug %s", domain.ErrProductNotFound, slug)
 	}
-	imageRows.Close()
 
-	product.ImageUrls = imageUrls
-	return product, nil
+	if scanErr != nil {
+		return domain.Product{}, fmt.Errorf("error while getting product with slug %s: %w", slug, scanErr)
+	}
+
+	products := []domain.Product{product}
+	if err := productRepository.loadImages(ctx, querier, products); err != nil {
+		return domain.Product{}, err
+	}
+	return products[0], nil
+}
+
+func (productRepository *ProductRepository) GetByIdWithCategory(productId int64) (domain.ProductWithCategory, error) {
+	ctx := context.Background()
+
+	getByIdSql := `SELECT ` + productColumns + `, c.id, c.name, c.slug, c.description, c.parent_id, c.created_by, c.created_at, c.updated_at
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id AND c.deleted_at IS NULL
+		WHERE p.id = $1`
+
+	var product domain.Product
+	var categoryId *int64
+	var categoryName, categorySlug, categoryDescription *string
+	var categoryParentId, categoryCreatedBy *int64
+	var categoryCreatedAt, categoryUpdatedAt *time.Time
+	scanTargets := append(productScanTargets(&product), &categoryId, &categoryName, &categorySlug, &categoryDescription,
+		&categoryParentId, &categoryCreatedBy, &categoryCreatedAt, &categoryUpdatedAt)
+
+	scanErr := productRepository.reader.QueryRow(ctx, getByIdSql, productId).Scan(scanTargets...)
+	if errors.Is(scanErr, pgx.ErrNoRows) {
+		return domain.ProductWithCategory{}, fmt.Errorf("%w with id %d", domain.ErrProductNotFound, productId)
+	}
+	if scanErr != nil {
+		return domain.ProductWithCategory{}, fmt.Errorf("error while getting product with id %d: %w", productId, scanErr)
+	}
+
+	products := []domain.Product{product}
+	if err := productRepository.loadImages(ctx, productRepository.reader, products); err != nil {
+		return domain.ProductWithCategory{}, err
+	}
+
+	productWithCategory := domain.ProductWithCategory{Product: products[0]}
+	if categoryId != nil {
+		productWithCategory.Category = &domain.Category{
+			Id:          *categoryId,
+			Name:        *categoryName,
+			Slug:        *categorySlug,
+			Description: stringValue(categoryDescription),
+			ParentId:    categoryParentId,
+			CreatedBy:   categoryCreatedBy,
+			CreatedAt:   *categoryCreatedAt,
+			UpdatedAt:   *categoryUpdatedAt,
+		}
+	}
+	return productWithCategory, nil
 }
 
+// insertDeletionsSql records tombstones for the change feed; it runs in the transaction
+// that deletes the products.
+const insertDeletionsSql = `INSERT INTO product_deletions (product_id) SELECT unnest($1::bigint[]) ON CONFLICT (product_id) DO NOTHING`
+
+// DeleteById deletes the product's images and then the product in one transaction, so
+// no image rows are left behind even where the foreign key does not cascade.
 func (productRepository *ProductRepository) DeleteById(productId int64) error {
 	ctx := context.Background()
-	deleteSql := `DELETE FROM products WHERE id = $1`
-	commandTag, err := productRepository.dbPool.Exec(ctx, deleteSql, productId)
 
+	tx, err := productRepository.dbPool.Begin(ctx)
 	if err != nil {
-		log.Errorf("❌ Error while deleting product with id %d: %v", productId, err)
+		return fmt.Errorf("error while starting delete of product with id %d: %w", productId, err)
+	}
+	defer tx.Rollback(ctx)
+
+	imagesTag, err := tx.Exec(ctx, `DELETE FROM product_images WHERE product_id = $1`, productId)
+	if err != nil {
+		logging.Error("error while deleting product images", logging.Fields{"product_id": productId, "error": err})
+		return fmt.Errorf("error while deleting images of product with id %d: %w", productId, err)
+	}
+
+	commandTag, err := tx.Exec(ctx, `DELETE FROM products WHERE id = $1`, productId)
+	if err != nil {
+		logging.Error("error while deleting product", logging.Fields{"product_id": productId, "error": err})
 		return fmt.Errorf("error while deleting product with id %d: %w", productId, err)
 	}
 
 	if commandTag.RowsAffected() == 0 {
-		log.Warnf("⚠️ Product with id %d not found for deletion", productId)
+		logging.Warn("product not found for deletion", logging.Fields{"product_id": productId})
 		return fmt.Errorf("product with id %d not found", productId)
 	}
 
-	log.Infof("✅ Product deleted with id %d", productId)
+	if _, err := tx.Exec(ctx, insertDeletionsSql, []int64{productId}); err != nil {
+		return fmt.Errorf("error while recording deletion of product with id %d: %w", productId, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error while committing delete of product with id %d: %w", productId, err)
+	}
+
+	logging.Info("product deleted", logging.Fields{"product_id": productId, "images_deleted": imagesTag.RowsAffected()})
 	return nil
 }
 
+// DeleteByIds deletes the given products in one transaction and reports which ids did not exist.
+// With dryRun the deletes run the same way but the transaction is rolled back, so the
+// report matches what a real run would do without removing anything.
+func (productRepository *ProductRepository) DeleteByIds(productIds []int64, dryRun bool) (int64, []int64, error) {
+	ctx := context.Background()
+
+	tx, err := productRepository.dbPool.Begin(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error while starting batch delete: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Remove the images explicitly rather than relying on the foreign key to cascade
+	if _, err := tx.Exec(ctx, `DELETE FROM product_images WHERE product_id = ANY($1)`, productIds); err != nil {
+		logging.Error("error while batch deleting product images", logging.Fields{"product_ids": productIds, "error": err})
+		return 0, nil, fmt.Errorf("error while deleting product images: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `DELETE FROM products WHERE id = ANY($1) RETURNING id`, productIds)
+	if err != nil {
+		logging.Error("error while batch deleting products", logging.Fields{"product_ids": productIds, "error": err})
+		return 0, nil, fmt.Errorf("error while deleting products: %w", err)
+	}
+
+	deletedIds := make(map[int64]bool)
+	var deleted []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, nil, fmt.Errorf("error scanning deleted product id: %w", err)
+		}
+		deletedIds[id] = true
+		deleted = append(deleted, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, nil, fmt.Errorf("error while deleting products: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, insertDeletionsSql, deleted); err != nil {
+		return 0, nil, fmt.Errorf("error while recording deleted products: %w", err)
+	}
+
+	if !dryRun {
+		if err := tx.Commit(ctx); err != nil {
+			return 0, nil, fmt.Errorf("error while committing batch delete: %w", err)
+		}
+	}
+
+	notFoundIds := []int64{}
+	for _, id := range productIds {
+		if !deletedIds[id] {
+			notFoundIds = append(notFoundIds, id)
+		}
+	}
+
+	logging.Info("products deleted in batch", logging.Fields{"deleted": len(deletedIds), "not_found": len(notFoundIds), "dry_run": dryRun})
+	return int64(len(deletedIds)), notFoundIds, nil
+}
+
 func (productRepository *ProductRepository) DeleteAllProducts() error {
 	ctx := context.Background()
-	deleteAllProductsSql := `DELETE FROM products`
+	// Record a tombstone per product in the same statement so the change feed sees every delete
+	deleteAllProductsSql := `
+        WITH deleted AS (DELETE FROM products RETURNING id)
+        INSERT INTO product_deletions (product_id) SELECT id FROM deleted
+        ON CONFLICT (product_id) DO NOTHING
+    `
 
 	commandTag, err := productRepository.dbPool.Exec(ctx, deleteAllProductsSql)
 
 	if err != nil {
-		log.Errorf("❌ Error while deleting all products: %v", err)
+		logging.Error("error while deleting all products", logging.Fields{"error": err})
 		return fmt.Errorf("error while deleting all products: %w", err)
 	}
 
 	if commandTag.RowsAffected() == 0 {
-		log.Warn("⚠️ No products found for deletion")
+		logging.Warn("no products found for deletion", nil)
 		return fmt.Errorf("products not found for deletion")
 	}
 
-	log.Infof("✅ All products deleted successfully (%d rows affected)", commandTag.RowsAffected())
+	logging.Info("all products deleted", logging.Fields{"rows_affected": commandTag.RowsAffected()})
 	return nil
 }
 
-func (productRepository *ProductRepository) UpdatePrice(productId int64, newPrice float32) error {
+// UpdatePrice changes the price and records the change in price_history in the same
+// transaction. changedBy is the id of the user making the change.
+func (productRepository *ProductRepository) UpdatePrice(productId int64, newPrice decimal.Decimal, version int, changedBy int64) error {
 	ctx := context.Background()
 
-	updateSql := `UPDATE products SET price = $1 WHERE id = $2`
-
-	_, err := productRepository.dbPool.Exec(ctx, updateSql, newPrice, productId)
+	tx, err := productRepository.dbPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting price update: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
+	// Lock the row while reading the old price so the recorded change matches what was replaced
+	var oldPrice decimal.Decimal
+	err = tx.QueryRow(ctx, `SELECT price FROM products WHERE id = $1 AND version = $2 FOR UPDATE`, productId, version).Scan(&oldPrice)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return productRepository.updateMissError(ctx, productId)
+	}
 	if err != nil {
-		log.Errorf("❌ Error while updating product price for id %d: %v", productId, err)
+		logging.Error("error while updating product price", logging.Fields{"product_id": productId, "error": err})
+		return fmt.Errorf("error while updating product price with id %d: %w", productId, err)
+	}
+
+	updateSql := `UPDATE products SET price = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	if _, err := tx.Exec(ctx, updateSql, newPrice, productId); err != nil {
+		logging.Error("error while updating product price", logging.Fields{"product_id": productId, "error": err})
 		return fmt.Errorf("error while updating product price with id %d: %w", productId, err)
 	}
-	log.Infof("✅ Product %d price updated to %v", productId, newPrice)
+
+	insertHistorySql := `
+        INSERT INTO price_history (product_id, old_price, new_price, changed_by)
+        VALUES ($1, $2, $3, NULLIF($4::bigint, 0))
+    `
+	if _, err := tx.Exec(ctx, insertHistorySql, productId, oldPrice, newPrice, changedBy); err != nil {
+		logging.Error("error while recording price change", logging.Fields{"product_id": productId, "error": err})
+		return fmt.Errorf("error while recording price change of product with id %d: %w", productId, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error while committing price update: %w", err)
+	}
+	logging.Info("product price updated", logging.Fields{"product_id": productId, "old_price": oldPrice.String(), "price": newPrice.String()})
 	return nil
 }
 
-func (productRepository *ProductRepository) GetProductsByCategoryId(categoryId int64) ([]domain.Product, error) {
+// GetPriceHistory returns the recorded price changes of a product, most recent first.
+func (productRepository *ProductRepository) GetPriceHistory(productId int64) ([]domain.PriceChange, error) {
 	ctx := context.Background()
 
-	query := `SELECT id, name, price, description, discount, store, category_id FROM products WHERE category_id = $1`
+	historySql := `
+        SELECT id, product_id, old_price, new_price, changed_at, changed_by
+        FROM price_history
+        WHERE product_id = $1
+        ORDER BY changed_at DESC, id DESC
+    `
 
-	rows, err := productRepository.dbPool.Query(ctx, query, categoryId)
+	rows, err := productRepository.reader.Query(ctx, historySql, productId)
 	if err != nil {
-		log.Errorf("❌ Error while getting products by category id %d: %v", categoryId, err)
-		return nil, fmt.Errorf("error while getting products by category id %d: %w", categoryId, err)
+		logging.Error("error while querying price history", logging.Fields{"product_id": productId, "error": err})
+		return nil, fmt.Errorf("error while querying price history of product with id %d: %w", productId, err)
 	}
 	defer rows.Close()
 
-	var products []domain.Product
-
+	history := []domain.PriceChange{}
 	for rows.Next() {
-		var p domain.Product
-		err := rows.Scan(&p.Id, &p.Name, &p.Price, &p.Description, &p.Discount, &p.Store, &p.CategoryID)
-		if err != nil {
-			log.Errorf("❌ Error scanning product row: %v", err)
-			return nil, fmt.Errorf("error scanning product: %w", err)
+		var change domain.PriceChange
+		if err := rows.Scan(&change.Id, &change.ProductId, &change.OldPrice, &change.NewPrice, &change.ChangedAt, &change.ChangedBy); err != nil {
+			return nil, fmt.Errorf("error scanning price change: %w", err)
 		}
+		history = append(history, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while querying price history of product with id %d: %w", productId, err)
+	}
+	return history, nil
+}
+
+// UpdateProductPartial updates the fields set in patch. A price change is recorded in
+// price_history in the same transaction, like UpdatePrice does.
+func (productRepository *ProductRepository) UpdateProductPartial(productId int64, patch model.ProductPatch, changedBy int64) error {
+	ctx := context.Background()
+
+	var setClauses []string
+	var args []interface{}
+	addColumn := func(column string, value interface{}) {
+		args = append(args, value)
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if patch.Name != nil {
+		addColumn("name", *patch.Name)
+	}
+	if patch.Slug != nil {
+		addColumn("slug", *patch.Slug)
+	}
+	if patch.Price != nil {
+		addColumn("price", *patch.Price)
+	}
+	if patch.Description != nil {
+		addColumn("description", *patch.Description)
+	}
+	if patch.Discount != nil {
+		addColumn("discount", *patch.Discount)
+	}
+	if patch.Store != nil {
+		addColumn("store", *patch.Store)
+	}
+	if patch.CategoryID != nil {
+		// Without an Uncategorized category, 0 is stored as NULL to satisfy the foreign key
+		args = append(args, *patch.CategoryID)
+		setClauses = append(setClauses, fmt.Sprintf("category_id = NULLIF($%d::bigint, 0)", len(args)))
+	}
+	if patch.Stock != nil {
+		addColumn("stock", *patch.Stock)
+	}
+
+	if len(setClauses) == 0 {
+		return fmt.Errorf("no fields provided to update product with id %d", productId)
+	}
+
+	tx, err := productRepository.dbPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while starting product update: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-		// Her ürün için resimleri ayrı çek
-		imageRows, err := productRepository.dbPool.Query(ctx, `
-			SELECT image_urls FROM product_images WHERE product_id = $1 ORDER BY display_order
-		`, p.Id)
+	// Lock the row while reading the old price so the recorded change matches what was replaced
+	var oldPrice decimal.Decimal
+	if patch.Price != nil {
+		err = tx.QueryRow(ctx, `SELECT price FROM products WHERE id = $1 AND version = $2 FOR UPDATE`, productId, patch.Version).Scan(&oldPrice)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return productRepository.updateMissError(ctx, productId)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("error querying images for product %d: %w", p.Id, err)
+			logging.Error("error while patching product", logging.Fields{"product_id": productId, "error": err})
+			return fmt.Errorf("error while updating product with id %d: %w", productId, err)
 		}
+	}
 
-		var imageUrls []string
-		for imageRows.Next() {
-			var url string
-			if err := imageRows.Scan(&url); err != nil {
-				imageRows.Close()
-				return nil, fmt.Errorf("error scanning image url for product %d: %w", p.Id, err)
-			}
-			imageUrls = append(imageUrls, url)
+	args = append(args, productId, patch.Version)
+	updateSql := fmt.Sprintf("UPDATE products SET %s, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $%d AND version = $%d",
+		strings.Join(setClauses, ", "), len(args)-1, len(args))
+
+	commandTag, err := tx.Exec(ctx, updateSql, args...)
+	if err != nil {
+		logging.Error("error while patching product", logging.Fields{"product_id": productId, "error": err})
+		return fmt.Errorf("error while updating product with id %d: %w", productId, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return productRepository.updateMissError(ctx, productId)
+	}
+
+	if patch.Price != nil {
+		insertHistorySql := `
+            INSERT INTO price_history (product_id, old_price, new_price, changed_by)
+            VALUES ($1, $2, $3, NULLIF($4::bigint, 0))
+        `
+		if _, err := tx.Exec(ctx, insertHistorySql, productId, oldPrice, *patch.Price, changedBy); err != nil {
+			logging.Error("error while recording price change", logging.Fields{"product_id": productId, "error": err})
+			return fmt.Errorf("error while recording price change of product with id %d: %w", productId, err)
 		}
-		imageRows.Close()
+	}
 
-		p.ImageUrls = imageUrls
-		products = append(products, p)
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error while committing product update: %w", err)
 	}
 
-	log.Infof("✅ %d products retrieved for category id %d", len(products), categoryId)
-	return products, nil
+	logging.Info("product partially updated", logging.Fields{"product_id": productId, "fields": len(setClauses)})
+	return nil
+}
+
+// UpdateProductCategory moves a product to another category and bumps its version. A
+// categoryId of 0 leaves the product without a category.
+func (productRepository *ProductRepository) UpdateProductCategory(productId int64, categoryId int64) error {
+	ctx := context.Background()
+
+	updateSql := `UPDATE products SET category_id = NULLIF($1::bigint, 0), version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	commandTag, err := productRepository.dbPool.Exec(ctx, updateSql, categoryId, productId)
+	if err != nil {
+		logging.Error("error while updating product category", logging.Fields{"product_id": productId, "category_id": categoryId, "error": err})
+		return fmt.Errorf("error while updating category of product with id %d: %w", productId, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		logging.Warn("product not found for category update", logging.Fields{"product_id": productId})
+		return fmt.Errorf("%w with id %d", domain.ErrProductNotFound, productId)
+	}
+	logging.Info("product category updated", logging.Fields{"product_id": productId, "category_id": categoryId})
+	return nil
+}
+
+func (productRepository *ProductRepository) UpdateProductStatus(productId int64, status string) error {
+	ctx := context.Background()
+
+	updateSql := `UPDATE products SET status = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	commandTag, err := productRepository.dbPool.Exec(ctx, updateSql, status, productId)
+	if err != nil {
+		logging.Error("error while updating product status", logging.Fields{"product_id": productId, "status": status, "error": err})
+		return fmt.Errorf("error while updating status of product with id %d: %w", productId, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		logging.Warn("product not found for status update", logging.Fields{"product_id": productId})
+		return fmt.Errorf("%w with id %d", domain.ErrProductNotFound, productId)
+	}
+	logging.Info("product status updated", logging.Fields{"product_id": productId, "status": status})
+	return nil
+}
+
+// SetFeatured flags or unflags a product for the featured listing.
+func (productRepository *ProductRepository) SetFeatured(productId int64, featured bool) error {
+	ctx := context.Background()
+
+	updateSql := `UPDATE products SET is_featured = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	commandTag, err := productRepository.dbPool.Exec(ctx, updateSql, featured, productId)
+	if err != nil {
+		logging.Error("error while updating product featured flag", logging.Fields{"product_id": productId, "featured": featured, "error": err})
+		return fmt.Errorf("error while updating featured flag of product with id %d: %w", productId, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		logging.Warn("product not found for featured update", logging.Fields{"product_id": productId})
+		return fmt.Errorf("%w with id %d", domain.ErrProductNotFound, productId)
+	}
+	logging.Info("product featured flag updated", logging.Fields{"product_id": productId, "featured": featured})
+	return nil
+}
+
+// updateMissError explains why a versioned update touched no rows: the product is
+// either gone or was updated by someone else since the caller read it.
+func (productRepository *ProductRepository) updateMissError(ctx context.Context, productId int64) error {
+	var exists bool
+	err := productRepository.primaryReader.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)`, productId).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("error while checking product with id %d: %w", productId, err)
+	}
+
+	if !exists {
+		logging.Warn("product not found for update", logging.Fields{"product_id": productId})
+		return fmt.Errorf("%w with id %d", domain.ErrProductNotFound, productId)
+	}
+
+	logging.Warn("version conflict while updating product", logging.Fields{"product_id": productId})
+	return fmt.Errorf("%w (id %d)", domain.ErrProductVersionConflict, productId)
+}
+
+func (productRepository *ProductRepository) CategoryExists(categoryId int64) (bool, error) {
+	ctx := context.Background()
+
+	var exists bool
+	// Validates writes, so it must not miss a category created or deleted a moment ago
+	err := productRepository.primaryReader.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1 AND deleted_at IS NULL)`, categoryId).Scan(&exists)
+	if err != nil {
+		logging.Error("error while checking category", logging.Fields{"category_id": categoryId, "error": err})
+		return false, fmt.Errorf("error while checking category with id %d: %w", categoryId, err)
+	}
+	return exists, nil
+}
+
+func (productRepository *ProductRepository) GetRating(productId int64) (domain.ProductRating, error) {
+	ctx := context.Background()
+
+	var rating domain.ProductRating
+	err := productRepository.reader.QueryRow(ctx,
+		`SELECT COALESCE(AVG(rating), 0)::float8, COUNT(*) FROM reviews WHERE product_id = $1`, productId).Scan(&rating.AverageRating, &rating.ReviewCount)
+	if err != nil {
+		logging.Error("error while getting product rating", logging.Fields{"product_id": productId, "error": err})
+		return domain.ProductRating{}, fmt.Errorf("error while getting rating of product with id %d: %w", productId, err)
+	}
+	return rating, nil
 }
 
-func (productRepository *ProductRepository) extractProductFromRows(ctx context.Context, productRows pgx.Rows) ([]domain.Product, error) {
-	var products []domain.Product
+// GetPriceStatsByCategory aggregates the active products of a category priced in
+// currency; prices in different currencies are not comparable.
+func (productRepository *ProductRepository) GetPriceStatsByCategory(categoryId int64, currency string) (domain.PriceStats, error) {
+	ctx := context.Background()
+
+	priceStatsSql := `SELECT COUNT(*), COALESCE(MIN(price), 0), COALESCE(MAX(price), 0), COALESCE(ROUND(AVG(price), 2), 0)
+		FROM products WHERE category_id = $1 AND status = 'active' AND currency = $2`
+
+	priceStats := domain.PriceStats{CategoryId: categoryId, Currency: currency}
+	err := productRepository.reader.QueryRow(ctx, priceStatsSql, categoryId, currency).Scan(
+		&priceStats.ProductCount, &priceStats.MinPrice, &priceStats.MaxPrice, &priceStats.AvgPrice)
+	if err != nil {
+		logging.Error("error while getting price stats", logging.Fields{"category_id": categoryId, "error": err})
+		return domain.PriceStats{}, fmt.Errorf("error while getting price stats for category id %d: %w", categoryId, err)
+	}
+	return priceStats, nil
+}
+
+// CountProductsByCategory counts the active products of a category, the ones its
+// product listing shows, without loading them.
+func (productRepository *ProductRepository) CountProductsByCategory(categoryId int64) (int64, error) {
+	ctx := context.Background()
+
+	var count int64
+	err := productRepository.reader.QueryRow(ctx,
+		`SELECT COUNT(*) FROM products WHERE category_id = $1 AND status = 'active'`, categoryId).Scan(&count)
+	if err != nil {
+		logging.Error("error while counting products", logging.Fields{"category_id": categoryId, "error": err})
+		return 0, fmt.Errorf("error while counting products of category id %d: %w", categoryId, err)
+	}
+	return count, nil
+}
+
+// GetProductStats counts the whole catalog but averages only the prices in currency.
+func (productRepository *ProductRepository) GetProductStats(currency string) (domain.ProductStats, error) {
+	ctx := context.Background()
+
+	productStatsSql := `SELECT COUNT(*), COUNT(DISTINCT store), COALESCE(ROUND(AVG(price) FILTER (WHERE currency = $1), 2), 0), COUNT(*) FILTER (WHERE discount > 0)
+		FROM products`
+
+	productStats := domain.ProductStats{Currency: currency}
+	err := productRepository.reader.QueryRow(ctx, productStatsSql, currency).Scan(
+		&productStats.TotalProducts, &productStats.DistinctStores, &productStats.AvgPrice, &productStats.DiscountedProducts)
+	if err != nil {
+		logging.Error("error while getting product stats", logging.Fields{"error": err})
+		return domain.ProductStats{}, fmt.Errorf("error while getting product stats: %w", err)
+	}
+	return productStats, nil
+}
+
+func (productRepository *ProductRepository) GetProductsByCategoryId(categoryId int64, priceRange model.PriceRange, pageRequest model.PageRequest) ([]domain.Product, int64, error) {
+	products, total, err := productRepository.Find(model.ProductFilter{
+		CategoryId:  categoryId,
+		Currency:    priceRange.Currency,
+		MinPrice:    priceRange.Min,
+		MaxPrice:    priceRange.Max,
+		PageRequest: pageRequest,
+	})
+	if err != nil {
+		logging.Error("error while getting products by category", logging.Fields{"category_id": categoryId, "error": err})
+		return nil, 0, fmt.Errorf("error while getting products by category id %d: %w", categoryId, err)
+	}
+
+	logging.Info("products retrieved for category", logging.Fields{"category_id": categoryId, "count": len(products), "total": total})
+	return products, total, nil
+}
+
+// extractProductFromRows scans every product row and then hydrates image urls with a
+// single query, avoiding one image query per product.
+func (productRepository *ProductRepository) extractProductFromRows(ctx context.Context, querier Querier, productRows pgx.Rows) ([]domain.Product, error) {
+	// Start from an empty slice so a query without rows serializes as [] rather than null
+	products := []domain.Product{}
 
 	for productRows.Next() {
-		var p domain.Product
-		// Scan işleminden p.UserID kaldırıldı
-		err := productRows.Scan(&p.Id, &p.Name, &p.Price, &p.Description, &p.Discount, &p.Store, &p.CategoryID)
+		p, err := scanProduct(productRows)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning product row: %w", err)
 		}
+		products = append(products, p)
+	}
 
-		// Görselleri çekme kısmı aynı kalır
-		imageRows, err := productRepository.dbPool.Query(ctx, `
-            SELECT image_urls FROM product_images
-            WHERE product_id = $1
-            ORDER BY display_order
-        `, p.Id)
-		if err != nil {
-			return nil, fmt.Errorf("error querying images for product %d: %w", p.Id, err)
+	if err := productRows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+	productRows.Close()
+
+	if err := productRepository.loadImages(ctx, querier, products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// loadImages fills ImageUrls and Images for all given products, in display order, using one query.
+func (productRepository *ProductRepository) loadImages(ctx context.Context, querier Querier, products []domain.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	productIds := make([]int64, len(products))
+	indexById := make(map[int64]int, len(products))
+	for i, p := range products {
+		productIds[i] = p.Id
+		indexById[p.Id] = i
+	}
+
+	imageRows, err := querier.Query(ctx, `
+        SELECT product_id, `+imageColumns+` FROM product_images
+        WHERE product_id = ANY($1)
+        ORDER BY product_id, display_order, id
+    `, productIds)
+	if err != nil {
+		return fmt.Errorf("error querying images for products: %w", err)
+	}
+	defer imageRows.Close()
+
+	for imageRows.Next() {
+		var productId int64
+		var image domain.ProductImage
+		if err := imageRows.Scan(append([]interface{}{&productId}, imageScanTargets(&image)...)...); err != nil {
+			return fmt.Errorf("error scanning image url: %w", err)
 		}
+		i := indexById[productId]
+		products[i].ImageUrls = append(products[i].ImageUrls, image.Url)
+		products[i].Images = append(products[i].Images, image)
+	}
 
-		var imageUrls []string
-		for imageRows.Next() {
-			var url string
-			if err := imageRows.Scan(&url); err != nil {
-				imageRows.Close() // Hata durumunda row'ları kapat
-				return nil, fmt.Errorf("error scanning image url: %w", err)
-			}
-			imageUrls = append(imageUrls, url)
+	if err := imageRows.Err(); err != nil {
+		return fmt.Errorf("error during image row iteration: %w", err)
+	}
+	return nil
+}
+
+// imageColumns is the select list of product images; keep it in sync with imageScanTargets.
+const imageColumns = `id, image_urls, COALESCE(is_main_image, false), display_order, width, height, mime_type`
+
+func imageScanTargets(image *domain.ProductImage) []interface{} {
+	return []interface{}{&image.Id, &image.Url, &image.IsMain, &image.DisplayOrder, &image.Width, &image.Height, &image.MimeType}
+}
+
+// GetProductImages lists the images of a product in display order. A product without
+// images yields an empty slice; a missing product yields ErrProductNotFound.
+func (productRepository *ProductRepository) GetProductImages(productId int64) ([]domain.ProductImage, error) {
+	ctx := context.Background()
+
+	var exists bool
+	err := productRepository.reader.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)`, productId).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("error while checking product with id %d: %w", productId, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w with id %d", domain.ErrProductNotFound, productId)
+	}
+
+	imageRows, err := productRepository.reader.Query(ctx,
+		`SELECT `+imageColumns+` FROM product_images WHERE product_id = $1 ORDER BY display_order, id`, productId)
+	if err != nil {
+		logging.Error("error while querying product images", logging.Fields{"product_id": productId, "error": err})
+		return nil, fmt.Errorf("error while querying images of product with id %d: %w", productId, err)
+	}
+	defer imageRows.Close()
+
+	images := []domain.ProductImage{}
+	for imageRows.Next() {
+		var image domain.ProductImage
+		if err := imageRows.Scan(imageScanTargets(&image)...); err != nil {
+			return nil, fmt.Errorf("error while scanning product image: %w", err)
 		}
-		imageRows.Close() // Tüm row'lar okunduktan sonra kapat
+		images = append(images, image)
+	}
+	if err := imageRows.Err(); err != nil {
+		return nil, fmt.Errorf("error during image row iteration: %w", err)
+	}
+	return images, nil
+}
 
-		p.ImageUrls = imageUrls
-		products = append(products, p)
+// normalizeImageOrderSql renumbers display_order per product in the current order, id
+// breaking ties, and bumps updated_at of the products it changed so the change feed
+// picks them up. $1 limits it to one product; 0 covers all of them.
+const normalizeImageOrderSql = `
+	WITH ordered AS (
+		SELECT id, ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY display_order NULLS LAST, id) - 1 AS position
+		FROM product_images
+		WHERE $1::bigint = 0 OR product_id = $1
+	), renumbered AS (
+		UPDATE product_images pi SET display_order = ordered.position
+		FROM ordered
+		WHERE pi.id = ordered.id AND pi.display_order IS DISTINCT FROM ordered.position
+		RETURNING pi.product_id
+	)
+	UPDATE products SET updated_at = CURRENT_TIMESTAMP WHERE id IN (SELECT product_id FROM renumbered)`
+
+func (productRepository *ProductRepository) NormalizeImageOrder(productId int64) error {
+	ctx := context.Background()
+
+	var exists bool
+	err := productRepository.dbPool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)`, productId).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("error while checking product with id %d: %w", productId, err)
+	}
+	if !exists {
+		return fmt.Errorf("%w with id %d", domain.ErrProductNotFound, productId)
 	}
 
-	if err := productRows.Err(); err != nil {
-		return nil, fmt.Errorf("error during row iteration: %w", err)
+	commandTag, err := productRepository.dbPool.Exec(ctx, normalizeImageOrderSql, productId)
+	if err != nil {
+		logging.Error("error while normalizing image order", logging.Fields{"product_id": productId, "error": err})
+		return fmt.Errorf("error while normalizing image order of product with id %d: %w", productId, err)
 	}
+	logging.Info("image order normalized", logging.Fields{"product_id": productId, "changed": commandTag.RowsAffected() > 0})
+	return nil
+}
 
-	return products, nil
+func (productRepository *ProductRepository) NormalizeAllImageOrders() (int64, error) {
+	ctx := context.Background()
+
+	commandTag, err := productRepository.dbPool.Exec(ctx, normalizeImageOrderSql, 0)
+	if err != nil {
+		logging.Error("error while normalizing image order", logging.Fields{"error": err})
+		return 0, fmt.Errorf("error while normalizing image order: %w", err)
+	}
+	logging.Info("image order normalized for all products", logging.Fields{"count": commandTag.RowsAffected()})
+	return commandTag.RowsAffected(), nil
+}
+
+func scanProduct(row pgx.Row) (domain.Product, error) {
+	var p domain.Product
+	err := row.Scan(productScanTargets(&p)...)
+	return p, err
+}
+
+// productScanTargets returns the destinations for productColumns, in select order.
+func productScanTargets(p *domain.Product) []interface{} {
+	return []interface{}{&p.Id, &p.Name, &p.Slug, &p.Price, &p.Description, &p.Discount, &p.Store, &p.Currency, &p.CategoryID, &p.Version, &p.Status, &p.IsFeatured, &p.Stock,
+		&p.Tags}
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// ChangeFeedLag holds recent changes back from the change feed. A change is stamped with
+// the start time of its transaction, so one that commits late can carry a time older
+// than a cursor already handed out; waiting this long lets such transactions commit first.
+const ChangeFeedLag = 5 * time.Second
+
+// GetProductChanges reads from the primary: on a lagging replica the feed could move a
+// client's cursor past changes it has not seen yet. Changes younger than ChangeFeedLag
+// are left for a later request.
+func (productRepository *ProductRepository) GetProductChanges(after model.ChangeCursor, limit int) ([]domain.ProductChange, error) {
+	ctx := context.Background()
+
+	changesSql := `
+        SELECT id, updated_at, false FROM products
+        WHERE (updated_at, id) > ($1, $2) AND updated_at < LOCALTIMESTAMP - make_interval(secs => $4)
+        UNION ALL
+        SELECT product_id, deleted_at, true FROM product_deletions
+        WHERE (deleted_at, product_id) > ($1, $2) AND deleted_at < LOCALTIMESTAMP - make_interval(secs => $4)
+        ORDER BY 2, 1
+        LIMIT $3
+    `
+	rows, err := productRepository.dbPool.Query(ctx, changesSql, after.ChangedAt, after.ProductId, limit, ChangeFeedLag.Seconds())
+	if err != nil {
+		logging.Error("error while querying product changes", logging.Fields{"error": err})
+		return nil, fmt.Errorf("error while querying product changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []domain.ProductChange{}
+	for rows.Next() {
+		var change domain.ProductChange
+		if err := rows.Scan(&change.ProductId, &change.ChangedAt, &change.Deleted); err != nil {
+			return nil, fmt.Errorf("error while scanning product change: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during product change iteration: %w", err)
+	}
+	return changes, nil
 }
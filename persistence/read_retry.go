@@ -0,0 +1,104 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"product-app/common/logging"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// Querier is the read side of a connection pool. *pgxpool.Pool satisfies it.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// RetryPolicy controls how often a read is retried after a transient error.
+// Retries is the number of extra attempts; each waits Backoff times the attempt number.
+type RetryPolicy struct {
+	Retries int
+	Backoff time.Duration
+}
+
+var DefaultReadRetryPolicy = RetryPolicy{Retries: 2, Backoff: 100 * time.Millisecond}
+
+// NewRetryingReader wraps querier so that reads failing with a transient error, such
+// as a refused connection or an administrator shutdown during failover, are retried.
+// Only use it for reads: a retried write could be applied twice, which is why the
+// repositories keep issuing writes on the pool itself.
+func NewRetryingReader(querier Querier, policy RetryPolicy) Querier {
+	return &retryingReader{querier: querier, policy: policy}
+}
+
+type retryingReader struct {
+	querier Querier
+	policy  RetryPolicy
+}
+
+func (reader *retryingReader) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	var rows pgx.Rows
+	err := reader.retry(ctx, func() error {
+		var queryErr error
+		rows, queryErr = reader.querier.Query(ctx, sql, args...)
+		return queryErr
+	})
+	return rows, err
+}
+
+// QueryRow defers the query to Scan, which is where pgx reports its errors.
+func (reader *retryingReader) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return &retryingRow{reader: reader, ctx: ctx, sql: sql, args: args}
+}
+
+func (reader *retryingReader) retry(ctx context.Context, operation func() error) error {
+	err := operation()
+	for attempt := 1; attempt <= reader.policy.Retries && isTransientError(err); attempt++ {
+		logging.Warn("transient database error, retrying read", logging.Fields{"attempt": attempt, "retries": reader.policy.Retries, "error": err})
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(reader.policy.Backoff * time.Duration(attempt)):
+		}
+		err = operation()
+	}
+	return err
+}
+
+type retryingRow struct {
+	reader *retryingReader
+	ctx    context.Context
+	sql    string
+	args   []interface{}
+}
+
+func (row *retryingRow) Scan(dest ...interface{}) error {
+	return row.reader.retry(row.ctx, func() error {
+		return row.reader.querier.QueryRow(row.ctx, row.sql, row.args...).Scan(dest...)
+	})
+}
+
+// isTransientError reports errors that are expected to go away on their own: the
+// server refusing or dropping connections, or shutting down for a failover.
+func isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "57P01", "57P02", "57P03": // admin_shutdown, crash_shutdown, cannot_connect_now
+			return true
+		}
+		// Class 08 is "connection exception"
+		return strings.HasPrefix(pgErr.Code, "08")
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	return pgconn.SafeToRetry(err)
+}
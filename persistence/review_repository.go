@@ -0,0 +1,100 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"product-app/common/logging"
+	"product-app/domain"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type IReviewRepository interface {
+	AddReview(review domain.Review) (int64, error)
+	GetReviewsByProduct(productId int64) ([]domain.Review, error)
+	GetAverageRating(productId int64) (float64, error)
+}
+
+type ReviewRepository struct {
+	dbPool *pgxpool.Pool
+	reader Querier
+}
+
+func NewReviewRepository(pools Pools, readRetry RetryPolicy) IReviewRepository {
+	return &ReviewRepository{
+		dbPool: pools.Primary,
+		reader: NewRetryingReader(pools.Read, readRetry),
+	}
+}
+
+// AddReview inserts a review, or updates the existing one if the user already reviewed the product.
+func (reviewRepository *ReviewRepository) AddReview(review domain.Review) (int64, error) {
+	ctx := context.Background()
+
+	upsertReviewSQL := `
+		INSERT INTO reviews (product_id, user_id, rating, comment, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (product_id, user_id)
+		DO UPDATE SET rating = EXCLUDED.rating, comment = EXCLUDED.comment, created_at = EXCLUDED.created_at
+		RETURNING id;
+	`
+
+	var reviewId int64
+	err := reviewRepository.dbPool.QueryRow(ctx, upsertReviewSQL,
+		review.ProductId, review.UserId, review.Rating, review.Comment, review.CreatedAt).Scan(&reviewId)
+
+	if err != nil {
+		logging.Error("error saving review", logging.Fields{"product_id": review.ProductId, "error": err})
+		return 0, fmt.Errorf("failed to save review: %w", err)
+	}
+
+	logging.Info("review saved", logging.Fields{"review_id": reviewId, "product_id": review.ProductId})
+	return reviewId, nil
+}
+
+func (reviewRepository *ReviewRepository) GetReviewsByProduct(productId int64) ([]domain.Review, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT id, product_id, user_id, rating, comment, created_at
+		FROM reviews
+		WHERE product_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := reviewRepository.reader.Query(ctx, query, productId)
+	if err != nil {
+		logging.Error("error while getting reviews", logging.Fields{"product_id": productId, "error": err})
+		return nil, fmt.Errorf("error while getting reviews for product %d: %w", productId, err)
+	}
+	defer rows.Close()
+
+	reviews := []domain.Review{}
+	for rows.Next() {
+		var r domain.Review
+		if err := rows.Scan(&r.Id, &r.ProductId, &r.UserId, &r.Rating, &r.Comment, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning review: %w", err)
+		}
+		reviews = append(reviews, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during review row iteration: %w", err)
+	}
+
+	return reviews, nil
+}
+
+func (reviewRepository *ReviewRepository) GetAverageRating(productId int64) (float64, error) {
+	ctx := context.Background()
+
+	var averageRating float64
+	err := reviewRepository.reader.QueryRow(ctx,
+		`SELECT COALESCE(AVG(rating), 0)::float8 FROM reviews WHERE product_id = $1`, productId).Scan(&averageRating)
+
+	if err != nil {
+		return 0, fmt.Errorf("error while getting average rating for product %d: %w", productId, err)
+	}
+
+	return averageRating, nil
+}
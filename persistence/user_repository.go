@@ -4,43 +4,56 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"product-app/common/logging"
 	"product-app/domain"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
-	"github.com/labstack/gommon/log"
 )
 
+// IUserRepository stores users. Lookups return an error when no user matches;
+// emails are stored lowercased and compared case-insensitively.
 type IUserRepository interface {
 	GetById(userId int64) (domain.User, error)
 	GetByUsername(username string) (domain.User, error)
 	GetByEmail(email string) (domain.User, error)
-	AddUser(user domain.User) error
+	// GetByUsernameOrEmail matches the exact username or the email, preferring the username
+	GetByUsernameOrEmail(identifier string) (domain.User, error)
+	ExistsByUsername(username string) (bool, error)
+	ExistsByEmail(email string) (bool, error)
+	// AddUser inserts the user with an already hashed password
+	AddUser(user domain.User) (int64, error)
+	// UpdateUser changes the profile fields; the password and role are left untouched
 	UpdateUser(user domain.User) error
+	UpdatePassword(userId int64, hashedPassword string) error
+	// TouchLastLogin sets last_login_at to the current time
+	TouchLastLogin(userId int64) error
 	DeleteById(userId int64) error
 }
 
 type UserRepository struct {
 	dbPool *pgxpool.Pool
+	reader Querier
 }
 
-func NewUserRepository(dbPool *pgxpool.Pool) IUserRepository {
+func NewUserRepository(dbPool *pgxpool.Pool, readRetry RetryPolicy) IUserRepository {
 	return &UserRepository{
 		dbPool: dbPool,
+		reader: NewRetryingReader(dbPool, readRetry),
 	}
 }
 
 func (userRepository *UserRepository) GetById(userId int64) (domain.User, error) {
 	ctx := context.Background()
 
-	getByIdSql := `SELECT id, username, email, password, first_name, last_name, created_at, updated_at FROM users WHERE id = $1`
-	queryRow := userRepository.dbPool.QueryRow(ctx, getByIdSql, userId)
+	getByIdSql := `SELECT id, username, email, password, first_name, last_name, role, created_at, updated_at, last_login_at FROM users WHERE id = $1`
+	queryRow := userRepository.reader.QueryRow(ctx, getByIdSql, userId)
 
 	var user domain.User
-	scanErr := queryRow.Scan(&user.Id, &user.Username, &user.Email, &user.Password, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt)
+	scanErr := queryRow.Scan(&user.Id, &user.Username, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName, &user.Role, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt)
 
 	if errors.Is(scanErr, pgx.ErrNoRows) {
-		return domain.User{}, fmt.Errorf("user not found with id %d: %w", userId, scanErr)
+		return domain.User{}, fmt.Errorf("%w with id %d", domain.ErrUserNotFound, userId)
 	}
 
 	if scanErr != nil {
@@ -53,11 +66,11 @@ func (userRepository *UserRepository) GetById(userId int64) (domain.User, error)
 func (userRepository *UserRepository) GetByUsername(username string) (domain.User, error) {
 	ctx := context.Background()
 
-	getByUsernameSql := `SELECT id, username, email, password, first_name, last_name, created_at, updated_at FROM users WHERE username = $1`
-	queryRow := userRepository.dbPool.QueryRow(ctx, getByUsernameSql, username)
+	getByUsernameSql := `SELECT id, username, email, password, first_name, last_name, role, created_at, updated_at, last_login_at FROM users WHERE username = $1`
+	queryRow := userRepository.reader.QueryRow(ctx, getByUsernameSql, username)
 
 	var user domain.User
-	scanErr := queryRow.Scan(&user.Id, &user.Username, &user.Email, &user.Password, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt)
+	scanErr := queryRow.Scan(&user.Id, &user.Username, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName, &user.Role, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt)
 
 	if errors.Is(scanErr, pgx.ErrNoRows) {
 		return domain.User{}, fmt.Errorf("user not found with username %s: %w", username, scanErr)
@@ -73,11 +86,11 @@ func (userRepository *UserRepository) GetByUsername(username string) (domain.Use
 func (userRepository *UserRepository) GetByEmail(email string) (domain.User, error) {
 	ctx := context.Background()
 
-	getByEmailSql := `SELECT id, username, email, password, first_name, last_name, created_at, updated_at FROM users WHERE email = $1`
-	queryRow := userRepository.dbPool.QueryRow(ctx, getByEmailSql, email)
+	getByEmailSql := `SELECT id, username, email, password, first_name, last_name, role, created_at, updated_at, last_login_at FROM users WHERE LOWER(email) = LOWER($1)`
+	queryRow := userRepository.reader.QueryRow(ctx, getByEmailSql, email)
 
 	var user domain.User
-	scanErr := queryRow.Scan(&user.Id, &user.Username, &user.Email, &user.Password, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt)
+	scanErr := queryRow.Scan(&user.Id, &user.Username, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName, &user.Role, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt)
 
 	if errors.Is(scanErr, pgx.ErrNoRows) {
 		return domain.User{}, fmt.Errorf("user not found with email %s: %w", email, scanErr)
@@ -90,26 +103,73 @@ func (userRepository *UserRepository) GetByEmail(email string) (domain.User, err
 	return user, nil
 }
 
-func (userRepository *UserRepository) AddUser(user domain.User) error {
+// GetByUsernameOrEmail looks a user up by exact username or case-insensitive email.
+// When the identifier is one user's username and another's email, the username wins.
+func (userRepository *UserRepository) GetByUsernameOrEmail(identifier string) (domain.User, error) {
+	ctx := context.Background()
+
+	getByIdentifierSql := `SELECT id, username, email, password, first_name, last_name, role, created_at, updated_at, last_login_at FROM users
+		WHERE username = $1 OR LOWER(email) = LOWER($1)
+		ORDER BY username = $1 DESC, id
+		LIMIT 1`
+	queryRow := userRepository.reader.QueryRow(ctx, getByIdentifierSql, identifier)
+
+	var user domain.User
+	scanErr := queryRow.Scan(&user.Id, &user.Username, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName, &user.Role, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt)
+
+	if errors.Is(scanErr, pgx.ErrNoRows) {
+		return domain.User{}, fmt.Errorf("user not found with username or email %s: %w", identifier, scanErr)
+	}
+
+	if scanErr != nil {
+		return domain.User{}, fmt.Errorf("error while getting user with username or email %s: %w", identifier, scanErr)
+	}
+
+	return user, nil
+}
+
+func (userRepository *UserRepository) ExistsByUsername(username string) (bool, error) {
+	ctx := context.Background()
+
+	var exists bool
+	err := userRepository.reader.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)`, username).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error while checking username %s: %w", username, err)
+	}
+	return exists, nil
+}
+
+func (userRepository *UserRepository) ExistsByEmail(email string) (bool, error) {
+	ctx := context.Background()
+
+	var exists bool
+	err := userRepository.reader.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE LOWER(email) = LOWER($1))`, email).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error while checking email %s: %w", email, err)
+	}
+	return exists, nil
+}
+
+func (userRepository *UserRepository) AddUser(user domain.User) (int64, error) {
 	ctx := context.Background()
 
 	insertUserSQL := `
-		INSERT INTO users (username, email, password, first_name, last_name, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO users (username, email, password, first_name, last_name, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id;
 	`
 
 	var userId int64
 	err := userRepository.dbPool.QueryRow(ctx, insertUserSQL,
-		user.Username, user.Email, user.Password, user.FirstName, user.LastName, user.CreatedAt, user.UpdatedAt).Scan(&userId)
+		user.Username, user.Email, user.PasswordHash, user.FirstName, user.LastName, user.Role, user.CreatedAt, user.UpdatedAt).Scan(&userId)
 
 	if err != nil {
-		log.Printf("❌ Error inserting user: %v", err)
-		return fmt.Errorf("failed to insert user: %w", err)
+		logging.Error("error inserting user", logging.Fields{"error": err})
+		return 0, fmt.Errorf("failed to insert user: %w", err)
 	}
 
-	log.Printf("✅ User inserted with ID: %d", userId)
-	return nil
+	logging.Info("user inserted", logging.Fields{"user_id": userId})
+	return userId, nil
 }
 
 func (userRepository *UserRepository) UpdateUser(user domain.User) error {
@@ -117,7 +177,7 @@ func (userRepository *UserRepository) UpdateUser(user domain.User) error {
 
 	updateSql := `UPDATE users SET username = $1, email = $2, first_name = $3, last_name = $4, updated_at = $5 WHERE id = $6`
 
-	commandTag, err := userRepository.dbPool.Exec(ctx, updateSql, 
+	commandTag, err := userRepository.dbPool.Exec(ctx, updateSql,
 		user.Username, user.Email, user.FirstName, user.LastName, user.UpdatedAt, user.Id)
 
 	if err != nil {
@@ -128,7 +188,38 @@ func (userRepository *UserRepository) UpdateUser(user domain.User) error {
 		return fmt.Errorf("user with id %d not found", user.Id)
 	}
 
-	log.Printf("✅ User updated with id %d", user.Id)
+	logging.Info("user updated", logging.Fields{"user_id": user.Id})
+	return nil
+}
+
+func (userRepository *UserRepository) UpdatePassword(userId int64, hashedPassword string) error {
+	ctx := context.Background()
+
+	commandTag, err := userRepository.dbPool.Exec(ctx, `UPDATE users SET password = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`,
+		hashedPassword, userId)
+	if err != nil {
+		return fmt.Errorf("error while updating password of user with id %d: %w", userId, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("user with id %d not found", userId)
+	}
+
+	logging.Info("password hash updated", logging.Fields{"user_id": userId})
+	return nil
+}
+
+func (userRepository *UserRepository) TouchLastLogin(userId int64) error {
+	ctx := context.Background()
+
+	commandTag, err := userRepository.dbPool.Exec(ctx, `UPDATE users SET last_login_at = CURRENT_TIMESTAMP WHERE id = $1`, userId)
+	if err != nil {
+		return fmt.Errorf("error while updating last login of user with id %d: %w", userId, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("user with id %d not found", userId)
+	}
 	return nil
 }
 
@@ -140,15 +231,15 @@ func (userRepository *UserRepository) DeleteById(userId int64) error {
 	commandTag, err := userRepository.dbPool.Exec(ctx, deleteSql, userId)
 
 	if err != nil {
-		log.Printf("ERROR: Error while deleting user with id %d: %v", userId, err)
+		logging.Error("error while deleting user", logging.Fields{"user_id": userId, "error": err})
 		return fmt.Errorf("error while deleting user with id %d: %w", userId, err)
 	}
 
 	if commandTag.RowsAffected() == 0 {
-		log.Printf("WARNING: User with id %d not found for deletion", userId)
+		logging.Warn("user not found for deletion", logging.Fields{"user_id": userId})
 		return fmt.Errorf("user with id %d not found", userId)
 	}
 
-	log.Printf("INFO: User deleted with id %d", userId)
+	logging.Info("user deleted", logging.Fields{"user_id": userId})
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"product-app/common/decimal"
+	"product-app/domain"
+	"product-app/persistence"
+	"product-app/persistence/migration"
+	"product-app/service"
+	"product-app/service/model"
+	"strings"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+)
+
+type seedProduct struct {
+	CategoryName string
+	Product      model.ProductCreate
+}
+
+var seedCategories = []domain.Category{
+	{Name: "Electronics", Description: "Electronic devices and gadgets"},
+	{Name: "Home Appliances", Description: "Appliances for kitchen and laundry"},
+	{Name: "Home Decoration", Description: "Lighting and decoration items"},
+	{Name: "Books", Description: "Books and educational materials"},
+}
+
+var seedProducts = []seedProduct{
+	{CategoryName: "Home Appliances", Product: model.ProductCreate{
+		Name: "AirFryer", Price: decimal.NewFromInt(3000), Description: "AirFryer açıklaması", Discount: decimal.NewFromInt(22), Store: "ABC TECH",
+		ImageUrls: []string{"https://picsum.photos/seed/airfryer-1/600", "https://picsum.photos/seed/airfryer-2/600"},
+		Tags:      []string{"kitchen", "sale"},
+	}},
+	{CategoryName: "Home Appliances", Product: model.ProductCreate{
+		Name: "Ütü", Price: decimal.NewFromInt(1500), Description: "Ütü açıklaması", Discount: decimal.NewFromInt(10), Store: "ABC TECH",
+		ImageUrls: []string{"https://picsum.photos/seed/utu-1/600"},
+		Tags:      []string{"laundry"},
+	}},
+	{CategoryName: "Home Appliances", Product: model.ProductCreate{
+		Name: "Çamaşır Makinesi", Price: decimal.NewFromInt(10000), Description: "Çamaşır Makinesi açıklaması", Discount: decimal.NewFromInt(15), Store: "ABC TECH",
+		ImageUrls: []string{"https://picsum.photos/seed/camasir-1/600", "https://picsum.photos/seed/camasir-2/600"},
+		Tags:      []string{"laundry", "sale"},
+	}},
+	{CategoryName: "Home Decoration", Product: model.ProductCreate{
+		Name: "Lambader", Price: decimal.NewFromInt(2000), Description: "Lambader açıklaması", Discount: decimal.NewFromInt(0), Store: "Dekorasyon Sarayı",
+		ImageUrls: []string{"https://picsum.photos/seed/lambader-1/600"},
+		Tags:      []string{"lighting"},
+	}},
+	{CategoryName: "Electronics", Product: model.ProductCreate{
+		Name: "Kablosuz Kulaklık", Price: decimal.NewFromInt(1200), Description: "Bluetooth kulaklık", Discount: decimal.NewFromInt(5), Store: "ABC TECH",
+		ImageUrls: []string{"https://picsum.photos/seed/kulaklik-1/600"},
+		Tags:      []string{"audio"},
+	}},
+	{CategoryName: "Books", Product: model.ProductCreate{
+		Name: "Go Programlama", Price: decimal.NewFromInt(350), Description: "Go dili için başlangıç kitabı", Discount: decimal.NewFromInt(0), Store: "Kitap Dünyası",
+		ImageUrls: []string{"https://picsum.photos/seed/go-kitap/600"},
+	}},
+}
+
+const (
+	seedUsername  = "demo"
+	seedEmail     = "demo@example.com"
+	seedPassword  = "demo123"
+	seedFirstName = "Demo"
+	seedLastName  = "User"
+)
+
+// runSeed fills the development database with sample data. Rows that already
+// exist (categories by name, products by name and store, the user by username)
+// are skipped, so running it repeatedly is safe.
+func runSeed(ctx context.Context, dbPool *pgxpool.Pool) error {
+	if err := migration.Up(ctx, dbPool); err != nil {
+		return err
+	}
+
+	categoryService := service.NewCategoryService(persistence.NewCategoryRepository(persistence.SinglePool(dbPool), persistence.DefaultReadRetryPolicy), service.DefaultCategorySettings)
+	productRepository := persistence.NewProductRepository(persistence.SinglePool(dbPool), persistence.DefaultReadRetryPolicy, persistence.DefaultMaxImagesPerProduct)
+	productService := service.NewProductService(productRepository, service.DefaultProductSettings)
+	userRepository := persistence.NewUserRepository(dbPool, persistence.DefaultReadRetryPolicy)
+	userService := service.NewUserService(userRepository, service.DefaultPasswordHashParams)
+
+	categoryIds, err := seedCategoryIds(categoryService)
+	if err != nil {
+		return err
+	}
+
+	for _, seed := range seedProducts {
+		exists, err := productExists(productRepository, seed.Product.Name, seed.Product.Store)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		productCreate := seed.Product
+		productCreate.CategoryID = categoryIds[strings.ToLower(seed.CategoryName)]
+		if _, err := productService.Add(productCreate); err != nil {
+			return fmt.Errorf("error while seeding product %q: %w", productCreate.Name, err)
+		}
+		log.Infof("✅ Seeded product %s", productCreate.Name)
+	}
+
+	if _, err := userRepository.GetByUsername(seedUsername); err != nil {
+		if _, err := userService.Register(seedUsername, seedEmail, seedPassword, seedFirstName, seedLastName); err != nil {
+			return fmt.Errorf("error while seeding user %q: %w", seedUsername, err)
+		}
+		log.Infof("✅ Seeded user %s (password: %s)", seedUsername, seedPassword)
+	}
+
+	log.Info("✅ Seed completed")
+	return nil
+}
+
+// seedCategoryIds creates the missing seed categories and returns the id of
+// every seed category keyed by lowercased name, matching how category names are deduplicated.
+func seedCategoryIds(categoryService service.ICategoryService) (map[string]int64, error) {
+	existing := map[string]bool{}
+	for _, category := range categoryService.GetAllCategories() {
+		existing[strings.ToLower(category.Name)] = true
+	}
+
+	for _, category := range seedCategories {
+		if existing[strings.ToLower(category.Name)] {
+			continue
+		}
+		if _, err := categoryService.AddCategory(category); err != nil {
+			return nil, fmt.Errorf("error while seeding category %q: %w", category.Name, err)
+		}
+		log.Infof("✅ Seeded category %s", category.Name)
+	}
+
+	categoryIds := map[string]int64{}
+	for _, category := range categoryService.GetAllCategories() {
+		categoryIds[strings.ToLower(category.Name)] = category.Id
+	}
+	return categoryIds, nil
+}
+
+func productExists(productRepository persistence.IProductRepository, name string, store string) (bool, error) {
+	products, _, err := productRepository.Find(model.ProductFilter{Store: store, Search: name})
+	if err != nil {
+		return false, fmt.Errorf("error while checking seed product %q: %w", name, err)
+	}
+	for _, product := range products {
+		if product.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -2,25 +2,57 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"product-app/domain"
 	"product-app/persistence"
+	"product-app/service/model"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 type ICategoryService interface {
 	GetAllCategories() []domain.Category
+	GetCategoryTree() ([]domain.CategoryNode, error)
 	GetById(categoryId int64) (domain.Category, error)
-	AddCategory(category domain.Category) error
+	// GetCurrentById reads the category from the primary, for answering with it right
+	// after a write.
+	GetCurrentById(categoryId int64) (domain.Category, error)
+	GetBySlug(slug string) (domain.Category, error)
+	SearchCategories(query string, limit int) ([]domain.Category, error)
+	AddCategory(category domain.Category) (domain.Category, error)
 	UpdateCategory(category domain.Category) error
 	DeleteById(categoryId int64) error
+	ForceDeleteById(categoryId int64) (int64, error)
+	RestoreCategory(categoryId int64) (domain.Category, error)
+	EnsureUncategorized() (domain.Category, error)
+}
+
+// CategorySettings are the deployment-specific rules for categories.
+type CategorySettings struct {
+	// RequireDescription rejects categories without a description; when false an empty
+	// description is accepted.
+	RequireDescription bool
+	// MaxDescriptionLength is the longest description accepted, in characters.
+	MaxDescriptionLength int
+}
+
+var DefaultCategorySettings = CategorySettings{
+	RequireDescription:   true,
+	MaxDescriptionLength: DefaultMaxDescriptionLength,
 }
 
 type CategoryService struct {
-	categoryRepository persistence.ICategoryRepository
+	categoryRepository   persistence.ICategoryRepository
+	requireDescription   bool
+	maxDescriptionLength int
 }
 
-func NewCategoryService(categoryRepository persistence.ICategoryRepository) ICategoryService {
+func NewCategoryService(categoryRepository persistence.ICategoryRepository, settings CategorySettings) ICategoryService {
 	return &CategoryService{
-		categoryRepository: categoryRepository,
+		categoryRepository:   categoryRepository,
+		requireDescription:   settings.RequireDescription,
+		maxDescriptionLength: settings.MaxDescriptionLength,
 	}
 }
 
@@ -28,36 +60,245 @@ func (categoryService *CategoryService) GetAllCategories() []domain.Category {
 	return categoryService.categoryRepository.GetAllCategories()
 }
 
+// GetCategoryTree returns the top-level categories with their subcategories nested under
+// children. Categories whose parent no longer exists are treated as top-level.
+func (categoryService *CategoryService) GetCategoryTree() ([]domain.CategoryNode, error) {
+	categories := categoryService.categoryRepository.GetAllCategories()
+
+	exists := make(map[int64]bool, len(categories))
+	for _, category := range categories {
+		exists[category.Id] = true
+	}
+	childrenByParent := make(map[int64][]domain.Category)
+	var roots []domain.Category
+	for _, category := range categories {
+		if category.ParentId == nil || !exists[*category.ParentId] {
+			roots = append(roots, category)
+			continue
+		}
+		childrenByParent[*category.ParentId] = append(childrenByParent[*category.ParentId], category)
+	}
+
+	visited := make(map[int64]bool, len(categories))
+	var buildNode func(category domain.Category) domain.CategoryNode
+	buildNode = func(category domain.Category) domain.CategoryNode {
+		visited[category.Id] = true
+		node := domain.CategoryNode{Category: category, Children: []domain.CategoryNode{}}
+		for _, child := range childrenByParent[category.Id] {
+			node.Children = append(node.Children, buildNode(child))
+		}
+		return node
+	}
+
+	tree := []domain.CategoryNode{}
+	for _, root := range roots {
+		tree = append(tree, buildNode(root))
+	}
+	// Every walk starts at a root, so a parent cycle can never be entered and recursion
+	// ends; the categories on or below such a cycle are simply left unvisited. Parents are
+	// validated on write, so this only reports rows edited by hand.
+	for _, category := range categories {
+		if !visited[category.Id] {
+			return nil, fmt.Errorf("%w: category with id %d", domain.ErrCategoryCycle, category.Id)
+		}
+	}
+	return tree, nil
+}
+
 func (categoryService *CategoryService) GetById(categoryId int64) (domain.Category, error) {
 	return categoryService.categoryRepository.GetById(categoryId)
 }
 
-func (categoryService *CategoryService) AddCategory(category domain.Category) error {
-	if err := validateCategory(category); err != nil {
-		return err
+func (categoryService *CategoryService) GetCurrentById(categoryId int64) (domain.Category, error) {
+	return categoryService.categoryRepository.GetCurrentById(categoryId)
+}
+
+func (categoryService *CategoryService) GetBySlug(slug string) (domain.Category, error) {
+	return categoryService.categoryRepository.GetBySlug(slug)
+}
+
+func (categoryService *CategoryService) SearchCategories(query string, limit int) ([]domain.Category, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrEmptySearchQuery
+	}
+	return categoryService.categoryRepository.SearchCategories(query, limit)
+}
+
+func (categoryService *CategoryService) AddCategory(category domain.Category) (domain.Category, error) {
+	category.Name = strings.TrimSpace(category.Name)
+	if err := categoryService.validateCategory(category); err != nil {
+		return domain.Category{}, err
+	}
+	if err := categoryService.ensureNameAvailable(category.Name, 0); err != nil {
+		return domain.Category{}, err
+	}
+	if err := categoryService.validateParent(category); err != nil {
+		return domain.Category{}, err
+	}
+	slug, err := categoryService.slugFor(category.Name, 0)
+	if err != nil {
+		return domain.Category{}, err
+	}
+	category.Slug = slug
+	now := time.Now()
+	category.CreatedAt = now
+	category.UpdatedAt = now
+	categoryId, err := categoryService.categoryRepository.AddCategory(category)
+	if err != nil {
+		return domain.Category{}, err
 	}
-	return categoryService.categoryRepository.AddCategory(category)
+	category.Id = categoryId
+	return category, nil
 }
 
 func (categoryService *CategoryService) UpdateCategory(category domain.Category) error {
-	if err := validateCategory(category); err != nil {
+	category.Name = strings.TrimSpace(category.Name)
+	if err := categoryService.validateCategory(category); err != nil {
+		return err
+	}
+	if err := categoryService.ensureNotUncategorized(category.Id); err != nil {
+		return err
+	}
+	if err := categoryService.ensureNameAvailable(category.Name, category.Id); err != nil {
+		return err
+	}
+	if err := categoryService.validateParent(category); err != nil {
+		return err
+	}
+	slug, err := categoryService.slugFor(category.Name, category.Id)
+	if err != nil {
 		return err
 	}
+	category.Slug = slug
+	category.UpdatedAt = time.Now()
 	return categoryService.categoryRepository.UpdateCategory(category)
 }
 
+// DeleteById soft-deletes an empty category so RestoreCategory can undo it; use
+// ForceDeleteById for one that still has products.
 func (categoryService *CategoryService) DeleteById(categoryId int64) error {
+	if err := categoryService.ensureNotUncategorized(categoryId); err != nil {
+		return err
+	}
 	return categoryService.categoryRepository.DeleteById(categoryId)
 }
 
-func validateCategory(category domain.Category) error {
-	if err := validateNameWithRegex(category.Name, "category name is required"); err != nil {
+// ForceDeleteById moves the category's products to Uncategorized and deletes it in one
+// step, returning how many products were moved. If the delete fails nothing is moved.
+func (categoryService *CategoryService) ForceDeleteById(categoryId int64) (int64, error) {
+	category, err := categoryService.categoryRepository.GetCurrentById(categoryId)
+	if err != nil {
+		return 0, err
+	}
+	if category.Slug == domain.UncategorizedSlug {
+		return 0, domain.ErrUncategorizedProtected
+	}
+	uncategorized, err := categoryService.EnsureUncategorized()
+	if err != nil {
+		return 0, err
+	}
+	return categoryService.categoryRepository.ReassignProductsAndDelete(categoryId, uncategorized.Id)
+}
+
+// RestoreCategory brings back a soft-deleted category and returns it. Only empty
+// categories are soft-deleted, so it comes back without products.
+func (categoryService *CategoryService) RestoreCategory(categoryId int64) (domain.Category, error) {
+	if err := categoryService.categoryRepository.RestoreCategory(categoryId); err != nil {
+		return domain.Category{}, err
+	}
+	return categoryService.categoryRepository.GetCurrentById(categoryId)
+}
+
+// EnsureUncategorized returns the Uncategorized category, creating it on first use.
+func (categoryService *CategoryService) EnsureUncategorized() (domain.Category, error) {
+	category, err := categoryService.categoryRepository.GetCurrentBySlug(domain.UncategorizedSlug)
+	if !errors.Is(err, domain.ErrCategoryNotFound) {
+		return category, err
+	}
+	now := time.Now()
+	category = domain.Category{
+		Name:        domain.UncategorizedName,
+		Slug:        domain.UncategorizedSlug,
+		Description: "Products without a category",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	categoryId, err := categoryService.categoryRepository.AddCategory(category)
+	if err != nil {
+		return domain.Category{}, err
+	}
+	category.Id = categoryId
+	return category, nil
+}
+
+func (categoryService *CategoryService) ensureNotUncategorized(categoryId int64) error {
+	category, err := categoryService.categoryRepository.GetCurrentById(categoryId)
+	if err == nil && category.Slug == domain.UncategorizedSlug {
+		return domain.ErrUncategorizedProtected
+	}
+	return nil
+}
+
+func (categoryService *CategoryService) validateCategory(category domain.Category) error {
+	if err := model.ValidateName(category.Name, "category name is required"); err != nil {
 		return err
 	}
 
-	if category.Description == "" {
+	if category.Description == "" && categoryService.requireDescription {
 		return errors.New("category description is required")
 	}
+	if utf8.RuneCountInString(category.Description) > categoryService.maxDescriptionLength {
+		return fmt.Errorf("category %w: at most %d characters are allowed", model.ErrDescriptionTooLong, categoryService.maxDescriptionLength)
+	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// ensureNameAvailable rejects a name that another category already uses, ignoring case.
+// The name is stored as entered, so "iPhone Cases" keeps its casing for display.
+func (categoryService *CategoryService) ensureNameAvailable(name string, categoryId int64) error {
+	exists, err := categoryService.categoryRepository.CategoryExistsByName(name, categoryId)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", domain.ErrCategoryNameTaken, name)
+	}
+	return nil
+}
+
+// validateParent checks that the parent category exists and is neither the category
+// itself nor one of its descendants.
+func (categoryService *CategoryService) validateParent(category domain.Category) error {
+	if category.ParentId == nil {
+		return nil
+	}
+	seen := make(map[int64]bool)
+	for parentId := category.ParentId; parentId != nil; {
+		if *parentId == category.Id || seen[*parentId] {
+			return fmt.Errorf("%w: category with id %d", domain.ErrCategoryCycle, category.Id)
+		}
+		seen[*parentId] = true
+		parent, err := categoryService.categoryRepository.GetCurrentById(*parentId)
+		if err != nil {
+			return fmt.Errorf("parent %w", err)
+		}
+		parentId = parent.ParentId
+	}
+	return nil
+}
+
+// slugFor generates a slug from name that is free or already owned by categoryId.
+func (categoryService *CategoryService) slugFor(name string, categoryId int64) (string, error) {
+	return uniqueSlug(generateSlug(name, "category"), func(slug string) (bool, error) {
+		existing, err := categoryService.categoryRepository.GetCurrentBySlug(slug)
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return existing.Id != categoryId, nil
+	})
+}
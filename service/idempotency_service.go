@@ -0,0 +1,98 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"product-app/common/logging"
+	"product-app/persistence"
+	"product-app/service/model"
+	"time"
+)
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used with a different request body")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+type IIdempotencyService interface {
+	// CreateProduct creates a product at most once per key. replayed is true when
+	// the product id comes from an earlier request with the same key.
+	CreateProduct(key string, productCreate model.ProductCreate) (productId int64, replayed bool, err error)
+}
+
+type IdempotencyService struct {
+	idempotencyRepository persistence.IIdempotencyRepository
+	productService        IProductService
+	keyTTL                time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepository persistence.IIdempotencyRepository, productService IProductService, keyTTL time.Duration) IIdempotencyService {
+	return &IdempotencyService{
+		idempotencyRepository: idempotencyRepository,
+		productService:        productService,
+		keyTTL:                keyTTL,
+	}
+}
+
+func (idempotencyService *IdempotencyService) CreateProduct(key string, productCreate model.ProductCreate) (int64, bool, error) {
+	requestHash, err := hashRequest(productCreate)
+	if err != nil {
+		return 0, false, err
+	}
+
+	// Claim the key before creating anything, so concurrent retries cannot both create
+	reserved, err := idempotencyService.idempotencyRepository.ReserveKey(key, requestHash, time.Now().Add(-idempotencyService.keyTTL))
+	if err != nil {
+		return 0, false, err
+	}
+	if !reserved {
+		return idempotencyService.replay(key, requestHash)
+	}
+
+	productId, err := idempotencyService.productService.Add(productCreate)
+	if err != nil {
+		if releaseErr := idempotencyService.idempotencyRepository.ReleaseKey(key); releaseErr != nil {
+			logging.Warn("idempotency key stays reserved after a failed create", logging.Fields{"idempotency_key": key, "error": releaseErr})
+		}
+		return 0, false, err
+	}
+
+	// The product exists either way; failing to record it only leaves the key reserved,
+	// so retries are answered as in progress until it expires
+	if err := idempotencyService.idempotencyRepository.CompleteKey(key, productId); err != nil {
+		logging.Error("product created but its idempotency key was not saved", logging.Fields{"idempotency_key": key, "product_id": productId, "error": err})
+	}
+
+	return productId, false, nil
+}
+
+// replay answers a request whose key is held by an earlier one.
+func (idempotencyService *IdempotencyService) replay(key string, requestHash string) (int64, bool, error) {
+	existing, err := idempotencyService.idempotencyRepository.GetByKey(key)
+	// Not found means the earlier request failed and released the key just now
+	if errors.Is(err, persistence.ErrIdempotencyKeyNotFound) {
+		return 0, false, ErrIdempotencyKeyInProgress
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if existing.RequestHash != requestHash {
+		return 0, false, ErrIdempotencyKeyReused
+	}
+	if existing.ProductId == 0 {
+		return 0, false, ErrIdempotencyKeyInProgress
+	}
+	return existing.ProductId, true, nil
+}
+
+func hashRequest(productCreate model.ProductCreate) (string, error) {
+	payload, err := json.Marshal(productCreate)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash request: %w", err)
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
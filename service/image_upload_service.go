@@ -0,0 +1,108 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"product-app/service/model"
+	"product-app/storage"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrUnsupportedUploadType = errors.New("unsupported image content type")
+	ErrInvalidUploadKey      = errors.New("upload key does not belong to the product")
+)
+
+// uploadExtensions maps the image types clients may upload to the extension of the stored object.
+var uploadExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+	"image/avif": ".avif",
+}
+
+// uploadKeyPattern matches the keys CreateUpload generates: products/<id>/<random hex><extension>.
+var uploadKeyPattern = regexp.MustCompile(`^products/([0-9]+)/[0-9a-f]{32}(\.[a-z]+)$`)
+
+type IImageUploadService interface {
+	// CreateUpload signs an upload for a new image of the product. Nothing is stored on
+	// the product until the client confirms the upload.
+	CreateUpload(productId int64, contentType string) (model.ImageUpload, error)
+	// ConfirmUpload appends the public URL of an uploaded object to the product images.
+	// The object itself is not checked; a client confirming an upload it never made
+	// only gets a broken image on its own product.
+	ConfirmUpload(productId int64, confirm model.UploadConfirm) error
+}
+
+type ImageUploadService struct {
+	storage        storage.Storage
+	productService IProductService
+	uploadUrlTTL   time.Duration
+}
+
+func NewImageUploadService(storage storage.Storage, productService IProductService, uploadUrlTTL time.Duration) IImageUploadService {
+	return &ImageUploadService{
+		storage:        storage,
+		productService: productService,
+		uploadUrlTTL:   uploadUrlTTL,
+	}
+}
+
+func (imageUploadService *ImageUploadService) CreateUpload(productId int64, contentType string) (model.ImageUpload, error) {
+	extension, ok := uploadExtensions[contentType]
+	if !ok {
+		return model.ImageUpload{}, fmt.Errorf("%w: %q", ErrUnsupportedUploadType, contentType)
+	}
+	if _, err := imageUploadService.productService.GetCurrentById(productId); err != nil {
+		return model.ImageUpload{}, err
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return model.ImageUpload{}, fmt.Errorf("error while generating upload key: %w", err)
+	}
+	key := fmt.Sprintf("products/%d/%s%s", productId, hex.EncodeToString(random), extension)
+
+	upload, err := imageUploadService.storage.PresignUpload(key, contentType, imageUploadService.uploadUrlTTL)
+	if err != nil {
+		return model.ImageUpload{}, err
+	}
+	return model.ImageUpload{
+		Key:       key,
+		PublicUrl: imageUploadService.storage.PublicUrl(key),
+		Upload:    upload,
+	}, nil
+}
+
+func (imageUploadService *ImageUploadService) ConfirmUpload(productId int64, confirm model.UploadConfirm) error {
+	match := uploadKeyPattern.FindStringSubmatch(confirm.Key)
+	if match == nil || match[1] != strconv.FormatInt(productId, 10) {
+		return fmt.Errorf("%w: %q", ErrInvalidUploadKey, confirm.Key)
+	}
+	mimeType, ok := mimeTypeOf(path.Ext(confirm.Key))
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidUploadKey, confirm.Key)
+	}
+
+	return imageUploadService.productService.AddProductImages(productId, []model.ImageCreate{{
+		Url:      imageUploadService.storage.PublicUrl(confirm.Key),
+		Width:    confirm.Width,
+		Height:   confirm.Height,
+		MimeType: &mimeType,
+	}})
+}
+
+func mimeTypeOf(extension string) (string, bool) {
+	for mimeType, uploadExtension := range uploadExtensions {
+		if uploadExtension == extension {
+			return mimeType, true
+		}
+	}
+	return "", false
+}
@@ -1,11 +1,134 @@
 package model
 
+import (
+	"product-app/common/decimal"
+	"product-app/storage"
+	"time"
+)
+
+// ProductCreate describes a new product. Slug is optional and generated from Name when empty.
 type ProductCreate struct {
-	Name        string  `json:"name"`
-	Price       float32 `json:"price"`
-	Description string  `json:"description"`
-	Discount    float32 `json:"discount"`
-	Store       string  `json:"store"`
-	ImageUrls   []string `json:"image_urls"`
-	CategoryID  int64   `json:"category_id"`
+	Name        string          `json:"name"`
+	Slug        string          `json:"slug"`
+	Price       decimal.Decimal `json:"price"`
+	Currency    string          `json:"currency"`
+	Description string          `json:"description"`
+	Discount    decimal.Decimal `json:"discount"`
+	Store       string          `json:"store"`
+	ImageUrls   []string        `json:"image_urls"`
+	CategoryID  int64           `json:"category_id"`
+	Tags        []string        `json:"tags"`
+	// Status is optional and defaults to active; create a draft to hide it until it is ready.
+	Status string `json:"status"`
+	// Stock is optional; without it the product's stock is not tracked.
+	Stock *int `json:"stock"`
+	// UserId is the creating user, taken from the token rather than the body; 0 when the
+	// product is created anonymously.
+	UserId int64 `json:"user_id"`
+}
+
+// ImageCreate describes an image added to an existing product. Width, Height and
+// MimeType are optional and stored as unknown when absent.
+type ImageCreate struct {
+	Url      string  `json:"url"`
+	Width    *int    `json:"width"`
+	Height   *int    `json:"height"`
+	MimeType *string `json:"mime_type"`
+}
+
+// ImageUpload is a signed upload handed to a client. Key identifies the object when the
+// upload is confirmed and PublicUrl is the image URL the product will get.
+type ImageUpload struct {
+	Key       string
+	PublicUrl string
+	Upload    storage.SignedUpload
+}
+
+// UploadConfirm reports a finished upload; Width and Height are optional like in ImageCreate.
+type UploadConfirm struct {
+	Key    string `json:"key"`
+	Width  *int   `json:"width"`
+	Height *int   `json:"height"`
+}
+
+// ProductPatch carries a partial product update; nil fields are left unchanged.
+// Version is the product version the client read and is always required.
+// Renaming a product keeps its slug so existing URLs stay valid; set Slug to change it.
+type ProductPatch struct {
+	Version     int              `json:"version"`
+	Name        *string          `json:"name"`
+	Slug        *string          `json:"slug"`
+	Price       *decimal.Decimal `json:"price"`
+	Description *string          `json:"description"`
+	Discount    *decimal.Decimal `json:"discount"`
+	Store       *string          `json:"store"`
+	CategoryID  *int64           `json:"category_id"`
+	Stock       *int             `json:"stock"`
+}
+
+func (productPatch ProductPatch) IsEmpty() bool {
+	return productPatch.Name == nil &&
+		productPatch.Slug == nil &&
+		productPatch.Price == nil &&
+		productPatch.Description == nil &&
+		productPatch.Discount == nil &&
+		productPatch.Store == nil &&
+		productPatch.CategoryID == nil &&
+		productPatch.Stock == nil
+}
+
+// PageRequest describes an optional window and ordering over a listing.
+// A zero Limit means no limit; an empty Sort keeps the default id order.
+type PageRequest struct {
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+	Sort   string `json:"sort"`
+}
+
+// ChangeCursor is a position in the product change feed: changes are ordered by time and
+// then product id, and a feed page holds the changes after the cursor.
+type ChangeCursor struct {
+	ChangedAt time.Time
+	ProductId int64
+}
+
+// PriceRange bounds a listing by price. Nil bounds are open; the bounds are
+// expressed in Currency, which is required as soon as either bound is set.
+type PriceRange struct {
+	Min      *decimal.Decimal `json:"min_price"`
+	Max      *decimal.Decimal `json:"max_price"`
+	Currency string           `json:"currency"`
+}
+
+func (priceRange PriceRange) IsSet() bool {
+	return priceRange.Min != nil || priceRange.Max != nil
+}
+
+// ProductStatusAll is the ProductFilter status that lists products of every status.
+const ProductStatusAll = "all"
+
+// ProductFilter narrows a product listing. Zero values mean "no constraint", except for
+// Status: empty lists active products only, ProductStatusAll lists every status.
+// MinPrice and MaxPrice are expressed in Currency, which they require.
+type ProductFilter struct {
+	Status     string           `json:"status"`
+	Store      string           `json:"store"`
+	CategoryId int64            `json:"category_id"`
+	UserId     int64            `json:"user_id"`
+	Tag        string           `json:"tag"`
+	Currency   string           `json:"currency"`
+	MinPrice   *decimal.Decimal `json:"min_price"`
+	MaxPrice   *decimal.Decimal `json:"max_price"`
+	Search     string           `json:"search"`
+	// Featured restricts the listing to products flagged for the featured listing.
+	Featured bool `json:"featured"`
+	// Ids restricts the listing to these products; nil means any product.
+	Ids []int64 `json:"ids"`
+	// AfterId switches to keyset pagination: only products with a greater id are listed
+	// and no total is counted. It requires the default id order.
+	AfterId *int64 `json:"after_id"`
+	// CreatedFrom and CreatedTo bound created_at inclusively; nil leaves that side open.
+	CreatedFrom *time.Time `json:"created_from"`
+	CreatedTo   *time.Time `json:"created_to"`
+	PageRequest
 }
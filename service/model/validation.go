@@ -0,0 +1,340 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"product-app/common/decimal"
+	"product-app/domain"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrUnsupportedCurrency   = errors.New("unsupported currency")
+	ErrDescriptionTooLong    = errors.New("description is too long")
+	ErrDescriptionHasMarkup  = errors.New("description must not contain HTML")
+	ErrInvalidImage          = errors.New("invalid image")
+	ErrInvalidStatus         = errors.New("status must be one of draft, active or discontinued")
+	ErrFinalPriceNotPositive = errors.New("discounted price must be greater than zero")
+	ErrDuplicateImageUrl     = errors.New("duplicate image url")
+	ErrNegativeStock         = errors.New("stock must not be negative")
+	ErrNegativeCategoryId    = errors.New("category_id must not be negative; use 0 for no category")
+
+	errPriceNotPositive = errors.New("product price must be greater than zero")
+)
+
+// Codes of FieldError. Clients match on them to show inline errors, so existing codes
+// must never be renamed; add a new code instead.
+const (
+	CodeRequired              = "required"
+	CodeInvalidCharacters     = "invalid_characters"
+	CodeInvalidSlug           = "invalid_slug"
+	CodePriceNotPositive      = "price_not_positive"
+	CodeUnsupportedCurrency   = "unsupported_currency"
+	CodeDiscountOutOfRange    = "discount_out_of_range"
+	CodeDescriptionTooLong    = "description_too_long"
+	CodeDescriptionHasMarkup  = "description_has_markup"
+	CodeInvalidStatus         = "invalid_status"
+	CodeFinalPriceNotPositive = "final_price_not_positive"
+	CodeDuplicateImageUrl     = "duplicate_image_url"
+	CodeNegativeStock         = "stock_negative"
+	CodeNegativeCategoryId    = "category_id_negative"
+)
+
+// FieldError is a failed rule on one request field. Field is the JSON name of the field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	err     error
+}
+
+func (fieldError FieldError) Error() string {
+	return fieldError.Message
+}
+
+func (fieldError FieldError) Unwrap() error {
+	return fieldError.err
+}
+
+// ValidationError lists every field that failed validation. Its message joins the field
+// messages, so a single failure reads exactly like the underlying error.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (validationError *ValidationError) Error() string {
+	messages := make([]string, len(validationError.Fields))
+	for i, fieldError := range validationError.Fields {
+		messages[i] = fieldError.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (validationError *ValidationError) Unwrap() []error {
+	errs := make([]error, len(validationError.Fields))
+	for i, fieldError := range validationError.Fields {
+		errs[i] = fieldError
+	}
+	return errs
+}
+
+// add records err as a failure of field; a nil err is ignored.
+func (validationError *ValidationError) add(field string, code string, err error) {
+	if err != nil {
+		validationError.Fields = append(validationError.Fields, FieldError{Field: field, Code: code, Message: err.Error(), err: err})
+	}
+}
+
+// addName checks a required name field, telling a missing name apart from one with
+// invalid characters.
+func (validationError *ValidationError) addName(field string, name string, requiredMessage string) {
+	code := CodeInvalidCharacters
+	if name == "" {
+		code = CodeRequired
+	}
+	validationError.add(field, code, ValidateName(name, requiredMessage))
+}
+
+// orNil returns the validation error, or nil when no field failed.
+func (validationError *ValidationError) orNil() error {
+	if len(validationError.Fields) == 0 {
+		return nil
+	}
+	return validationError
+}
+
+// JoinValidationErrors merges the fields of several validation results into one
+// ValidationError. Nil results are skipped, and nil is returned when all are nil.
+func JoinValidationErrors(errs ...error) error {
+	joined := &ValidationError{}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var validationError *ValidationError
+		if !errors.As(err, &validationError) {
+			return err
+		}
+		joined.Fields = append(joined.Fields, validationError.Fields...)
+	}
+	return joined.orNil()
+}
+
+// supportedCurrencies are the ISO 4217 codes products may be priced in.
+var supportedCurrencies = map[string]bool{"TRY": true, "USD": true, "EUR": true, "GBP": true}
+
+var namePattern = regexp.MustCompile(`^[\p{L}\p{N}\s]+$`)
+
+var emailPattern = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+// slugPattern accepts lowercase alphanumeric words joined by single hyphens.
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// markupPattern matches the start of an HTML tag, comment or doctype. A "<" followed by a
+// space or digit, as in "5 < 10", is plain text and does not match.
+var markupPattern = regexp.MustCompile(`<\s*[/!?]?\s*[a-zA-Z!-]`)
+
+func IsSupportedCurrency(currency string) bool {
+	return supportedCurrencies[currency]
+}
+
+func ValidateSlug(slug string) error {
+	if !slugPattern.MatchString(slug) {
+		return fmt.Errorf("slug %q must contain only lowercase letters, digits and single hyphens", slug)
+	}
+	return nil
+}
+
+// ValidateName requires a non-empty name made of letters, digits and spaces.
+func ValidateName(name string, errorMessage string) error {
+	if name == "" {
+		return errors.New(errorMessage)
+	}
+	if !namePattern.MatchString(name) {
+		return errors.New("contains invalid characters (only alphanumeric and space allowed)")
+	}
+	return nil
+}
+
+func ValidateEmail(email string) error {
+	if email == "" {
+		return errors.New("email is required")
+	}
+	if !emailPattern.MatchString(email) {
+		return errors.New("invalid email format")
+	}
+	return nil
+}
+
+func ValidateStatus(status string) error {
+	if !domain.IsValidProductStatus(status) {
+		return ErrInvalidStatus
+	}
+	return nil
+}
+
+// ValidateDescription limits a description to maxLength characters and rejects HTML, so
+// a description can be rendered as-is without opening the door to stored XSS. A failure
+// is returned as a ValidationError on the description field.
+func ValidateDescription(description string, maxLength int) error {
+	validationError := &ValidationError{}
+	if utf8.RuneCountInString(description) > maxLength {
+		validationError.add("description", CodeDescriptionTooLong, fmt.Errorf("%w: at most %d characters are allowed", ErrDescriptionTooLong, maxLength))
+	} else if markupPattern.MatchString(description) {
+		validationError.add("description", CodeDescriptionHasMarkup, ErrDescriptionHasMarkup)
+	}
+	return validationError.orNil()
+}
+
+// Validate checks an image; the optional dimensions must be positive and the MIME type
+// must name an image type such as image/webp.
+func (imageCreate ImageCreate) Validate() error {
+	if strings.TrimSpace(imageCreate.Url) == "" {
+		return fmt.Errorf("%w: url is required", ErrInvalidImage)
+	}
+	if (imageCreate.Width != nil && *imageCreate.Width <= 0) || (imageCreate.Height != nil && *imageCreate.Height <= 0) {
+		return fmt.Errorf("%w: width and height must be positive", ErrInvalidImage)
+	}
+	if imageCreate.MimeType != nil && !strings.HasPrefix(*imageCreate.MimeType, "image/") {
+		return fmt.Errorf("%w: mime_type %q is not an image type", ErrInvalidImage, *imageCreate.MimeType)
+	}
+	return nil
+}
+
+// FindDuplicateUrl returns the first url that occurs earlier in urls too.
+func FindDuplicateUrl(urls []string) (string, bool) {
+	seen := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		if seen[url] {
+			return url, true
+		}
+		seen[url] = true
+	}
+	return "", false
+}
+
+// ValidateUniqueImageUrls rejects image_urls naming the same url twice. A failure is
+// returned as a ValidationError on the image_urls field.
+func ValidateUniqueImageUrls(urls []string) error {
+	validationError := &ValidationError{}
+	if url, found := FindDuplicateUrl(urls); found {
+		validationError.add("image_urls", CodeDuplicateImageUrl, fmt.Errorf("%w %q", ErrDuplicateImageUrl, url))
+	}
+	return validationError.orNil()
+}
+
+// DedupeImageUrls drops repeated urls, keeping the first occurrence of each in order.
+func DedupeImageUrls(urls []string) []string {
+	if _, found := FindDuplicateUrl(urls); !found {
+		return urls
+	}
+	seen := make(map[string]bool, len(urls))
+	unique := make([]string, 0, len(urls))
+	for _, url := range urls {
+		if !seen[url] {
+			seen[url] = true
+			unique = append(unique, url)
+		}
+	}
+	return unique
+}
+
+// Validate checks a new product and reports every failing field in a ValidationError.
+// The discount ceiling is configurable, so the caller passes it in; an empty Currency
+// is rejected, callers apply their default first.
+func (productCreate ProductCreate) Validate(maxDiscount decimal.Decimal) error {
+	validationError := &ValidationError{}
+	validationError.addName("name", productCreate.Name, "product name is required")
+
+	if productCreate.Slug != "" {
+		validationError.add("slug", CodeInvalidSlug, ValidateSlug(productCreate.Slug))
+	}
+
+	if productCreate.Price.Sign() <= 0 {
+		validationError.add("price", CodePriceNotPositive, errPriceNotPositive)
+	}
+
+	if !IsSupportedCurrency(productCreate.Currency) {
+		validationError.add("currency", CodeUnsupportedCurrency, fmt.Errorf("%w %q", ErrUnsupportedCurrency, productCreate.Currency))
+	}
+
+	validationError.addName("store", productCreate.Store, "store name is required")
+	if discountErr := validateDiscount(productCreate.Discount, maxDiscount); discountErr != nil {
+		validationError.add("discount", CodeDiscountOutOfRange, discountErr)
+	} else if productCreate.Price.Sign() > 0 {
+		validationError.add("discount", CodeFinalPriceNotPositive, validateFinalPrice(productCreate.Price, productCreate.Discount))
+	}
+
+	if productCreate.Status != "" {
+		validationError.add("status", CodeInvalidStatus, ValidateStatus(productCreate.Status))
+	}
+
+	if productCreate.Stock != nil && *productCreate.Stock < 0 {
+		validationError.add("stock", CodeNegativeStock, ErrNegativeStock)
+	}
+
+	if productCreate.CategoryID < 0 {
+		validationError.add("category_id", CodeNegativeCategoryId, ErrNegativeCategoryId)
+	}
+
+	return validationError.orNil()
+}
+
+// Validate checks the fields present in the patch; absent fields are not validated.
+func (productPatch ProductPatch) Validate(maxDiscount decimal.Decimal) error {
+	validationError := &ValidationError{}
+	if productPatch.Name != nil {
+		validationError.addName("name", *productPatch.Name, "product name is required")
+	}
+
+	if productPatch.Slug != nil {
+		validationError.add("slug", CodeInvalidSlug, ValidateSlug(*productPatch.Slug))
+	}
+
+	if productPatch.Price != nil && productPatch.Price.Sign() <= 0 {
+		validationError.add("price", CodePriceNotPositive, errPriceNotPositive)
+	}
+
+	if productPatch.Store != nil {
+		validationError.addName("store", *productPatch.Store, "store name is required")
+	}
+
+	if productPatch.Discount != nil {
+		validationError.add("discount", CodeDiscountOutOfRange, validateDiscount(*productPatch.Discount, maxDiscount))
+	}
+
+	if productPatch.Stock != nil && *productPatch.Stock < 0 {
+		validationError.add("stock", CodeNegativeStock, ErrNegativeStock)
+	}
+
+	if productPatch.CategoryID != nil && *productPatch.CategoryID < 0 {
+		validationError.add("category_id", CodeNegativeCategoryId, ErrNegativeCategoryId)
+	}
+
+	return validationError.orNil()
+}
+
+// ValidateFinalPrice rejects a price and discount whose discounted price, rounded to
+// cents, is zero or less, such as a 70 percent discount on 0.01. A failure is returned
+// as a ValidationError on the discount field.
+func ValidateFinalPrice(price decimal.Decimal, discount decimal.Decimal) error {
+	validationError := &ValidationError{}
+	validationError.add("discount", CodeFinalPriceNotPositive, validateFinalPrice(price, discount))
+	return validationError.orNil()
+}
+
+func validateFinalPrice(price decimal.Decimal, discount decimal.Decimal) error {
+	if finalPrice := price.ApplyDiscount(discount); finalPrice.Sign() <= 0 {
+		return fmt.Errorf("%w: a %s percent discount on %s leaves %s", ErrFinalPriceNotPositive, discount, price, finalPrice)
+	}
+	return nil
+}
+
+func validateDiscount(discount decimal.Decimal, maxDiscount decimal.Decimal) error {
+	if discount.Sign() < 0 || discount.Cmp(maxDiscount) > 0 {
+		return fmt.Errorf("discount must be between 0 and %s percent", maxDiscount)
+	}
+	return nil
+}
@@ -2,104 +2,796 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"product-app/common/decimal"
 	"product-app/domain"
 	"product-app/persistence"
 	"product-app/service/model"
-	"regexp"
+	"strings"
+	"time"
 )
 
 type IProductService interface {
-	GetProductsByCategoryId(categoryId int64) ([]domain.Product, error)
-	Add(productCreate model.ProductCreate) error
+	GetProductsByCategoryId(categoryId int64, priceRange model.PriceRange, pageRequest model.PageRequest) ([]domain.Product, int64, error)
+	Add(productCreate model.ProductCreate) (int64, error)
 	DeleteById(productId int64) error
+	DeleteByIds(productIds []int64, dryRun bool) (deleted int64, notFoundIds []int64, err error)
 	GetById(productId int64) (domain.Product, error)
-	UpdatePrice(productId int64, newPrice float32) error
+	// GetCurrentById reads the product from the primary, for answering with it right
+	// after a write.
+	GetCurrentById(productId int64) (domain.Product, error)
+	// GetRating returns the product's average rating and review count, which product
+	// reads leave out.
+	GetRating(productId int64) (domain.ProductRating, error)
+	GetByIds(productIds []int64) ([]domain.Product, error)
+	GetAvailability(productId int64) (domain.Availability, error)
+	GetByIdWithCategory(productId int64) (domain.ProductWithCategory, error)
+	GetBySlug(slug string) (domain.Product, error)
+	UpdatePrice(productId int64, newPrice decimal.Decimal, version int, changedBy int64) error
+	GetPriceHistory(productId int64) ([]domain.PriceChange, error)
+	UpdateProductPartial(productId int64, patch model.ProductPatch, changedBy int64) error
+	UpdateProductCategory(productId int64, categoryId int64) error
+	ClearProductCategory(productId int64) error
+	UpdateProductStatus(productId int64, status string) error
+	SetFeatured(productId int64, featured bool) error
+	GetFeaturedProducts() ([]domain.Product, error)
 	GetAllProducts() []domain.Product
+	GetProductsByStatus(status string) ([]domain.Product, error)
+	// Deprecated: GetAllProductsByStore returns every product of the store without a
+	// total; use GetProductsByStore.
 	GetAllProductsByStore(storeName string) []domain.Product
+	GetProductsByStore(storeName string, pageRequest model.PageRequest) ([]domain.Product, int64, error)
+	GetProductsByUser(userId int64, pageRequest model.PageRequest) ([]domain.Product, int64, error)
+	FindProducts(filter model.ProductFilter) ([]domain.Product, int64, error)
+	GetProductsAfter(storeName string, afterId int64, limit int) ([]domain.Product, *int64, error)
+	GetAllProductsByTag(tag string) ([]domain.Product, error)
+	GetProductsCreatedBetween(from time.Time, to time.Time) ([]domain.Product, error)
+	GetProductChanges(after model.ChangeCursor, limit int) ([]domain.ProductChange, *model.ChangeCursor, error)
+	GetRelatedProducts(productId int64, limit int) ([]domain.Product, error)
+	GetNewestProducts(limit int) ([]domain.Product, error)
+	GetPriceStatsByCategory(categoryId int64, currency string) (domain.PriceStats, error)
+	CountProductsByCategory(categoryId int64) (int64, error)
+	GetProductStats(currency string) (domain.ProductStats, error)
+	SearchProducts(query string, limit int) ([]domain.Product, error)
+	AttachTags(productId int64, tags []string) error
+	AddProductImages(productId int64, images []model.ImageCreate) error
+	GetProductImages(productId int64) ([]domain.ProductImage, error)
+	NormalizeImageOrder(productId int64) ([]domain.ProductImage, error)
+	NormalizeAllImageOrders() (int64, error)
+	DetachTag(productId int64, tag string) error
 	DeleteAllProducts() error
 }
 
+var (
+	ErrEmptyProductPatch  = errors.New("at least one field must be provided for update")
+	ErrEmptyTag           = errors.New("tag must not be empty")
+	ErrEmptyIdList        = errors.New("at least one product id must be provided")
+	ErrInvalidPageRequest = errors.New("invalid pagination parameters")
+	ErrEmptySearchQuery   = errors.New("search query must not be empty")
+	ErrSlugTaken          = errors.New("slug is already in use")
+	ErrInvalidPriceRange  = errors.New("invalid price range")
+	ErrInvalidDateRange   = errors.New("invalid date range")
+)
+
+const MaxRelatedProducts = 20
+
+// DefaultMaxDiscount is the discount ceiling, in percent, used when none is configured.
+var DefaultMaxDiscount = decimal.NewFromInt(70)
+
+// DefaultMaxNewestProducts caps the newest products listing when no maximum is configured.
+const DefaultMaxNewestProducts = 50
+
+// DefaultMaxFeaturedProducts caps the featured listing when no maximum is configured.
+const DefaultMaxFeaturedProducts = 12
+
+// DefaultCurrency is the ISO 4217 code given to new products that do not name one.
+const DefaultCurrency = "TRY"
+
+// DefaultMaxDescriptionLength is the longest product description, in characters, when none is configured.
+const DefaultMaxDescriptionLength = 2000
+
+// ProductSettings holds the configurable product rules.
+type ProductSettings struct {
+	MaxDiscount          decimal.Decimal
+	DefaultCurrency      string
+	MaxNewestProducts    int
+	MaxFeaturedProducts  int
+	MaxDescriptionLength int
+	// RejectDuplicateImageUrls fails a create or image add that names the same url twice;
+	// otherwise repeats are dropped and the first occurrence is kept.
+	RejectDuplicateImageUrls bool
+	// UncategorizedCategoryId is assigned to products created without a category;
+	// zero leaves them without one.
+	UncategorizedCategoryId int64
+}
+
+var DefaultProductSettings = ProductSettings{
+	MaxDiscount:          DefaultMaxDiscount,
+	DefaultCurrency:      DefaultCurrency,
+	MaxNewestProducts:    DefaultMaxNewestProducts,
+	MaxFeaturedProducts:  DefaultMaxFeaturedProducts,
+	MaxDescriptionLength: DefaultMaxDescriptionLength,
+}
+
 type ProductService struct {
-	productRepository persistence.IProductRepository
+	productRepository        persistence.IProductRepository
+	maxDiscount              decimal.Decimal
+	defaultCurrency          string
+	maxNewestProducts        int
+	maxFeaturedProducts      int
+	maxDescriptionLength     int
+	rejectDuplicateImageUrls bool
+	uncategorizedCategoryId  int64
 }
 
-func NewProductService(productRepository persistence.IProductRepository) IProductService {
+func NewProductService(productRepository persistence.IProductRepository, settings ProductSettings) IProductService {
 	return &ProductService{
-		productRepository: productRepository,
+		productRepository:        productRepository,
+		maxDiscount:              settings.MaxDiscount,
+		defaultCurrency:          settings.DefaultCurrency,
+		maxNewestProducts:        settings.MaxNewestProducts,
+		maxFeaturedProducts:      settings.MaxFeaturedProducts,
+		maxDescriptionLength:     settings.MaxDescriptionLength,
+		rejectDuplicateImageUrls: settings.RejectDuplicateImageUrls,
+		uncategorizedCategoryId:  settings.UncategorizedCategoryId,
 	}
 }
-func (productService *ProductService) Add(productCreate model.ProductCreate) error {
-	validateError := validateProductCreate(productCreate)
+func (productService *ProductService) Add(productCreate model.ProductCreate) (int64, error) {
+	if productCreate.Currency == "" {
+		productCreate.Currency = productService.defaultCurrency
+	}
+	if productCreate.CategoryID == 0 {
+		productCreate.CategoryID = productService.uncategorizedCategoryId
+	}
+	if productCreate.Status == "" {
+		productCreate.Status = domain.ProductStatusActive
+	}
+	validateError := model.JoinValidationErrors(
+		productCreate.Validate(productService.maxDiscount),
+		model.ValidateDescription(productCreate.Description, productService.maxDescriptionLength),
+	)
+	if productService.rejectDuplicateImageUrls {
+		validateError = model.JoinValidationErrors(validateError, model.ValidateUniqueImageUrls(productCreate.ImageUrls))
+	} else {
+		productCreate.ImageUrls = model.DedupeImageUrls(productCreate.ImageUrls)
+	}
 	if validateError != nil {
-		return validateError
+		return 0, validateError
+	}
+	if err := productService.ensureCategoryExists(productCreate.CategoryID); err != nil {
+		return 0, err
+	}
+	slug, err := productService.slugForNewProduct(productCreate)
+	if err != nil {
+		return 0, err
 	}
 	return productService.productRepository.AddProduct(domain.Product{
 		Name:        productCreate.Name,
+		Slug:        slug,
 		Price:       productCreate.Price,
+		Currency:    productCreate.Currency,
 		Description: productCreate.Description,
 		Discount:    productCreate.Discount,
 		Store:       productCreate.Store,
 		ImageUrls:   productCreate.ImageUrls,
 		CategoryID:  productCreate.CategoryID,
+		Tags:        normalizeTags(productCreate.Tags),
+		Status:      productCreate.Status,
+		Stock:       productCreate.Stock,
+		UserId:      productCreate.UserId,
 	})
 
 }
 func (productService *ProductService) DeleteById(productId int64) error {
 	return productService.productRepository.DeleteById(productId)
 }
+func (productService *ProductService) DeleteByIds(productIds []int64, dryRun bool) (int64, []int64, error) {
+	if len(productIds) == 0 {
+		return 0, nil, ErrEmptyIdList
+	}
+	return productService.productRepository.DeleteByIds(productIds, dryRun)
+}
 func (productService *ProductService) GetById(productId int64) (domain.Product, error) {
 	return productService.productRepository.GetById(productId)
 }
-func (productService *ProductService) UpdatePrice(productId int64, newPrice float32) error {
-	return productService.productRepository.UpdatePrice(productId, newPrice)
+func (productService *ProductService) GetCurrentById(productId int64) (domain.Product, error) {
+	return productService.productRepository.GetCurrentById(productId)
+}
+func (productService *ProductService) GetRating(productId int64) (domain.ProductRating, error) {
+	return productService.productRepository.GetRating(productId)
+}
+
+// GetAvailability tells whether a product can be bought right now, see
+// domain.Product.Availability.
+func (productService *ProductService) GetAvailability(productId int64) (domain.Availability, error) {
+	product, err := productService.productRepository.GetById(productId)
+	if err != nil {
+		return domain.Availability{}, err
+	}
+	return product.Availability(), nil
+}
+
+// GetByIds returns the active products with the given ids in the order the ids were
+// supplied. Unknown and inactive ids are skipped, and a repeated id is listed once.
+func (productService *ProductService) GetByIds(productIds []int64) ([]domain.Product, error) {
+	if len(productIds) == 0 {
+		return nil, ErrEmptyIdList
+	}
+	products, _, err := productService.productRepository.Find(model.ProductFilter{Ids: productIds})
+	if err != nil {
+		return nil, err
+	}
+	productsById := make(map[int64]domain.Product, len(products))
+	for _, product := range products {
+		productsById[product.Id] = product
+	}
+
+	ordered := make([]domain.Product, 0, len(products))
+	for _, productId := range productIds {
+		if product, ok := productsById[productId]; ok {
+			ordered = append(ordered, product)
+			delete(productsById, productId)
+		}
+	}
+	return ordered, nil
+}
+func (productService *ProductService) GetBySlug(slug string) (domain.Product, error) {
+	return productService.productRepository.GetBySlug(slug)
+}
+func (productService *ProductService) GetByIdWithCategory(productId int64) (domain.ProductWithCategory, error) {
+	return productService.productRepository.GetByIdWithCategory(productId)
+}
+
+// UpdatePrice rejects a price that the product's current discount would reduce to zero.
+func (productService *ProductService) UpdatePrice(productId int64, newPrice decimal.Decimal, version int, changedBy int64) error {
+	product, err := productService.productRepository.GetCurrentById(productId)
+	if err != nil {
+		return err
+	}
+	if err := model.ValidateFinalPrice(newPrice, product.Discount); err != nil {
+		return err
+	}
+	return productService.productRepository.UpdatePrice(productId, newPrice, version, changedBy)
+}
+
+// GetPriceHistory returns a product's price changes, most recent first.
+func (productService *ProductService) GetPriceHistory(productId int64) ([]domain.PriceChange, error) {
+	if _, err := productService.productRepository.GetById(productId); err != nil {
+		return nil, err
+	}
+	return productService.productRepository.GetPriceHistory(productId)
+}
+func (productService *ProductService) UpdateProductPartial(productId int64, patch model.ProductPatch, changedBy int64) error {
+	if patch.IsEmpty() {
+		return ErrEmptyProductPatch
+	}
+	validateError := patch.Validate(productService.maxDiscount)
+	if patch.Description != nil {
+		validateError = model.JoinValidationErrors(validateError,
+			model.ValidateDescription(*patch.Description, productService.maxDescriptionLength))
+	}
+	if validateError != nil {
+		return validateError
+	}
+	if patch.Price != nil || patch.Discount != nil {
+		if err := productService.ensureFinalPricePositive(productId, patch); err != nil {
+			return err
+		}
+	}
+	if patch.CategoryID != nil && *patch.CategoryID == 0 {
+		// 0 means uncategorized, as on create
+		patch.CategoryID = &productService.uncategorizedCategoryId
+	}
+	if patch.CategoryID != nil {
+		if err := productService.ensureCategoryExists(*patch.CategoryID); err != nil {
+			return err
+		}
+	}
+	if patch.Slug != nil {
+		if err := productService.ensureSlugAvailable(*patch.Slug, productId); err != nil {
+			return err
+		}
+	}
+	return productService.productRepository.UpdateProductPartial(productId, patch, changedBy)
+}
+
+// UpdateProductCategory moves a product to an existing category without touching its other fields.
+func (productService *ProductService) UpdateProductCategory(productId int64, categoryId int64) error {
+	if err := productService.ensureCategoryExists(categoryId); err != nil {
+		return err
+	}
+	return productService.productRepository.UpdateProductCategory(productId, categoryId)
+}
+
+// ClearProductCategory takes a product out of its category, moving it to the configured
+// Uncategorized category or, without one, leaving it with no category at all.
+func (productService *ProductService) ClearProductCategory(productId int64) error {
+	return productService.productRepository.UpdateProductCategory(productId, productService.uncategorizedCategoryId)
+}
+
+// UpdateProductStatus moves a product through its lifecycle. Any transition is allowed,
+// so a discontinued product can be reactivated.
+func (productService *ProductService) UpdateProductStatus(productId int64, status string) error {
+	if err := model.ValidateStatus(status); err != nil {
+		return err
+	}
+	return productService.productRepository.UpdateProductStatus(productId, status)
+}
+
+// ensureFinalPricePositive checks the discounted price the patch would leave, taking
+// the price or discount it does not change from the stored product.
+func (productService *ProductService) ensureFinalPricePositive(productId int64, patch model.ProductPatch) error {
+	product, err := productService.productRepository.GetCurrentById(productId)
+	if err != nil {
+		return err
+	}
+	price, discount := product.Price, product.Discount
+	if patch.Price != nil {
+		price = *patch.Price
+	}
+	if patch.Discount != nil {
+		discount = *patch.Discount
+	}
+	return model.ValidateFinalPrice(price, discount)
+}
+
+// slugForNewProduct returns the requested slug if it is free. Without one, the slug is
+// generated from the name and suffixed with -2, -3, ... until it is unique.
+func (productService *ProductService) slugForNewProduct(productCreate model.ProductCreate) (string, error) {
+	if productCreate.Slug != "" {
+		return productCreate.Slug, productService.ensureSlugAvailable(productCreate.Slug, 0)
+	}
+	return uniqueSlug(generateSlug(productCreate.Name, "product"), func(slug string) (bool, error) {
+		_, err := productService.productRepository.GetCurrentBySlug(slug)
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+// ensureSlugAvailable rejects slugs used by a product other than productId.
+func (productService *ProductService) ensureSlugAvailable(slug string, productId int64) error {
+	existing, err := productService.productRepository.GetCurrentBySlug(slug)
+	if errors.Is(err, domain.ErrProductNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.Id != productId {
+		return fmt.Errorf("%w: %s", ErrSlugTaken, slug)
+	}
+	return nil
+}
+
+// ensureCategoryExists rejects references to missing categories. A zero id means
+// the product is uncategorized and is always accepted.
+func (productService *ProductService) ensureCategoryExists(categoryId int64) error {
+	if categoryId == 0 {
+		return nil
+	}
+	exists, err := productService.productRepository.CategoryExists(categoryId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w with id %d", domain.ErrCategoryNotFound, categoryId)
+	}
+	return nil
 }
 func (productService *ProductService) GetAllProducts() []domain.Product {
 	return productService.productRepository.GettAllProducts()
 }
 
+// GetProductsByStatus lists the products with the given status, or all products for
+// model.ProductStatusAll. It backs the admin view of the catalog.
+func (productService *ProductService) GetProductsByStatus(status string) ([]domain.Product, error) {
+	if status != model.ProductStatusAll {
+		if err := model.ValidateStatus(status); err != nil {
+			return nil, err
+		}
+	}
+	products, _, err := productService.productRepository.Find(model.ProductFilter{Status: status})
+	return products, err
+}
+
+// Deprecated: GetAllProductsByStore is unpaginated and reports no total; use
+// GetProductsByStore.
 func (productService *ProductService) GetAllProductsByStore(storeName string) []domain.Product {
 	return productService.productRepository.GetAllProductsByStore(storeName)
 }
 
-func (productService *ProductService) DeleteAllProducts() error {
-	return productService.productRepository.DeleteAllProducts()
+// GetProductsByStore lists a page of the store's active products along with how many
+// the store has in total, so clients can render "showing 20 of 340".
+func (productService *ProductService) GetProductsByStore(storeName string, pageRequest model.PageRequest) ([]domain.Product, int64, error) {
+	return productService.FindProducts(model.ProductFilter{Store: storeName, PageRequest: pageRequest})
 }
 
-func (productService *ProductService) GetProductsByCategoryId(categoryId int64) ([]domain.Product, error) {
-	if categoryId <= 0 {
-		return nil, errors.New("category ID must be a positive integer")
+// GetProductsByUser lists a page of the products the user created, in every status so
+// owners also see their drafts, along with how many they have in total.
+func (productService *ProductService) GetProductsByUser(userId int64, pageRequest model.PageRequest) ([]domain.Product, int64, error) {
+	return productService.FindProducts(model.ProductFilter{UserId: userId, Status: model.ProductStatusAll, PageRequest: pageRequest})
+}
+
+// FindProducts lists a page of products matching every criterion of the filter in a
+// single query, together with the number of matches across all pages. The search text
+// is trimmed; blank text, like any other zero value, leaves its criterion out.
+func (productService *ProductService) FindProducts(filter model.ProductFilter) ([]domain.Product, int64, error) {
+	if err := validatePageRequest(filter.PageRequest); err != nil {
+		return nil, 0, err
 	}
-	return productService.productRepository.GetProductsByCategoryId(categoryId)
+	filter.Search = strings.TrimSpace(filter.Search)
+	return productService.productRepository.Find(filter)
 }
 
-func validateProductCreate(productCreate model.ProductCreate) error {
-	if err := validateNameWithRegex(productCreate.Name, "product name is required"); err != nil {
-		return err
+// GetProductsAfter is the keyset alternative to offset pages: it lists up to limit active
+// products with an id above afterId, in id order and optionally from one store, and
+// returns the id to continue after, or nil on the last page. Inserts elsewhere in the
+// catalog never shift or repeat rows between pages.
+func (productService *ProductService) GetProductsAfter(storeName string, afterId int64, limit int) ([]domain.Product, *int64, error) {
+	if limit < 1 {
+		return nil, nil, fmt.Errorf("%w: limit must be a positive integer", ErrInvalidPageRequest)
+	}
+	if afterId < 0 {
+		return nil, nil, fmt.Errorf("%w: cursor must not be negative", ErrInvalidPageRequest)
 	}
+	// One extra row tells whether another page follows without counting
+	products, _, err := productService.productRepository.Find(model.ProductFilter{
+		Store:       storeName,
+		AfterId:     &afterId,
+		PageRequest: model.PageRequest{Limit: limit + 1},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(products) <= limit {
+		return products, nil, nil
+	}
+	products = products[:limit]
+	next := products[limit-1].Id
+	return products, &next, nil
+}
 
-	if productCreate.Price <= 0 {
-		return errors.New("product price must be greater than zero")
+// GetRelatedProducts returns other products from the same category, highest discount
+// first. Uncategorized products have no related products.
+func (productService *ProductService) GetRelatedProducts(productId int64, limit int) ([]domain.Product, error) {
+	if limit < 1 || limit > MaxRelatedProducts {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidPageRequest, MaxRelatedProducts)
 	}
 
-	if err := validateNameWithRegex(productCreate.Store, "store name is required"); err != nil {
+	product, err := productService.productRepository.GetById(productId)
+	if err != nil {
+		return nil, err
+	}
+	if product.CategoryID == 0 {
+		return []domain.Product{}, nil
+	}
+
+	// Fetch one extra row so the current product can be dropped without shrinking the result
+	siblings, _, err := productService.productRepository.Find(model.ProductFilter{
+		CategoryId:  product.CategoryID,
+		PageRequest: model.PageRequest{Limit: limit + 1, Sort: "discount_desc"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	related := []domain.Product{}
+	for _, sibling := range siblings {
+		if sibling.Id != productId && len(related) < limit {
+			related = append(related, sibling)
+		}
+	}
+	return related, nil
+}
+
+// GetNewestProducts returns the most recently added products, newest first. Limits
+// above the configured maximum are lowered to it.
+func (productService *ProductService) GetNewestProducts(limit int) ([]domain.Product, error) {
+	if limit < 1 {
+		return nil, fmt.Errorf("%w: limit must be a positive integer", ErrInvalidPageRequest)
+	}
+	if limit > productService.maxNewestProducts {
+		limit = productService.maxNewestProducts
+	}
+	products, _, err := productService.productRepository.Find(model.ProductFilter{
+		PageRequest: model.PageRequest{Limit: limit, Sort: "newest"},
+	})
+	return products, err
+}
+
+// GetFeaturedProducts lists the active featured products, newest first, up to the
+// configured maximum.
+func (productService *ProductService) GetFeaturedProducts() ([]domain.Product, error) {
+	products, _, err := productService.productRepository.Find(model.ProductFilter{
+		Featured:    true,
+		PageRequest: model.PageRequest{Limit: productService.maxFeaturedProducts, Sort: "newest"},
+	})
+	return products, err
+}
+
+// SetFeatured flags or unflags a product for the featured listing. Only active
+// products are listed, so a flagged draft appears once it is activated.
+func (productService *ProductService) SetFeatured(productId int64, featured bool) error {
+	return productService.productRepository.SetFeatured(productId, featured)
+}
+
+// GetProductsCreatedBetween returns the products created between from and to, both inclusive.
+func (productService *ProductService) GetProductsCreatedBetween(from time.Time, to time.Time) ([]domain.Product, error) {
+	if from.After(to) {
+		return nil, fmt.Errorf("%w: createdFrom must not be after createdTo", ErrInvalidDateRange)
+	}
+	return productService.productRepository.GetProductsCreatedBetween(from, to)
+}
+
+// GetProductChanges returns up to limit changes after the cursor for clients syncing the
+// catalog, oldest first, with the cursor of the next page or nil on the last one.
+// Products that were deleted or are no longer active come back flagged as deleted, so
+// clients drop them just as the public listings do.
+func (productService *ProductService) GetProductChanges(after model.ChangeCursor, limit int) ([]domain.ProductChange, *model.ChangeCursor, error) {
+	if limit < 1 {
+		return nil, nil, fmt.Errorf("%w: limit must be a positive integer", ErrInvalidPageRequest)
+	}
+	changes, err := productService.productRepository.GetProductChanges(after, limit+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	var next *model.ChangeCursor
+	if len(changes) > limit {
+		changes = changes[:limit]
+		last := changes[limit-1]
+		next = &model.ChangeCursor{ChangedAt: last.ChangedAt, ProductId: last.ProductId}
+	}
+
+	var changedIds []int64
+	for _, change := range changes {
+		if !change.Deleted {
+			changedIds = append(changedIds, change.ProductId)
+		}
+	}
+	productsById := make(map[int64]domain.Product, len(changedIds))
+	if len(changedIds) > 0 {
+		// Read from the primary: a product the replica has not caught up on would be
+		// reported as deleted while the cursor moves past its change
+		products, err := productService.productRepository.GetCurrentByIds(changedIds)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, product := range products {
+			productsById[product.Id] = product
+		}
+	}
+
+	for i, change := range changes {
+		if change.Deleted {
+			continue
+		}
+		// A product deleted between the two queries is missing here
+		product, ok := productsById[change.ProductId]
+		if !ok || product.Status != domain.ProductStatusActive {
+			changes[i].Deleted = true
+			continue
+		}
+		changes[i].Product = &product
+	}
+	return changes, next, nil
+}
+
+// SearchProducts matches the query against product names and descriptions.
+func (productService *ProductService) SearchProducts(query string, limit int) ([]domain.Product, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrEmptySearchQuery
+	}
+	products, _, err := productService.productRepository.Find(model.ProductFilter{
+		Search:      query,
+		PageRequest: model.PageRequest{Limit: limit},
+	})
+	return products, err
+}
+
+// GetPriceStatsByCategory aggregates the category's prices in currency, or in the
+// default currency when none is given.
+func (productService *ProductService) GetPriceStatsByCategory(categoryId int64, currency string) (domain.PriceStats, error) {
+	currency, err := productService.statsCurrency(currency)
+	if err != nil {
+		return domain.PriceStats{}, err
+	}
+	exists, err := productService.productRepository.CategoryExists(categoryId)
+	if err != nil {
+		return domain.PriceStats{}, err
+	}
+	if !exists {
+		return domain.PriceStats{}, fmt.Errorf("%w with id %d", domain.ErrCategoryNotFound, categoryId)
+	}
+	return productService.productRepository.GetPriceStatsByCategory(categoryId, currency)
+}
+
+// CountProductsByCategory counts the products GetProductsByCategoryId would list, so an
+// empty category yields 0 and an unknown one ErrCategoryNotFound.
+func (productService *ProductService) CountProductsByCategory(categoryId int64) (int64, error) {
+	exists, err := productService.productRepository.CategoryExists(categoryId)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, fmt.Errorf("%w with id %d", domain.ErrCategoryNotFound, categoryId)
+	}
+	return productService.productRepository.CountProductsByCategory(categoryId)
+}
+
+// GetProductStats averages prices in currency, or in the default currency when none is given.
+func (productService *ProductService) GetProductStats(currency string) (domain.ProductStats, error) {
+	currency, err := productService.statsCurrency(currency)
+	if err != nil {
+		return domain.ProductStats{}, err
+	}
+	return productService.productRepository.GetProductStats(currency)
+}
+
+// statsCurrency resolves the currency price aggregates are computed in.
+func (productService *ProductService) statsCurrency(currency string) (string, error) {
+	if currency == "" {
+		return productService.defaultCurrency, nil
+	}
+	if !model.IsSupportedCurrency(currency) {
+		return "", fmt.Errorf("%w %q", model.ErrUnsupportedCurrency, currency)
+	}
+	return currency, nil
+}
+
+func (productService *ProductService) GetAllProductsByTag(tag string) ([]domain.Product, error) {
+	normalizedTag := normalizeTag(tag)
+	if normalizedTag == "" {
+		return nil, ErrEmptyTag
+	}
+	return productService.productRepository.GetAllProductsByTag(normalizedTag), nil
+}
+
+// AddProductImages validates the images and appends them to the product's gallery. An
+// url listed twice is rejected or dropped, as for a new product.
+func (productService *ProductService) AddProductImages(productId int64, images []model.ImageCreate) error {
+	if len(images) == 0 {
+		return fmt.Errorf("%w: at least one image must be provided", model.ErrInvalidImage)
+	}
+	urls := make([]string, len(images))
+	for i, image := range images {
+		if err := image.Validate(); err != nil {
+			return err
+		}
+		urls[i] = image.Url
+	}
+	if url, found := model.FindDuplicateUrl(urls); found && productService.rejectDuplicateImageUrls {
+		return fmt.Errorf("%w: %w %q", model.ErrInvalidImage, model.ErrDuplicateImageUrl, url)
+	}
+	added := make(map[string]bool, len(images))
+	productImages := make([]domain.ProductImage, 0, len(images))
+	for _, image := range images {
+		if added[image.Url] {
+			continue
+		}
+		added[image.Url] = true
+		productImages = append(productImages, domain.ProductImage{Url: image.Url, Width: image.Width, Height: image.Height, MimeType: image.MimeType})
+	}
+	if _, err := productService.productRepository.GetCurrentById(productId); err != nil {
 		return err
 	}
+	err := productService.productRepository.AddProductImages(productId, productImages)
+	if errors.Is(err, persistence.ErrTooManyImages) || errors.Is(err, persistence.ErrImageUrlTooLong) {
+		return fmt.Errorf("%w: %w", model.ErrInvalidImage, err)
+	}
+	return err
+}
+
+// GetProductImages lists the images of a product in display order.
+func (productService *ProductService) GetProductImages(productId int64) ([]domain.ProductImage, error) {
+	return productService.productRepository.GetProductImages(productId)
+}
+
+// NormalizeImageOrder closes the gaps that removed images leave in a product's display
+// orders, keeping the images in their current order, and returns the renumbered images.
+func (productService *ProductService) NormalizeImageOrder(productId int64) ([]domain.ProductImage, error) {
+	if err := productService.productRepository.NormalizeImageOrder(productId); err != nil {
+		return nil, err
+	}
+	return productService.productRepository.GetProductImages(productId)
+}
+
+// NormalizeAllImageOrders renumbers the images of every product and reports how many
+// products needed it.
+func (productService *ProductService) NormalizeAllImageOrders() (int64, error) {
+	return productService.productRepository.NormalizeAllImageOrders()
+}
 
-	if productCreate.Discount < 0 || productCreate.Discount > 70 {
-		return errors.New("discount must be between 0 and 70 percent")
+func (productService *ProductService) AttachTags(productId int64, tags []string) error {
+	normalizedTags := normalizeTags(tags)
+	if len(normalizedTags) == 0 {
+		return ErrEmptyTag
 	}
+	return productService.productRepository.AttachTags(productId, normalizedTags)
+}
 
-	return nil
+func (productService *ProductService) DetachTag(productId int64, tag string) error {
+	normalizedTag := normalizeTag(tag)
+	if normalizedTag == "" {
+		return ErrEmptyTag
+	}
+	return productService.productRepository.DetachTag(productId, normalizedTag)
+}
+
+func (productService *ProductService) DeleteAllProducts() error {
+	return productService.productRepository.DeleteAllProducts()
+}
+
+// GetProductsByCategoryId lists a page of the category's products, optionally bounded
+// by priceRange. A range without a currency is read in the default currency.
+func (productService *ProductService) GetProductsByCategoryId(categoryId int64, priceRange model.PriceRange, pageRequest model.PageRequest) ([]domain.Product, int64, error) {
+	if categoryId <= 0 {
+		return nil, 0, errors.New("category ID must be a positive integer")
+	}
+	if err := validatePageRequest(pageRequest); err != nil {
+		return nil, 0, err
+	}
+	if priceRange.IsSet() && priceRange.Currency == "" {
+		priceRange.Currency = productService.defaultCurrency
+	}
+	if err := validatePriceRange(priceRange); err != nil {
+		return nil, 0, err
+	}
+	// An unknown category and an empty one both produce no rows, so check existence
+	// to let callers tell them apart.
+	exists, err := productService.productRepository.CategoryExists(categoryId)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !exists {
+		return nil, 0, fmt.Errorf("%w with id %d", domain.ErrCategoryNotFound, categoryId)
+	}
+	return productService.productRepository.GetProductsByCategoryId(categoryId, priceRange, pageRequest)
 }
 
-func validateNameWithRegex(name string, errorMessage string) error {
-	if name == "" {
-		return errors.New(errorMessage)
+func validatePageRequest(pageRequest model.PageRequest) error {
+	if pageRequest.Limit < 0 || pageRequest.Offset < 0 {
+		return fmt.Errorf("%w: limit and offset must not be negative", ErrInvalidPageRequest)
+	}
+	if !persistence.IsValidProductSort(pageRequest.Sort) {
+		return fmt.Errorf("%w: unsupported sort %q", ErrInvalidPageRequest, pageRequest.Sort)
 	}
+	return nil
+}
 
-	regex := regexp.MustCompile(`^[\p{L}\p{N}\s]+$`)
-	if !regex.MatchString(name) {
-		return errors.New("contains invalid characters (only alphanumeric and space allowed)")
+func validatePriceRange(priceRange model.PriceRange) error {
+	if (priceRange.Min != nil && priceRange.Min.Sign() < 0) || (priceRange.Max != nil && priceRange.Max.Sign() < 0) {
+		return fmt.Errorf("%w: prices must not be negative", ErrInvalidPriceRange)
+	}
+	if priceRange.Min != nil && priceRange.Max != nil && priceRange.Min.Cmp(*priceRange.Max) > 0 {
+		return fmt.Errorf("%w: minimum price must not exceed the maximum price", ErrInvalidPriceRange)
+	}
+	if priceRange.Currency != "" && !model.IsSupportedCurrency(priceRange.Currency) {
+		return fmt.Errorf("%w %q", model.ErrUnsupportedCurrency, priceRange.Currency)
 	}
 	return nil
 }
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// normalizeTags lowercases and trims tags, dropping empty entries and duplicates.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	var normalizedTags []string
+	for _, tag := range tags {
+		normalizedTag := normalizeTag(tag)
+		if normalizedTag == "" || seen[normalizedTag] {
+			continue
+		}
+		seen[normalizedTag] = true
+		normalizedTags = append(normalizedTags, normalizedTag)
+	}
+	return normalizedTags
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"product-app/domain"
+	"product-app/persistence"
+	"time"
+)
+
+type IReviewService interface {
+	AddReview(productId int64, userId int64, rating int, comment string) (domain.Review, error)
+	GetReviewsByProduct(productId int64) ([]domain.Review, error)
+	GetAverageRating(productId int64) (float64, error)
+}
+
+type ReviewService struct {
+	reviewRepository  persistence.IReviewRepository
+	productRepository persistence.IProductRepository
+}
+
+func NewReviewService(reviewRepository persistence.IReviewRepository, productRepository persistence.IProductRepository) IReviewService {
+	return &ReviewService{
+		reviewRepository:  reviewRepository,
+		productRepository: productRepository,
+	}
+}
+
+func (reviewService *ReviewService) AddReview(productId int64, userId int64, rating int, comment string) (domain.Review, error) {
+	if rating < 1 || rating > 5 {
+		return domain.Review{}, errors.New("rating must be between 1 and 5")
+	}
+
+	if _, err := reviewService.productRepository.GetCurrentById(productId); err != nil {
+		return domain.Review{}, err
+	}
+
+	review := domain.Review{
+		ProductId: productId,
+		UserId:    userId,
+		Rating:    rating,
+		Comment:   comment,
+		CreatedAt: time.Now(),
+	}
+	reviewId, err := reviewService.reviewRepository.AddReview(review)
+	if err != nil {
+		return domain.Review{}, err
+	}
+	review.Id = reviewId
+	return review, nil
+}
+
+func (reviewService *ReviewService) GetReviewsByProduct(productId int64) ([]domain.Review, error) {
+	if _, err := reviewService.productRepository.GetById(productId); err != nil {
+		return nil, err
+	}
+	return reviewService.reviewRepository.GetReviewsByProduct(productId)
+}
+
+func (reviewService *ReviewService) GetAverageRating(productId int64) (float64, error) {
+	return reviewService.reviewRepository.GetAverageRating(productId)
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var slugSeparatorRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// turkishTransliterator maps Turkish letters to their ASCII base letters. İ is mapped
+// before lowercasing, since strings.ToLower turns it into i with a combining dot.
+var turkishTransliterator = strings.NewReplacer(
+	"ç", "c", "Ç", "c",
+	"ğ", "g", "Ğ", "g",
+	"ı", "i", "İ", "i",
+	"ö", "o", "Ö", "o",
+	"ş", "s", "Ş", "s",
+	"ü", "u", "Ü", "u",
+)
+
+// generateSlug lowercases name, transliterates Turkish letters and joins its
+// alphanumeric runs with hyphens. Names without any ASCII letters or digits get the fallback.
+func generateSlug(name string, fallback string) string {
+	lowered := strings.ToLower(turkishTransliterator.Replace(name))
+	slug := strings.Trim(slugSeparatorRegex.ReplaceAllString(lowered, "-"), "-")
+	if slug == "" {
+		return fallback
+	}
+	return slug
+}
+
+// uniqueSlug returns baseSlug, or baseSlug suffixed with -2, -3, ..., whichever is the
+// first candidate that isTaken reports as free.
+func uniqueSlug(baseSlug string, isTaken func(slug string) (bool, error)) (string, error) {
+	candidate := baseSlug
+	for suffix := 2; ; suffix++ {
+		taken, err := isTaken(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", baseSlug, suffix)
+	}
+}
@@ -6,18 +6,36 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"product-app/common/logging"
 	"product-app/domain"
 	"product-app/persistence"
-	"regexp"
+	"product-app/service/model"
 	"strings"
 	"time"
 
 	"golang.org/x/crypto/argon2"
 )
 
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// PasswordHashParams are the Argon2id cost parameters used for new password hashes.
+// Stored hashes with a lower memory or iteration cost are upgraded on the next login.
+type PasswordHashParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+var DefaultPasswordHashParams = PasswordHashParams{
+	Memory:      64 * 1024,
+	Iterations:  1,
+	Parallelism: 4,
+}
+
 type IUserService interface {
-	Register(username, email, password, firstName, lastName string) error
+	Register(username, email, password, firstName, lastName string) (domain.User, error)
 	Login(usernameOrEmail, password string) (domain.User, error)
+	CheckAvailability(username, email string) (usernameAvailable, emailAvailable *bool, err error)
 	GetById(userId int64) (domain.User, error)
 	UpdateUser(user domain.User) error
 	DeleteById(userId int64) error
@@ -25,47 +43,60 @@ type IUserService interface {
 
 type UserService struct {
 	userRepository persistence.IUserRepository
+	hashParams     PasswordHashParams
 }
 
-func NewUserService(userRepository persistence.IUserRepository) IUserService {
+func NewUserService(userRepository persistence.IUserRepository, hashParams PasswordHashParams) IUserService {
 	return &UserService{
 		userRepository: userRepository,
+		hashParams:     hashParams,
 	}
 }
 
-func (userService *UserService) Register(username, email, password, firstName, lastName string) error {
+func (userService *UserService) Register(username, email, password, firstName, lastName string) (domain.User, error) {
+	email = normalizeEmail(email)
 	if err := validateRegistration(username, email, password, firstName, lastName); err != nil {
-		return err
+		return domain.User{}, err
 	}
 
 	// Check if username already exists
 	if _, err := userService.userRepository.GetByUsername(username); err == nil {
-		return errors.New("username already exists")
+		return domain.User{}, errors.New("username already exists")
 	}
 
-	// Check if email already exists
-	if _, err := userService.userRepository.GetByEmail(email); err == nil {
-		return errors.New("email already exists")
+	// Check if email already exists, ignoring case like login does
+	emailTaken, err := userService.userRepository.ExistsByEmail(email)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if emailTaken {
+		return domain.User{}, errors.New("email already exists")
 	}
 
 	// Hash password
-	hashedPassword, err := hashPassword(password)
+	hashedPassword, err := hashPassword(password, userService.hashParams)
 	if err != nil {
-		return fmt.Errorf("failed to hash password: %w", err)
+		return domain.User{}, fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	now := time.Now()
 	user := domain.User{
-		Username:  username,
-		Email:     email,
-		Password:  hashedPassword,
-		FirstName: firstName,
-		LastName:  lastName,
-		CreatedAt: now,
-		UpdatedAt: now,
+		Username:     username,
+		Email:        email,
+		PasswordHash: hashedPassword,
+		FirstName:    firstName,
+		LastName:     lastName,
+		Role:         domain.RoleUser,
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	}
 
-	return userService.userRepository.AddUser(user)
+	userId, err := userService.userRepository.AddUser(user)
+	if err != nil {
+		return domain.User{}, err
+	}
+	user.Id = userId
+	return user, nil
 }
 
 func (userService *UserService) Login(usernameOrEmail, password string) (domain.User, error) {
@@ -73,26 +104,70 @@ func (userService *UserService) Login(usernameOrEmail, password string) (domain.
 		return domain.User{}, errors.New("username/email and password are required")
 	}
 
-	var user domain.User
-	var err error
+	// Unknown accounts and wrong passwords get the same error so callers can't probe which accounts exist
+	user, err := userService.userRepository.GetByUsernameOrEmail(strings.TrimSpace(usernameOrEmail))
+	if err != nil {
+		return domain.User{}, ErrInvalidCredentials
+	}
+
+	// Verify password
+	if !verifyPassword(password, user.PasswordHash) {
+		return domain.User{}, ErrInvalidCredentials
+	}
+
+	if needsRehash(user.PasswordHash, userService.hashParams) {
+		userService.rehashPassword(&user, password)
+	}
 
-	// Try to find user by email first, then username
-	if strings.Contains(usernameOrEmail, "@") {
-		user, err = userService.userRepository.GetByEmail(usernameOrEmail)
+	if err := userService.userRepository.TouchLastLogin(user.Id); err != nil {
+		logging.Warn("unable to record last login", logging.Fields{"user_id": user.Id, "error": err})
 	} else {
-		user, err = userService.userRepository.GetByUsername(usernameOrEmail)
+		now := time.Now()
+		user.LastLoginAt = &now
 	}
 
+	return user, nil
+}
+
+// rehashPassword upgrades a stored hash to the configured cost. Failures are only
+// logged: the user has already been authenticated with the old hash.
+func (userService *UserService) rehashPassword(user *domain.User, password string) {
+	hashedPassword, err := hashPassword(password, userService.hashParams)
 	if err != nil {
-		return domain.User{}, errors.New("invalid credentials")
+		logging.Warn("unable to rehash password", logging.Fields{"user_id": user.Id, "error": err})
+		return
 	}
+	if err := userService.userRepository.UpdatePassword(user.Id, hashedPassword); err != nil {
+		logging.Warn("unable to store rehashed password", logging.Fields{"user_id": user.Id, "error": err})
+		return
+	}
+	user.PasswordHash = hashedPassword
+}
 
-	// Verify password
-	if !verifyPassword(password, user.Password) {
-		return domain.User{}, errors.New("invalid credentials")
+// CheckAvailability reports whether the username and email are still free. An empty
+// value is not checked and its result is nil.
+func (userService *UserService) CheckAvailability(username, email string) (*bool, *bool, error) {
+	var usernameAvailable, emailAvailable *bool
+
+	if username = strings.TrimSpace(username); username != "" {
+		exists, err := userService.userRepository.ExistsByUsername(username)
+		if err != nil {
+			return nil, nil, err
+		}
+		available := !exists
+		usernameAvailable = &available
 	}
 
-	return user, nil
+	if email = strings.TrimSpace(email); email != "" {
+		exists, err := userService.userRepository.ExistsByEmail(email)
+		if err != nil {
+			return nil, nil, err
+		}
+		available := !exists
+		emailAvailable = &available
+	}
+
+	return usernameAvailable, emailAvailable, nil
 }
 
 func (userService *UserService) GetById(userId int64) (domain.User, error) {
@@ -100,6 +175,7 @@ func (userService *UserService) GetById(userId int64) (domain.User, error) {
 }
 
 func (userService *UserService) UpdateUser(user domain.User) error {
+	user.Email = normalizeEmail(user.Email)
 	if err := validateUserUpdate(user); err != nil {
 		return err
 	}
@@ -111,8 +187,13 @@ func (userService *UserService) DeleteById(userId int64) error {
 	return userService.userRepository.DeleteById(userId)
 }
 
+// normalizeEmail lowercases email so that addresses differing only in case are one account.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 func validateRegistration(username, email, password, firstName, lastName string) error {
-	if err := validateNameWithRegex(username, "username is required"); err != nil {
+	if err := model.ValidateName(username, "username is required"); err != nil {
 		return err
 	}
 
@@ -120,7 +201,7 @@ func validateRegistration(username, email, password, firstName, lastName string)
 		return errors.New("username must be at least 3 characters long")
 	}
 
-	if err := validateEmail(email); err != nil {
+	if err := model.ValidateEmail(email); err != nil {
 		return err
 	}
 
@@ -128,11 +209,11 @@ func validateRegistration(username, email, password, firstName, lastName string)
 		return err
 	}
 
-	if err := validateNameWithRegex(firstName, "first name is required"); err != nil {
+	if err := model.ValidateName(firstName, "first name is required"); err != nil {
 		return err
 	}
 
-	if err := validateNameWithRegex(lastName, "last name is required"); err != nil {
+	if err := model.ValidateName(lastName, "last name is required"); err != nil {
 		return err
 	}
 
@@ -140,38 +221,25 @@ func validateRegistration(username, email, password, firstName, lastName string)
 }
 
 func validateUserUpdate(user domain.User) error {
-	if err := validateNameWithRegex(user.Username, "username is required"); err != nil {
+	if err := model.ValidateName(user.Username, "username is required"); err != nil {
 		return err
 	}
 
-	if err := validateEmail(user.Email); err != nil {
+	if err := model.ValidateEmail(user.Email); err != nil {
 		return err
 	}
 
-	if err := validateNameWithRegex(user.FirstName, "first name is required"); err != nil {
+	if err := model.ValidateName(user.FirstName, "first name is required"); err != nil {
 		return err
 	}
 
-	if err := validateNameWithRegex(user.LastName, "last name is required"); err != nil {
+	if err := model.ValidateName(user.LastName, "last name is required"); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func validateEmail(email string) error {
-	if email == "" {
-		return errors.New("email is required")
-	}
-
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	if !emailRegex.MatchString(email) {
-		return errors.New("invalid email format")
-	}
-
-	return nil
-}
-
 func validatePassword(password string) error {
 	if password == "" {
 		return errors.New("password is required")
@@ -185,29 +253,44 @@ func validatePassword(password string) error {
 }
 
 // Password hashing using Argon2
-func hashPassword(password string) (string, error) {
+func hashPassword(password string, params PasswordHashParams) (string, error) {
 	salt := make([]byte, 16)
 	if _, err := rand.Read(salt); err != nil {
 		return "", err
 	}
 
-	hash := argon2.IDKey([]byte(password), salt, 1, 64*1024, 4, 32)
+	hash := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, 32)
 
 	b64Salt := base64.RawStdEncoding.EncodeToString(salt)
 	b64Hash := base64.RawStdEncoding.EncodeToString(hash)
 
-	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, 64*1024, 1, 4, b64Salt, b64Hash), nil
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, params.Memory, params.Iterations, params.Parallelism, b64Salt, b64Hash), nil
 }
 
-func verifyPassword(password, hashedPassword string) bool {
+func parseHashParams(hashedPassword string) (PasswordHashParams, []string, bool) {
 	parts := strings.Split(hashedPassword, "$")
 	if len(parts) != 6 {
+		return PasswordHashParams{}, nil, false
+	}
+
+	var params PasswordHashParams
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return PasswordHashParams{}, nil, false
+	}
+	return params, parts, true
+}
+
+func needsRehash(hashedPassword string, target PasswordHashParams) bool {
+	params, _, ok := parseHashParams(hashedPassword)
+	if !ok {
 		return false
 	}
+	return params.Memory < target.Memory || params.Iterations < target.Iterations
+}
 
-	var memory, iterations uint32
-	var parallelism uint8
-	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
+func verifyPassword(password, hashedPassword string) bool {
+	params, parts, ok := parseHashParams(hashedPassword)
+	if !ok {
 		return false
 	}
 
@@ -221,7 +304,7 @@ func verifyPassword(password, hashedPassword string) bool {
 		return false
 	}
 
-	testHash := argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, uint32(len(hash)))
+	testHash := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(hash)))
 
 	return subtle.ConstantTimeCompare(hash, testHash) == 1
-}
\ No newline at end of file
+}
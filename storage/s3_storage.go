@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxPresignTTL is the longest validity S3 accepts for a signed URL.
+const MaxPresignTTL = 7 * 24 * time.Hour
+
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	amzDateFormat    = "20060102T150405Z"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+)
+
+// S3Config points at an S3-compatible bucket. Endpoint is the service root such as
+// https://s3.eu-central-1.amazonaws.com or http://localhost:9000 for MinIO; objects are
+// addressed path-style as Endpoint/Bucket/key. PublicBaseUrl is where uploaded objects
+// are served from, e.g. a CDN in front of the bucket; it defaults to Endpoint/Bucket.
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyId     string
+	SecretAccessKey string
+	PublicBaseUrl   string
+}
+
+type S3Storage struct {
+	config   S3Config
+	endpoint *url.URL
+}
+
+func NewS3Storage(config S3Config) (*S3Storage, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", config.Endpoint)
+	}
+	if config.Region == "" || config.Bucket == "" || config.AccessKeyId == "" || config.SecretAccessKey == "" {
+		return nil, errors.New("S3 region, bucket and credentials are required")
+	}
+	if config.PublicBaseUrl == "" {
+		config.PublicBaseUrl = endpoint.String() + "/" + config.Bucket
+	}
+	config.PublicBaseUrl = strings.TrimSuffix(config.PublicBaseUrl, "/")
+	return &S3Storage{config: config, endpoint: endpoint}, nil
+}
+
+func (s3Storage *S3Storage) PublicUrl(key string) string {
+	return s3Storage.config.PublicBaseUrl + "/" + encodePath(key)
+}
+
+// PresignUpload signs a PUT with AWS Signature Version 4 in the query string. The
+// content type is part of the signature, so the upload must declare the same one.
+func (s3Storage *S3Storage) PresignUpload(key string, contentType string, ttl time.Duration) (SignedUpload, error) {
+	if key == "" {
+		return SignedUpload{}, errors.New("object key is required")
+	}
+	if ttl <= 0 || ttl > MaxPresignTTL {
+		return SignedUpload{}, fmt.Errorf("signed URL validity must be between 1s and %s", MaxPresignTTL)
+	}
+
+	now := time.Now().UTC()
+	headers := map[string]string{"Content-Type": contentType}
+	signedUrl := s3Storage.presign(http.MethodPut, "/"+s3Storage.config.Bucket+"/"+key, headers, now, ttl)
+	return SignedUpload{
+		Url:       signedUrl,
+		Method:    http.MethodPut,
+		Headers:   headers,
+		ExpiresAt: now.Add(ttl).Truncate(time.Second),
+	}, nil
+}
+
+func (s3Storage *S3Storage) presign(method string, path string, headers map[string]string, now time.Time, ttl time.Duration) string {
+	date := now.Format("20060102")
+	scope := date + "/" + s3Storage.config.Region + "/s3/aws4_request"
+
+	canonicalHeaders := map[string]string{"host": s3Storage.endpoint.Host}
+	for name, value := range headers {
+		canonicalHeaders[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+	headerNames := make([]string, 0, len(canonicalHeaders))
+	for name := range canonicalHeaders {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	var headerLines strings.Builder
+	for _, name := range headerNames {
+		headerLines.WriteString(name + ":" + canonicalHeaders[name] + "\n")
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", signingAlgorithm)
+	query.Set("X-Amz-Credential", s3Storage.config.AccessKeyId+"/"+scope)
+	query.Set("X-Amz-Date", now.Format(amzDateFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(ttl.Seconds())))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+	canonicalQuery := encodeQuery(query)
+
+	canonicalPath := s3Storage.endpoint.EscapedPath() + encodePath(path)
+	canonicalRequest := strings.Join([]string{
+		method, canonicalPath, canonicalQuery, headerLines.String(), signedHeaders, unsignedPayload,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		signingAlgorithm, now.Format(amzDateFormat), scope, hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s3Storage.config.SecretAccessKey), date)
+	for _, part := range []string{s3Storage.config.Region, "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	return s3Storage.endpoint.Scheme + "://" + s3Storage.endpoint.Host + canonicalPath +
+		"?" + canonicalQuery + "&X-Amz-Signature=" + signature
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// encodeQuery sorts and escapes parameters the way SigV4 expects; url.Values.Encode
+// writes spaces as "+", which S3 would not match.
+func encodeQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(query.Get(key), true))
+	}
+	return strings.Join(pairs, "&")
+}
+
+func encodePath(path string) string {
+	return uriEncode(path, false)
+}
+
+// uriEncode percent-encodes everything except unreserved characters, and "/" unless
+// encodeSlash is set.
+func uriEncode(value string, encodeSlash bool) string {
+	var builder strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~':
+			builder.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			builder.WriteByte(b)
+		default:
+			fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+	return builder.String()
+}
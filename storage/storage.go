@@ -0,0 +1,22 @@
+package storage
+
+import "time"
+
+// Storage hands out signed URLs clients upload objects to directly, so image bytes
+// never pass through the API.
+type Storage interface {
+	// PresignUpload returns a URL accepting a single PUT of the object at key with the
+	// given content type, valid for ttl.
+	PresignUpload(key string, contentType string, ttl time.Duration) (SignedUpload, error)
+	// PublicUrl is the address the object at key is served from once uploaded.
+	PublicUrl(key string) string
+}
+
+// SignedUpload is a request the client has to send as is: Headers must be set on the
+// upload or the signature will not match.
+type SignedUpload struct {
+	Url       string
+	Method    string
+	Headers   map[string]string
+	ExpiresAt time.Time
+}
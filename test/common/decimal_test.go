@@ -0,0 +1,86 @@
+package common
+
+import (
+	"encoding/json"
+	"math/big"
+	"product-app/common/decimal"
+	"testing"
+
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Decimal(t *testing.T) {
+	t.Run("Should parse plain decimals exactly", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			"12": "12.00", "0.1": "0.10", "1999.99": "1999.99", "-3.5": "-3.50", "+7": "7.00", ".5": "0.50",
+		} {
+			parsed, err := decimal.Parse(input)
+			assert.NoError(t, err, input)
+			assert.Equal(t, expected, parsed.String(), input)
+		}
+	})
+
+	t.Run("Should reject values it would have to round", func(t *testing.T) {
+		for _, input := range []string{"", "-", ".", "1.999", "1e3", "1,000", "cheap", "99999999999999999999"} {
+			_, err := decimal.Parse(input)
+			assert.ErrorIs(t, err, decimal.ErrInvalidDecimal, input)
+		}
+	})
+
+	t.Run("Should add without float rounding errors", func(t *testing.T) {
+		sum := decimal.Zero
+		for i := 0; i < 10; i++ {
+			sum = sum.Add(decimal.MustParse("0.10"))
+		}
+		assert.Equal(t, decimal.NewFromInt(1), sum)
+		assert.Equal(t, "0.33", decimal.NewFromInt(1).DivInt(3).String())
+		assert.Equal(t, "0.67", decimal.NewFromInt(2).DivInt(3).String())
+		assert.Equal(t, "-0.67", decimal.NewFromInt(-2).DivInt(3).String())
+	})
+
+	t.Run("Should apply a discount rounded to cents", func(t *testing.T) {
+		seventyPercent := decimal.NewFromInt(70)
+		assert.Equal(t, "900.00", decimal.NewFromInt(3000).ApplyDiscount(seventyPercent).String())
+		assert.Equal(t, "0.00", decimal.MustParse("0.01").ApplyDiscount(seventyPercent).String())
+		assert.Equal(t, "0.01", decimal.MustParse("0.02").ApplyDiscount(seventyPercent).String())
+		assert.Equal(t, "0.02", decimal.MustParse("0.05").ApplyDiscount(seventyPercent).String())
+		assert.Equal(t, "0.00", decimal.NewFromInt(10).ApplyDiscount(decimal.NewFromInt(100)).String())
+	})
+
+	t.Run("Should write JSON strings and read strings or numbers", func(t *testing.T) {
+		encoded, err := json.Marshal(struct {
+			Price decimal.Decimal `json:"price"`
+		}{decimal.MustParse("1500.5")})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"price": "1500.50"}`, string(encoded))
+
+		var decoded struct {
+			Price    decimal.Decimal  `json:"price"`
+			Discount *decimal.Decimal `json:"discount"`
+		}
+		assert.NoError(t, json.Unmarshal([]byte(`{"price": 19.99, "discount": "12.5"}`), &decoded))
+		assert.Equal(t, decimal.MustParse("19.99"), decoded.Price)
+		assert.Equal(t, decimal.MustParse("12.50"), *decoded.Discount)
+
+		assert.ErrorIs(t, json.Unmarshal([]byte(`{"price": 0.001}`), &decoded), decimal.ErrInvalidDecimal)
+	})
+
+	t.Run("Should scan NUMERIC values without losing digits", func(t *testing.T) {
+		var scanned decimal.Decimal
+		assert.NoError(t, scanned.DecodeText(nil, []byte("3000.10")))
+		assert.Equal(t, "3000.10", scanned.String())
+
+		binary, err := (&pgtype.Numeric{Int: big.NewInt(1234567), Exp: -3, Status: pgtype.Present}).EncodeBinary(nil, nil)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, scanned.DecodeBinary(nil, binary), decimal.ErrInvalidDecimal)
+
+		binary, err = (&pgtype.Numeric{Int: big.NewInt(15), Exp: 2, Status: pgtype.Present}).EncodeBinary(nil, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, scanned.DecodeBinary(nil, binary))
+		assert.Equal(t, "1500.00", scanned.String())
+
+		assert.NoError(t, scanned.DecodeText(nil, nil))
+		assert.True(t, scanned.IsZero())
+	})
+}
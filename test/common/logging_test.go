@@ -0,0 +1,57 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"product-app/common/logging"
+	"testing"
+
+	"github.com/labstack/gommon/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func captureLogs(t *testing.T, format string, level string) *bytes.Buffer {
+	var buffer bytes.Buffer
+	log.SetOutput(&buffer)
+	assert.NoError(t, logging.Configure(format, level))
+	t.Cleanup(func() {
+		log.SetOutput(os.Stdout)
+		assert.NoError(t, logging.Configure(logging.FormatText, "info"))
+	})
+	return &buffer
+}
+
+func Test_Logging(t *testing.T) {
+	t.Run("Should write structured fields in json format", func(t *testing.T) {
+		buffer := captureLogs(t, logging.FormatJSON, "info")
+		logging.Error("error while deleting product", logging.Fields{"product_id": int64(7), "error": errors.New("connection reset")})
+
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buffer.Bytes(), &entry))
+		assert.Equal(t, "ERROR", entry["level"])
+		assert.Equal(t, "error while deleting product", entry["msg"])
+		assert.Equal(t, float64(7), entry["product_id"])
+		assert.Equal(t, "connection reset", entry["error"])
+	})
+
+	t.Run("Should write key value pairs in text format", func(t *testing.T) {
+		buffer := captureLogs(t, logging.FormatText, "info")
+		logging.Info("product deleted", logging.Fields{"product_id": 7})
+
+		assert.Contains(t, buffer.String(), "INFO product deleted product_id=7")
+	})
+
+	t.Run("Should drop entries below the configured level", func(t *testing.T) {
+		buffer := captureLogs(t, logging.FormatJSON, "warn")
+		logging.Info("product deleted", logging.Fields{"product_id": 7})
+
+		assert.Empty(t, buffer.String())
+	})
+
+	t.Run("Should reject unknown formats and levels", func(t *testing.T) {
+		assert.Error(t, logging.Configure("xml", "info"))
+		assert.Error(t, logging.Configure(logging.FormatJSON, "verbose"))
+	})
+}
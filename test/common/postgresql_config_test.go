@@ -0,0 +1,50 @@
+package common
+
+import (
+	"product-app/common/postgresql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validConfig() postgresql.Config {
+	return postgresql.Config{
+		Host:                  "localhost",
+		Port:                  "6432",
+		UserName:              "postgres",
+		Password:              "postgres",
+		DbName:                "productapp",
+		MaxConnections:        "10",
+		MaxConnectionIdleTime: "30s",
+	}
+}
+
+func Test_PostgreSqlConfigValidate(t *testing.T) {
+	t.Run("Should accept a valid configuration", func(t *testing.T) {
+		assert.NoError(t, validConfig().Validate())
+	})
+
+	t.Run("Should report every invalid field", func(t *testing.T) {
+		config := validConfig()
+		config.Host = ""
+		config.Port = "abc"
+		config.MaxConnections = "ten"
+		config.MaxConnectionIdleTime = "30"
+
+		err := config.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "Host is required")
+		assert.Contains(t, err.Error(), `Port must be an integer between 1 and 65535, got "abc"`)
+		assert.Contains(t, err.Error(), `MaxConnections must be a positive integer, got "ten"`)
+		assert.Contains(t, err.Error(), `MaxConnectionIdleTime must be a duration`)
+	})
+
+	t.Run("Should validate the replica port only when one is given", func(t *testing.T) {
+		config := validConfig()
+		config.ReplicaHost = "replica"
+		assert.NoError(t, config.Validate())
+
+		config.ReplicaPort = "70000"
+		assert.ErrorContains(t, config.Validate(), `ReplicaPort must be an integer between 1 and 65535, got "70000"`)
+	})
+}
@@ -0,0 +1,244 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"product-app/controller"
+	"product-app/controller/response"
+	"product-app/domain"
+	"product-app/middleware"
+	"product-app/service"
+	testservice "product-app/test/service"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCategoryServer() *echo.Echo {
+	e := echo.New()
+	categoryService := service.NewCategoryService(testservice.NewFakeCategoryRepository([]domain.Category{}), service.DefaultCategorySettings)
+	controller.NewCategoryController(categoryService, controller.DefaultCategoryPageSize).RegisterRoutes(e)
+	return e
+}
+
+func postCategory(e *echo.Echo, token string) *httptest.ResponseRecorder {
+	body := `{"name": "Electronics", "description": "Electronic devices and gadgets"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func Test_AddCategory_Authorization(t *testing.T) {
+	e := newCategoryServer()
+
+	t.Run("Should return 401 without a token", func(t *testing.T) {
+		rec := postCategory(e, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Should return 403 for a non-admin user", func(t *testing.T) {
+		token, _ := middleware.GenerateToken(1, "demo", "demo@example.com", domain.RoleUser)
+		rec := postCategory(e, token)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Should create the category for an admin", func(t *testing.T) {
+		token, _ := middleware.GenerateToken(2, "admin", "admin@example.com", domain.RoleAdmin)
+		rec := postCategory(e, token)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var created response.MutationResponse[domain.Category]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+		assert.Equal(t, int64(1), created.Id)
+		assert.Equal(t, "electronics", created.Data.Slug)
+	})
+
+	t.Run("Should return 409 for a name that differs only in case", func(t *testing.T) {
+		token, _ := middleware.GenerateToken(2, "admin", "admin@example.com", domain.RoleAdmin)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", strings.NewReader(`{"name": "ELECTRONICS", "description": "Gadgets"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), "a category with this name already exists")
+	})
+
+	t.Run("Should keep category reads public", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/categories", nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func Test_DeleteCategory(t *testing.T) {
+	e := echo.New()
+	fakeRepo := testservice.NewFakeCategoryRepository([]domain.Category{
+		{Id: 1, Name: "Electronics", Slug: "electronics", Description: "Electronic devices"},
+		{Id: 2, Name: "Home", Slug: "home", Description: "Home appliances"},
+	})
+	fakeRepo.AssignProduct(10, 1)
+	controller.NewCategoryController(service.NewCategoryService(fakeRepo, service.DefaultCategorySettings), controller.DefaultCategoryPageSize).RegisterRoutes(e)
+	token, _ := middleware.GenerateToken(2, "admin", "admin@example.com", domain.RoleAdmin)
+
+	deleteCategory := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should return 409 for a category with products", func(t *testing.T) {
+		rec := deleteCategory("/api/v1/categories/1")
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("Should reassign the products when forced", func(t *testing.T) {
+		rec := deleteCategory("/api/v1/categories/1?force=true")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"reassigned_products": 1}`, rec.Body.String())
+	})
+
+	t.Run("Should delete an empty category without content", func(t *testing.T) {
+		rec := deleteCategory("/api/v1/categories/2")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("Should return 404 for an unknown category", func(t *testing.T) {
+		rec := deleteCategory("/api/v1/categories/99")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Should reject an invalid force flag", func(t *testing.T) {
+		rec := deleteCategory("/api/v1/categories/2?force=maybe")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func Test_RestoreCategory(t *testing.T) {
+	e := newCategoryServer()
+	token, _ := middleware.GenerateToken(2, "admin", "admin@example.com", domain.RoleAdmin)
+	assert.Equal(t, http.StatusCreated, postCategory(e, token).Code)
+
+	send := func(method string, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should return 404 for a category that is not deleted", func(t *testing.T) {
+		rec := send(http.MethodPost, "/api/v1/categories/1/restore")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Should leave a deleted category out of the listing", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, send(http.MethodDelete, "/api/v1/categories/1").Code)
+		assert.Equal(t, http.StatusNotFound, send(http.MethodGet, "/api/v1/categories/1").Code)
+
+		rec := send(http.MethodGet, "/api/v1/categories")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"total":0`)
+	})
+
+	t.Run("Should bring the category back", func(t *testing.T) {
+		rec := send(http.MethodPost, "/api/v1/categories/1/restore")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var restored response.MutationResponse[domain.Category]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &restored))
+		assert.Equal(t, "electronics", restored.Data.Slug)
+		assert.Equal(t, http.StatusOK, send(http.MethodGet, "/api/v1/categories/1").Code)
+	})
+
+	t.Run("Should require the admin role", func(t *testing.T) {
+		userToken, _ := middleware.GenerateToken(1, "demo", "demo@example.com", domain.RoleUser)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/categories/1/restore", nil)
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func Test_GetAllCategories_Pagination(t *testing.T) {
+	e := echo.New()
+	fakeRepo := testservice.NewFakeCategoryRepository([]domain.Category{
+		{Id: 1, Name: "Electronics", Slug: "electronics"},
+		{Id: 2, Name: "Home", Slug: "home"},
+		{Id: 3, Name: "Garden", Slug: "garden"},
+	})
+	controller.NewCategoryController(service.NewCategoryService(fakeRepo, service.DefaultCategorySettings), controller.PageSize{Default: 2, Max: 10}).RegisterRoutes(e)
+
+	getPage := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/categories"+query, nil))
+		return rec
+	}
+
+	t.Run("Should use the default page size when no limit is given", func(t *testing.T) {
+		rec := getPage("")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var page response.Page[domain.Category]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		assert.Len(t, page.Items, 2)
+		assert.Equal(t, 2, page.Size)
+		assert.Equal(t, int64(3), page.Total)
+		assert.Equal(t, "3", rec.Header().Get("X-Total-Count"))
+	})
+
+	t.Run("Should return the requested page", func(t *testing.T) {
+		var page response.Page[domain.Category]
+		assert.NoError(t, json.Unmarshal(getPage("?limit=2&offset=2").Body.Bytes(), &page))
+		assert.Len(t, page.Items, 1)
+		assert.Equal(t, 2, page.Page)
+	})
+
+	t.Run("Should return empty items past the end", func(t *testing.T) {
+		rec := getPage("?offset=10")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"items":[]`)
+	})
+
+	t.Run("Should reject invalid limits and offsets", func(t *testing.T) {
+		for _, query := range []string{"?limit=0", "?limit=two", "?offset=-1"} {
+			assert.Equal(t, http.StatusBadRequest, getPage(query).Code, query)
+		}
+	})
+}
+
+func Test_GetCategoryTree(t *testing.T) {
+	e := echo.New()
+	electronicsId := int64(1)
+	fakeRepo := testservice.NewFakeCategoryRepository([]domain.Category{
+		{Id: 1, Name: "Electronics", Slug: "electronics"},
+		{Id: 2, Name: "Phones", Slug: "phones", ParentId: &electronicsId},
+	})
+	controller.NewCategoryController(service.NewCategoryService(fakeRepo, service.DefaultCategorySettings), controller.DefaultCategoryPageSize).RegisterRoutes(e)
+
+	t.Run("Should return top-level categories with nested children", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/categories/tree", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var tree []domain.CategoryNode
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tree))
+		assert.Len(t, tree, 1)
+		assert.Equal(t, "electronics", tree[0].Slug)
+		assert.Len(t, tree[0].Children, 1)
+		assert.Equal(t, &electronicsId, tree[0].Children[0].ParentId)
+		assert.Contains(t, rec.Body.String(), `"children":[]`)
+	})
+}
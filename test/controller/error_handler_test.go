@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"product-app/controller"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HTTPErrorHandler(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = controller.NewHTTPErrorHandler(e)
+	e.GET("/api/v1/products", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	serve := func(method string, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	t.Run("Should return the error envelope for unknown routes", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/api/v1/unknown")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.JSONEq(t, `{"errorDescription": "Error: no route for GET /api/v1/unknown"}`, rec.Body.String())
+	})
+
+	t.Run("Should return the error envelope for unsupported methods", func(t *testing.T) {
+		rec := serve(http.MethodPut, "/api/v1/products")
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderAllow), http.MethodGet)
+		assert.JSONEq(t, `{"errorDescription": "Error: method PUT is not allowed for /api/v1/products"}`, rec.Body.String())
+	})
+}
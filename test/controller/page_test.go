@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"product-app/controller"
+	"product-app/controller/response"
+	"product-app/domain"
+	"product-app/service"
+	testservice "product-app/test/service"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewPage(t *testing.T) {
+	t.Run("Should round total pages up", func(t *testing.T) {
+		page := response.NewPage([]string{"a", "b"}, 5, 3, 2)
+		assert.Equal(t, 3, page.TotalPages)
+		assert.Equal(t, 3, page.Page)
+		assert.Equal(t, int64(5), page.Total)
+	})
+
+	t.Run("Should treat zero size as a single page", func(t *testing.T) {
+		page := response.NewPage([]string{"a", "b"}, 2, 1, 0)
+		assert.Equal(t, 1, page.TotalPages)
+	})
+
+	t.Run("Should return no pages and an empty item list when there is nothing", func(t *testing.T) {
+		page := response.NewPage[string](nil, 0, 1, 10)
+		assert.Equal(t, 0, page.TotalPages)
+		assert.NotNil(t, page.Items)
+		assert.Empty(t, page.Items)
+	})
+}
+
+func Test_EmptyListsSerializeAsArrays(t *testing.T) {
+	t.Run("Should replace nil with an empty slice and keep other slices", func(t *testing.T) {
+		assert.Equal(t, []int64{}, response.EmptyIfNil[int64](nil))
+		assert.Equal(t, []int64{1, 2}, response.EmptyIfNil([]int64{1, 2}))
+	})
+
+	t.Run("Should write the list fields of a bare product as []", func(t *testing.T) {
+		body, err := json.Marshal(response.ToResponse(domain.Product{Name: "Ütü"}))
+		assert.NoError(t, err)
+		for _, field := range []string{`"image_urls":[]`, `"images":[]`, `"tags":[]`} {
+			assert.Contains(t, string(body), field)
+		}
+	})
+
+	t.Run("Should answer empty listings with []", func(t *testing.T) {
+		e := echo.New()
+		productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{}), service.DefaultProductSettings)
+		controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+		categoryService := service.NewCategoryService(testservice.NewFakeCategoryRepository(nil), service.DefaultCategorySettings)
+		controller.NewCategoryController(categoryService, controller.DefaultCategoryPageSize).RegisterRoutes(e)
+
+		for path, expected := range map[string]string{
+			"/api/v1/products":          `[]`,
+			"/api/v1/products?store=X":  `"items":[]`,
+			"/api/v1/products/newest":   `[]`,
+			"/api/v1/products/featured": `[]`,
+			"/api/v1/categories":        `"items":[]`,
+			"/api/v1/categories/tree":   `[]`,
+		} {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+			assert.Equal(t, http.StatusOK, rec.Code, path)
+			assert.Contains(t, rec.Body.String(), expected, path)
+			assert.NotContains(t, rec.Body.String(), "null", path)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"product-app/controller/request"
+	"product-app/service/model"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParsePagination(t *testing.T) {
+	e := echo.New()
+	pageSize := request.PageSize{Default: 20, Max: 100}
+	parse := func(query string) (request.Pagination, error) {
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/products"+query, nil), httptest.NewRecorder())
+		return request.ParsePagination(c, pageSize)
+	}
+
+	t.Run("Should apply the default limit without parameters", func(t *testing.T) {
+		pagination, err := parse("")
+		assert.NoError(t, err)
+		assert.Equal(t, request.Pagination{Limit: 20}, pagination)
+	})
+
+	t.Run("Should keep the default within the maximum", func(t *testing.T) {
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/products", nil), httptest.NewRecorder())
+		pagination, err := request.ParsePagination(c, request.PageSize{Default: 50, Max: 10})
+		assert.NoError(t, err)
+		assert.Equal(t, 10, pagination.Limit)
+	})
+
+	t.Run("Should read limit, offset and sort", func(t *testing.T) {
+		pagination, err := parse("?limit=5&offset=10&sort=price_desc")
+		assert.NoError(t, err)
+		assert.Equal(t, request.Pagination{Limit: 5, Offset: 10, Sort: "price_desc"}, pagination)
+		assert.Equal(t, model.PageRequest{Limit: 5, Offset: 10, Sort: "price_desc"}, pagination.PageRequest())
+	})
+
+	t.Run("Should fall back to the default sort", func(t *testing.T) {
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/products", nil), httptest.NewRecorder())
+		pagination, err := request.ParsePagination(c, request.PageSize{Default: 20, Max: 100, Sort: "newest"})
+		assert.NoError(t, err)
+		assert.Equal(t, "newest", pagination.Sort)
+
+		c = e.NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/products?sort=price_asc", nil), httptest.NewRecorder())
+		pagination, err = request.ParsePagination(c, request.PageSize{Default: 20, Max: 100, Sort: "newest"})
+		assert.NoError(t, err)
+		assert.Equal(t, "price_asc", pagination.Sort)
+	})
+
+	t.Run("Should clamp a limit above the maximum", func(t *testing.T) {
+		pagination, err := parse("?limit=1000")
+		assert.NoError(t, err)
+		assert.Equal(t, 100, pagination.Limit)
+	})
+
+	t.Run("Should reject invalid values", func(t *testing.T) {
+		for query, message := range map[string]string{
+			"?limit=ten":        "limit must be an integer",
+			"?limit=0":          "limit must be greater than zero",
+			"?limit=-5":         "limit must be greater than zero",
+			"?offset=abc":       "offset must be an integer",
+			"?offset=-1":        "offset must not be negative",
+			"?limit=2.5":        "limit must be an integer",
+			"?offset=1e3":       "offset must be an integer",
+			"?limit=&offset=-3": "offset must not be negative",
+		} {
+			_, err := parse(query)
+			assert.EqualError(t, err, message, query)
+		}
+	})
+}
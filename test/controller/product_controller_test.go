@@ -0,0 +1,1416 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"product-app/common/decimal"
+	"product-app/controller"
+	"product-app/controller/response"
+	"product-app/domain"
+	"product-app/middleware"
+	"product-app/persistence"
+	"product-app/service"
+	testservice "product-app/test/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newProductController() *controller.ProductController {
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{}), service.DefaultProductSettings)
+	return controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true)
+}
+
+func postProduct(t *testing.T, body string) (*httptest.ResponseRecorder, response.ErrorResponse) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	err := newProductController().AddProduct(e.NewContext(req, rec))
+	assert.NoError(t, err)
+
+	var errorResponse response.ErrorResponse
+	if rec.Code >= http.StatusBadRequest {
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errorResponse))
+	}
+	return rec, errorResponse
+}
+
+func Test_AddProduct_BindErrors(t *testing.T) {
+	t.Run("Should reject a string where a number is expected", func(t *testing.T) {
+		rec, errorResponse := postProduct(t, `{"name": "Ütü", "price": "cheap", "store": "ABC TECH"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `invalid decimal "cheap": prices and discounts must be numbers with at most 2 decimal places`, errorResponse.ErrorDescription)
+	})
+
+	t.Run("Should reject unknown fields", func(t *testing.T) {
+		rec, errorResponse := postProduct(t, `{"name": "Ütü", "prcie": 100, "store": "ABC TECH"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, `unknown field "prcie"`, errorResponse.ErrorDescription)
+	})
+
+	t.Run("Should reject malformed JSON", func(t *testing.T) {
+		rec, errorResponse := postProduct(t, `{"name": "Ütü",`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "request body contains malformed JSON", errorResponse.ErrorDescription)
+	})
+
+	t.Run("Should create a product from a valid body", func(t *testing.T) {
+		rec, _ := postProduct(t, `{"name": "Ütü", "price": 100, "store": "ABC TECH", "category_id": 1}`)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var created response.MutationResponse[response.ProductResponse]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+		assert.Equal(t, int64(1), created.Id)
+		assert.Equal(t, "Ütü", created.Data.Name)
+		assert.NotEmpty(t, created.Data.Slug)
+	})
+}
+
+func Test_AddProduct_ValidationDetails(t *testing.T) {
+	t.Run("Should list every failing field with a stable code", func(t *testing.T) {
+		rec, _ := postProduct(t, `{"name": "", "price": 0, "currency": "XYZ", "store": "ABC-TECH", "discount": 95}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+		var body struct {
+			Details []map[string]string `json:"details"`
+		}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		codes := map[string]string{}
+		for _, detail := range body.Details {
+			codes[detail["field"]] = detail["code"]
+			assert.NotEmpty(t, detail["message"])
+		}
+		assert.Equal(t, map[string]string{
+			"name":     "required",
+			"price":    "price_not_positive",
+			"currency": "unsupported_currency",
+			"store":    "invalid_characters",
+			"discount": "discount_out_of_range",
+		}, codes)
+	})
+
+	t.Run("Should reject a negative category id as a field error", func(t *testing.T) {
+		rec, errorResponse := postProduct(t, `{"name": "Ütü", "price": 100, "store": "ABC TECH", "category_id": -1}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, errorResponse.ErrorDescription, "category_id must not be negative")
+		assert.Contains(t, rec.Body.String(), `"code":"category_id_negative"`)
+	})
+
+	t.Run("Should keep the summary and omit details for other errors", func(t *testing.T) {
+		rec, errorResponse := postProduct(t, `{"name": "Ütü", "price": 100, "store": "ABC TECH", "category_id": 99}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.NotEmpty(t, errorResponse.ErrorDescription)
+		assert.NotContains(t, rec.Body.String(), `"details"`)
+	})
+}
+
+func Test_DeleteRoutes(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+	token, _ := middleware.GenerateToken(1, "demo", "demo@example.com", domain.RoleUser)
+
+	deleteRequest := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should reject a non-numeric product id", func(t *testing.T) {
+		rec := deleteRequest("/api/v1/products/abc")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Should answer a single delete with no content", func(t *testing.T) {
+		rec := deleteRequest("/api/v1/products/1")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("Should route deleteAll to the delete-all handler", func(t *testing.T) {
+		rec := deleteRequest("/api/v1/products/deleteAll")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, productService.GetAllProducts())
+	})
+}
+
+func Test_AddProduct_Authentication(t *testing.T) {
+	body := `{"name": "Ütü", "price": 1500, "description": "Buharlı ütü", "store": "ABC TECH"}`
+	token, _ := middleware.GenerateToken(7, "demo", "demo@example.com", domain.RoleUser)
+	newServer := func(requireAuthForCreate bool) (*echo.Echo, persistence.IProductRepository) {
+		e := echo.New()
+		productRepository := testservice.NewFakeProductRepository([]domain.Product{})
+		productService := service.NewProductService(productRepository, service.DefaultProductSettings)
+		controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, requireAuthForCreate).RegisterRoutes(e)
+		return e, productRepository
+	}
+	create := func(e *echo.Echo, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/products", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should return 401 without a token when authentication is required", func(t *testing.T) {
+		e, productRepository := newServer(true)
+		assert.Equal(t, http.StatusUnauthorized, create(e, "").Code)
+		assert.Empty(t, productRepository.GettAllProducts())
+	})
+
+	t.Run("Should stamp the caller as the owner", func(t *testing.T) {
+		e, productRepository := newServer(true)
+		assert.Equal(t, http.StatusCreated, create(e, token).Code)
+		assert.Len(t, productRepository.GetAllProductsByUser(7), 1)
+	})
+
+	t.Run("Should accept anonymous creation when authentication is not required", func(t *testing.T) {
+		e, productRepository := newServer(false)
+		assert.Equal(t, http.StatusCreated, create(e, "").Code)
+		assert.Len(t, productRepository.GettAllProducts(), 1)
+		assert.Empty(t, productRepository.GetAllProductsByUser(7))
+
+		assert.Equal(t, http.StatusCreated, create(e, token).Code)
+		assert.Len(t, productRepository.GetAllProductsByUser(7), 1)
+	})
+}
+
+func Test_AddProduct_IdempotencyKeyLength(t *testing.T) {
+	e := echo.New()
+	productRepository := testservice.NewFakeProductRepository([]domain.Product{})
+	productService := service.NewProductService(productRepository, service.DefaultProductSettings)
+	idempotencyService := service.NewIdempotencyService(testservice.NewFakeIdempotencyRepository(), productService, time.Hour)
+	controller.NewProductController(productService, nil, idempotencyService, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, false).RegisterRoutes(e)
+
+	create := func(idempotencyKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/products", strings.NewReader(`{"name": "Ütü", "price": 1500, "store": "ABC TECH"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should reject a key longer than 255 characters", func(t *testing.T) {
+		rec := create(strings.Repeat("k", 256))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "at most 255 characters")
+		assert.Empty(t, productRepository.GettAllProducts())
+	})
+
+	t.Run("Should accept a key of 255 characters", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, create(strings.Repeat("k", 255)).Code)
+		assert.Len(t, productRepository.GettAllProducts(), 1)
+	})
+}
+
+func Test_GetMyProducts(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+	ownerToken, _ := middleware.GenerateToken(7, "demo", "demo@example.com", domain.RoleUser)
+	otherToken, _ := middleware.GenerateToken(8, "other", "other@example.com", domain.RoleUser)
+
+	send := func(method string, path string, body string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should reject an anonymous create", func(t *testing.T) {
+		rec := send(http.MethodPost, "/api/v1/products", `{"name": "Ütü", "price": 1500, "description": "Buharlı ütü", "store": "ABC TECH"}`, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Should list only the caller's products, drafts included", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, send(http.MethodPost, "/api/v1/products", `{"name": "Ütü", "price": 1500, "description": "Buharlı ütü", "store": "ABC TECH"}`, ownerToken).Code)
+		assert.Equal(t, http.StatusCreated, send(http.MethodPost, "/api/v1/products", `{"name": "Lambader", "price": 900, "description": "Lambader", "store": "ABC TECH", "status": "draft"}`, ownerToken).Code)
+		assert.Equal(t, http.StatusCreated, send(http.MethodPost, "/api/v1/products", `{"name": "AirFryer", "price": 3000, "description": "AirFryer", "store": "ABC TECH"}`, otherToken).Code)
+
+		rec := send(http.MethodGet, "/api/v1/products/my-products", "", ownerToken)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var page response.Page[response.ProductResponse]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		assert.Equal(t, int64(2), page.Total)
+		assert.Equal(t, "2", rec.Header().Get("X-Total-Count"))
+	})
+
+	t.Run("Should return 401 without a token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/api/v1/products/my-products", "", "").Code)
+	})
+}
+
+func Test_GetNewestProducts(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	getNewest := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("Should route newest before the product id route", func(t *testing.T) {
+		rec := getNewest("/api/v1/products/newest?limit=1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"Ütü"`)
+		assert.NotContains(t, rec.Body.String(), "AirFryer")
+	})
+
+	t.Run("Should reject a non-numeric limit", func(t *testing.T) {
+		rec := getNewest("/api/v1/products/newest?limit=ten")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func Test_GetProductBySlug(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Slug: "airfryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	getBySlug := func(slug string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products/slug/"+slug, nil))
+		return rec
+	}
+
+	t.Run("Should return the product with the slug", func(t *testing.T) {
+		rec := getBySlug("airfryer")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"slug":"airfryer"`)
+	})
+
+	t.Run("Should return 404 for an unknown slug", func(t *testing.T) {
+		rec := getBySlug("unknown")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func Test_GetProductsByCategoryId(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	getCategoryProducts := func(categoryId string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/categories/"+categoryId+"/products", nil))
+		return rec
+	}
+
+	t.Run("Should return an empty page for an existing empty category", func(t *testing.T) {
+		rec := getCategoryProducts("2")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"items":[]`)
+		assert.Equal(t, "0", rec.Header().Get("X-Total-Count"))
+	})
+
+	t.Run("Should return 404 for an unknown category", func(t *testing.T) {
+		rec := getCategoryProducts("99")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Contains(t, rec.Body.String(), "category not found")
+	})
+}
+
+func Test_GetProductsByCategoryId_PageSize(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH", CategoryID: 1},
+		{Id: 3, Name: "Çamaşır Makinesi", Price: decimal.NewFromInt(10000), Store: "ABC TECH", CategoryID: 1},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.PageSize{Default: 1, Max: 2}, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	getPage := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/categories/1/products"+query, nil))
+		return rec
+	}
+
+	t.Run("Should clamp an oversized limit to the maximum page size", func(t *testing.T) {
+		rec := getPage("?limit=100000")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var page response.Page[response.ProductResponse]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		assert.Equal(t, 2, page.Size)
+		assert.Len(t, page.Items, 2)
+		assert.Equal(t, int64(3), page.Total)
+		assert.Equal(t, 2, page.TotalPages)
+	})
+
+	t.Run("Should use the default page size when no limit is given", func(t *testing.T) {
+		rec := getPage("")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"size":1`)
+	})
+
+	t.Run("Should link the neighbouring pages and keep the query", func(t *testing.T) {
+		rec := getPage("?limit=1&offset=1&sort=price_asc")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "3", rec.Header().Get("X-Total-Count"))
+		assert.Equal(t, `</api/v1/categories/1/products?limit=1&offset=0&sort=price_asc>; rel="first", `+
+			`</api/v1/categories/1/products?limit=1&offset=0&sort=price_asc>; rel="prev", `+
+			`</api/v1/categories/1/products?limit=1&offset=2&sort=price_asc>; rel="next", `+
+			`</api/v1/categories/1/products?limit=1&offset=2&sort=price_asc>; rel="last"`, rec.Header().Get("Link"))
+	})
+
+	t.Run("Should omit prev on the first page and next on the last", func(t *testing.T) {
+		first := getPage("?limit=2").Header().Get("Link")
+		assert.NotContains(t, first, `rel="prev"`)
+		assert.Contains(t, first, `offset=2>; rel="next"`)
+
+		last := getPage("?limit=2&offset=2").Header().Get("Link")
+		assert.Contains(t, last, `offset=0>; rel="prev"`)
+		assert.NotContains(t, last, `rel="next"`)
+	})
+
+	t.Run("Should reject zero and negative limits", func(t *testing.T) {
+		for _, limit := range []string{"0", "-5"} {
+			rec := getPage("?limit=" + limit)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "limit must be greater than zero")
+		}
+	})
+}
+
+func Test_GetProductsByCategoryId_PriceFilter(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Currency: "TRY", Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Currency: "TRY", Store: "ABC TECH", CategoryID: 1},
+		{Id: 3, Name: "Çamaşır Makinesi", Price: decimal.NewFromInt(10000), Currency: "TRY", Store: "ABC TECH", CategoryID: 1},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	getPage := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/categories/1/products"+query, nil))
+		return rec
+	}
+
+	t.Run("Should filter by price within the category and keep pagination", func(t *testing.T) {
+		rec := getPage("?minPrice=1000&maxPrice=5000&sort=price_desc&limit=1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var page response.Page[response.ProductResponse]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		assert.Equal(t, int64(2), page.Total)
+		assert.Len(t, page.Items, 1)
+		assert.Equal(t, "AirFryer", page.Items[0].Name)
+	})
+
+	t.Run("Should reject a non-numeric bound", func(t *testing.T) {
+		rec := getPage("?minPrice=cheap")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "minPrice must be a number")
+	})
+
+	t.Run("Should reject an inverted range", func(t *testing.T) {
+		rec := getPage("?minPrice=5000&maxPrice=1000")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func Test_GetAllProducts_CreatedBetween(t *testing.T) {
+	e := echo.New()
+	newProductController().RegisterRoutes(e)
+
+	getProducts := func(query string) (*httptest.ResponseRecorder, response.ErrorResponse) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products?"+query, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		var errorResponse response.ErrorResponse
+		if rec.Code >= http.StatusBadRequest {
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errorResponse))
+		}
+		return rec, errorResponse
+	}
+
+	t.Run("Should accept an RFC3339 window", func(t *testing.T) {
+		rec, _ := getProducts("createdFrom=2024-03-04T00:00:00Z&createdTo=2024-03-10T23:59:59Z")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Should reject an unparseable date", func(t *testing.T) {
+		rec, errorResponse := getProducts("createdFrom=2024-03-04&createdTo=2024-03-10T23:59:59Z")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "createdFrom must be an RFC3339 timestamp", errorResponse.ErrorDescription)
+	})
+
+	t.Run("Should reject a missing bound", func(t *testing.T) {
+		rec, _ := getProducts("createdFrom=2024-03-04T00:00:00Z")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Should reject a window that ends before it starts", func(t *testing.T) {
+		rec, _ := getProducts("createdFrom=2024-03-10T00:00:00Z&createdTo=2024-03-04T00:00:00Z")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func Test_GetAllProducts_ByStore(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+		{Id: 3, Name: "Çamaşır Makinesi", Price: decimal.NewFromInt(10000), Store: "ABC TECH"},
+		{Id: 4, Name: "Lamba", Price: decimal.NewFromInt(500), Store: "XYZ HOME"},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	t.Run("Should page the store listing with the store's total", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products?store=ABC%20TECH&limit=2", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "3", rec.Header().Get("X-Total-Count"))
+
+		var page response.Page[response.ProductResponse]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		assert.Len(t, page.Items, 2)
+		assert.Equal(t, int64(3), page.Total)
+		assert.Equal(t, 2, page.TotalPages)
+	})
+
+	t.Run("Should reject an invalid offset", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products?store=ABC%20TECH&offset=x", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Should answer an unknown store with an empty array", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products?store=Unknown", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "0", rec.Header().Get("X-Total-Count"))
+		assert.Contains(t, rec.Body.String(), `"items":[]`)
+	})
+}
+
+func Test_GetAllProducts_CombinedFilters(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "Buharlı Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Seyahat Ütüsü", Price: decimal.NewFromInt(900), Store: "ABC TECH", CategoryID: 2},
+		{Id: 3, Name: "Kablosuz Ütü", Price: decimal.NewFromInt(2500), Store: "XYZ HOME", CategoryID: 1},
+		{Id: 4, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	getProducts := func(query string) (*httptest.ResponseRecorder, response.Page[response.ProductResponse]) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products?"+query, nil))
+		var page response.Page[response.ProductResponse]
+		if rec.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		}
+		return rec, page
+	}
+	names := func(page response.Page[response.ProductResponse]) []string {
+		var productNames []string
+		for _, product := range page.Items {
+			productNames = append(productNames, product.Name)
+		}
+		return productNames
+	}
+
+	t.Run("Should AND a text query with a store", func(t *testing.T) {
+		rec, page := getProducts("q=%C3%BCt%C3%BC&store=ABC%20TECH")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []string{"Buharlı Ütü", "Seyahat Ütüsü"}, names(page))
+		assert.Equal(t, "2", rec.Header().Get("X-Total-Count"))
+	})
+
+	t.Run("Should AND a store with a category", func(t *testing.T) {
+		rec, page := getProducts("store=ABC%20TECH&categoryId=1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []string{"Buharlı Ütü", "AirFryer"}, names(page))
+	})
+
+	t.Run("Should AND all three and count the combined matches", func(t *testing.T) {
+		rec, page := getProducts("q=%C3%BCt%C3%BC&store=ABC%20TECH&categoryId=1&limit=1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []string{"Buharlı Ütü"}, names(page))
+		assert.Equal(t, int64(1), page.Total)
+	})
+
+	t.Run("Should reject an invalid category id", func(t *testing.T) {
+		rec, _ := getProducts("q=%C3%BCt%C3%BC&categoryId=abc")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Should reject a cursor with a text query", func(t *testing.T) {
+		rec, _ := getProducts("q=%C3%BCt%C3%BC&cursor=")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func Test_GetAllProducts_DefaultSort(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "Buharlı Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+		{Id: 2, Name: "Seyahat Ütüsü", Price: decimal.NewFromInt(900), Store: "ABC TECH"},
+		{Id: 3, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+	}), service.DefaultProductSettings)
+	pageSize := controller.PageSize{Default: 20, Max: controller.DefaultMaxPageSize, Sort: "price_desc"}
+	controller.NewProductController(productService, nil, nil, pageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	getNames := func(query string) []string {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products?store=ABC%20TECH"+query, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var page response.Page[response.ProductResponse]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		var productNames []string
+		for _, product := range page.Items {
+			productNames = append(productNames, product.Name)
+		}
+		return productNames
+	}
+
+	t.Run("Should apply the configured sort without a sort param", func(t *testing.T) {
+		assert.Equal(t, []string{"AirFryer", "Buharlı Ütü", "Seyahat Ütüsü"}, getNames(""))
+	})
+
+	t.Run("Should apply the configured sort to the listing without query params", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var products []response.ProductResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &products))
+		var productNames []string
+		for _, product := range products {
+			productNames = append(productNames, product.Name)
+		}
+		assert.Equal(t, []string{"AirFryer", "Buharlı Ütü", "Seyahat Ütüsü"}, productNames)
+	})
+
+	t.Run("Should let an explicit sort override the default", func(t *testing.T) {
+		assert.Equal(t, []string{"Seyahat Ütüsü", "Buharlı Ütü", "AirFryer"}, getNames("&sort=price_asc"))
+	})
+}
+
+func Test_GetAllProducts_Cursor(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+		{Id: 3, Name: "Lamba", Price: decimal.NewFromInt(500), Store: "XYZ HOME"},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	getProducts := func(query string) (*httptest.ResponseRecorder, response.CursorPage[response.ProductResponse]) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products?"+query, nil))
+		var page response.CursorPage[response.ProductResponse]
+		if rec.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		}
+		return rec, page
+	}
+
+	t.Run("Should start with an empty cursor and follow next_cursor", func(t *testing.T) {
+		rec, first := getProducts("cursor=&limit=2")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, first.Items, 2)
+		assert.Equal(t, "2", *first.NextCursor)
+
+		rec, last := getProducts("cursor=" + *first.NextCursor + "&limit=2")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, last.Items, 1)
+		assert.Equal(t, "Lamba", last.Items[0].Name)
+		assert.Nil(t, last.NextCursor)
+		assert.Contains(t, rec.Body.String(), `"next_cursor":null`)
+	})
+
+	t.Run("Should keep the store filter", func(t *testing.T) {
+		_, page := getProducts("cursor=1&store=ABC%20TECH")
+		assert.Len(t, page.Items, 1)
+		assert.Equal(t, "Ütü", page.Items[0].Name)
+	})
+
+	t.Run("Should keep the unpaginated listing without a cursor", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products", nil))
+		var products []response.ProductResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &products))
+		assert.Len(t, products, 3)
+	})
+
+	t.Run("Should reject invalid cursors and offset or sort alongside one", func(t *testing.T) {
+		for _, query := range []string{"cursor=abc", "cursor=-1", "cursor=1&offset=20", "cursor=1&sort=price_asc"} {
+			rec, _ := getProducts(query)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		}
+	})
+}
+
+func Test_GetProductChanges(t *testing.T) {
+	e := echo.New()
+	fakeRepo := testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+		{Id: 3, Name: "Lambader", Price: decimal.NewFromInt(2000), Store: "ABC TECH"},
+	}).(*testservice.FakeProductRepository)
+	lastSync := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	fakeRepo.SetUpdatedAt(1, lastSync)
+	fakeRepo.SetUpdatedAt(2, lastSync.Add(time.Minute))
+	fakeRepo.SetUpdatedAt(3, lastSync.Add(2*time.Minute))
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	getChanges := func(query string) (*httptest.ResponseRecorder, response.ProductChangesResponse) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products/changes?"+query, nil))
+		var changes response.ProductChangesResponse
+		if rec.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &changes))
+		}
+		return rec, changes
+	}
+
+	t.Run("Should return changes strictly after since and follow the cursor", func(t *testing.T) {
+		// 15:00+03:00 is the last sync in UTC, so product 1 is not included
+		rec, first := getChanges("since=2024-03-04T15:00:00%2B03:00&limit=1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, first.Changes, 1)
+		assert.Equal(t, int64(2), first.Changes[0].Id)
+		assert.Equal(t, "Ütü", first.Changes[0].Product.Name)
+		assert.NotNil(t, first.NextCursor)
+
+		rec, rest := getChanges("cursor=" + *first.NextCursor)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, rest.Changes, 1)
+		assert.Equal(t, int64(3), rest.Changes[0].Id)
+		assert.Nil(t, rest.NextCursor)
+	})
+
+	t.Run("Should report deleted products without a body", func(t *testing.T) {
+		assert.NoError(t, fakeRepo.DeleteById(3))
+		rec, changes := getChanges("since=2024-03-04T12:00:30Z")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, changes.Changes, 2)
+		assert.True(t, changes.Changes[1].Deleted)
+		assert.Nil(t, changes.Changes[1].Product)
+		assert.Contains(t, rec.Body.String(), `"product":null`)
+	})
+
+	t.Run("Should reject a missing or unparseable since", func(t *testing.T) {
+		for _, query := range []string{"", "since=2024-03-04", "since=yesterday"} {
+			rec, _ := getChanges(query)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+			assert.Contains(t, rec.Body.String(), "since must be an RFC3339 timestamp")
+		}
+	})
+
+	t.Run("Should reject a cursor it did not issue", func(t *testing.T) {
+		rec, _ := getChanges("cursor=not-a-cursor")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func Test_GetProductById(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	getProduct := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("Should return an existing product", func(t *testing.T) {
+		rec := getProduct("/api/v1/products/1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"AirFryer"`)
+	})
+
+	t.Run("Should reject ids that are not positive integers", func(t *testing.T) {
+		for _, id := range []string{"abc", "-1", "0", "1.5", "99999999999999999999"} {
+			rec := getProduct("/api/v1/products/" + id)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, id)
+			assert.JSONEq(t, `{"errorDescription": "id must be a positive integer"}`, rec.Body.String(), id)
+		}
+	})
+
+	t.Run("Should return 404 for a valid id without a product", func(t *testing.T) {
+		for _, path := range []string{"/api/v1/products/99", "/api/v1/products/99?expand=category"} {
+			rec := getProduct(path)
+			assert.Equal(t, http.StatusNotFound, rec.Code, path)
+			assert.JSONEq(t, `{"errorDescription": "product 99 not found"}`, rec.Body.String(), path)
+		}
+	})
+}
+
+func Test_GetProductById_Expand(t *testing.T) {
+	e := echo.New()
+	fakeRepository := testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+	}).(*testservice.FakeProductRepository)
+	fakeRepository.SetRating(2, domain.ProductRating{AverageRating: 4.5, ReviewCount: 2})
+	productService := service.NewProductService(fakeRepository, service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	getProduct := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products/1"+query, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should leave the rating out by default", func(t *testing.T) {
+		rec := getProduct("")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.NotContains(t, body, "average_rating")
+		assert.NotContains(t, body, "review_count")
+	})
+
+	t.Run("Should include the review aggregate", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products/2?expand=rating", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, 4.5, body["average_rating"])
+		assert.Equal(t, float64(2), body["review_count"])
+	})
+
+	t.Run("Should report zero ratings rather than null for a product without reviews", func(t *testing.T) {
+		rec := getProduct("?expand=rating")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, float64(0), body["average_rating"])
+		assert.Equal(t, float64(0), body["review_count"])
+	})
+
+	t.Run("Should combine expansions", func(t *testing.T) {
+		rec := getProduct("?expand=rating,category")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Contains(t, body, "category")
+		assert.Contains(t, body, "review_count")
+	})
+
+	t.Run("Should reject unknown expansions", func(t *testing.T) {
+		rec := getProduct("?expand=rating,reviews")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func Test_GetProductAvailability(t *testing.T) {
+	e := echo.New()
+	inStock, soldOut := 3, 0
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", Stock: &inStock},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH", Stock: &soldOut},
+		{Id: 3, Name: "Lambader", Price: decimal.NewFromInt(2000), Store: "ABC TECH", Stock: &inStock, Status: domain.ProductStatusDiscontinued},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	getAvailability := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("Should report an active product in stock as available", func(t *testing.T) {
+		rec := getAvailability("/api/v1/products/1/availability")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"available": true, "reason": "in_stock"}`, rec.Body.String())
+	})
+
+	t.Run("Should report an out of stock product as unavailable", func(t *testing.T) {
+		rec := getAvailability("/api/v1/products/2/availability")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"available": false, "reason": "out_of_stock"}`, rec.Body.String())
+	})
+
+	t.Run("Should report a discontinued product as unavailable", func(t *testing.T) {
+		rec := getAvailability("/api/v1/products/3/availability")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"available": false, "reason": "not_active"}`, rec.Body.String())
+	})
+
+	t.Run("Should return 404 for an unknown product", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, getAvailability("/api/v1/products/99/availability").Code)
+	})
+}
+
+func Test_UpdateProductCategory(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1, Discount: decimal.NewFromInt(10)},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+	token, _ := middleware.GenerateToken(1, "demo", "demo@example.com", domain.RoleUser)
+
+	moveProduct := func(path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should move the product and keep its other fields", func(t *testing.T) {
+		rec := moveProduct("/api/v1/products/1/category", `{"category_id": 2}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var updated response.MutationResponse[response.ProductResponse]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+		assert.Equal(t, int64(2), updated.Data.CategoryID)
+		assert.Equal(t, "AirFryer", updated.Data.Name)
+		assert.Equal(t, decimal.NewFromInt(10), updated.Data.Discount)
+	})
+
+	t.Run("Should return 404 for an unknown category", func(t *testing.T) {
+		rec := moveProduct("/api/v1/products/1/category", `{"category_id": 99}`)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Should move the product into Uncategorized when patched with category 0", func(t *testing.T) {
+		e := echo.New()
+		settings := service.DefaultProductSettings
+		settings.UncategorizedCategoryId = 3
+		productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+			{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1},
+		}), settings)
+		controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/products/1", strings.NewReader(`{"version": 0, "category_id": 0}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var updated response.MutationResponse[response.ProductResponse]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+		assert.Equal(t, int64(3), updated.Data.CategoryID)
+	})
+
+	t.Run("Should return 404 for an unknown product", func(t *testing.T) {
+		rec := moveProduct("/api/v1/products/42/category", `{"category_id": 2}`)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Should reject a missing category id", func(t *testing.T) {
+		rec := moveProduct("/api/v1/products/1/category", `{}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func Test_GetProductsByIds(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+		{Id: 3, Name: "Lambader", Price: decimal.NewFromInt(2000), Store: "Dekorasyon Sarayı"},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, 4, true).RegisterRoutes(e)
+
+	getByIds := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products/by-ids"+query, nil))
+		return rec
+	}
+
+	t.Run("Should keep the requested order and skip unknown ids", func(t *testing.T) {
+		rec := getByIds("?ids=3,99,1,2")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var products []response.ProductResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &products))
+		names := make([]string, len(products))
+		for i, product := range products {
+			names[i] = product.Name
+		}
+		assert.Equal(t, []string{"Lambader", "AirFryer", "Ütü"}, names)
+	})
+
+	t.Run("Should return an empty array when no id matches", func(t *testing.T) {
+		rec := getByIds("?ids=98,99")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `[]`, rec.Body.String())
+	})
+
+	t.Run("Should reject missing or malformed ids", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, getByIds("").Code)
+		assert.Equal(t, http.StatusBadRequest, getByIds("?ids=1,abc").Code)
+		assert.Equal(t, http.StatusBadRequest, getByIds("?ids=1,-2").Code)
+	})
+
+	t.Run("Should reject more ids than the maximum batch size", func(t *testing.T) {
+		rec := getByIds("?ids=1,2,3,4,5")
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Contains(t, rec.Body.String(), "at most 4 ids")
+	})
+}
+
+func Test_DeleteProductsByIds_BatchSize(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+		{Id: 3, Name: "Lambader", Price: decimal.NewFromInt(2000), Store: "Dekorasyon Sarayı"},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, 2, true).RegisterRoutes(e)
+	token, _ := middleware.GenerateToken(1, "admin", "admin@example.com", domain.RoleAdmin)
+
+	deleteBatch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/products?dryRun=true", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should accept a batch at the limit", func(t *testing.T) {
+		rec := deleteBatch(`[1, 2]`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"deleted": 2, "not_found_ids": []}`, rec.Body.String())
+	})
+
+	t.Run("Should reject a batch one over the limit", func(t *testing.T) {
+		rec := deleteBatch(`[1, 2, 3]`)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Contains(t, rec.Body.String(), "split the request into smaller batches")
+		assert.Len(t, productService.GetAllProducts(), 3)
+	})
+
+	t.Run("Should stop reading once the batch is over the limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusRequestEntityTooLarge, deleteBatch(`[1, 2, 3, not json`).Code)
+	})
+
+	t.Run("Should still reject a body that is not an array", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, deleteBatch(`{"ids": [1]}`).Code)
+	})
+}
+
+func Test_ClearProductCategory(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH", CategoryID: 1},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+	token, _ := middleware.GenerateToken(1, "demo", "demo@example.com", domain.RoleUser)
+
+	clearCategory := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should take the product out of its category", func(t *testing.T) {
+		rec := clearCategory("/api/v1/products/1/category")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var updated response.MutationResponse[response.ProductResponse]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+		assert.Equal(t, int64(0), updated.Data.CategoryID)
+		assert.Equal(t, "AirFryer", updated.Data.Name)
+
+		listRec := httptest.NewRecorder()
+		e.ServeHTTP(listRec, httptest.NewRequest(http.MethodGet, "/api/v1/categories/1/products", nil))
+		assert.Equal(t, http.StatusOK, listRec.Code)
+		var page response.Page[response.ProductResponse]
+		assert.NoError(t, json.Unmarshal(listRec.Body.Bytes(), &page))
+		assert.Len(t, page.Items, 1)
+		assert.Equal(t, "Ütü", page.Items[0].Name)
+	})
+
+	t.Run("Should return 404 for an unknown product", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, clearCategory("/api/v1/products/42/category").Code)
+	})
+
+	t.Run("Should reject an invalid product id", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, clearCategory("/api/v1/products/abc/category").Code)
+	})
+}
+
+func Test_AddProductImages(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+	token, _ := middleware.GenerateToken(1, "demo", "demo@example.com", domain.RoleUser)
+
+	addImages := func(path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should return the image metadata with the product", func(t *testing.T) {
+		rec := addImages("/api/v1/products/1/images",
+			`{"images": [{"url": "https://example.com/front.webp", "width": 1200, "height": 800, "mime_type": "image/webp"}, {"url": "https://example.com/side.jpg"}]}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var updated response.MutationResponse[response.ProductResponse]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+		assert.Equal(t, []string{"https://example.com/front.webp", "https://example.com/side.jpg"}, updated.Data.ImageUrls)
+		assert.Equal(t, 1200, *updated.Data.Images[0].Width)
+		assert.Equal(t, "image/webp", *updated.Data.Images[0].MimeType)
+		assert.Nil(t, updated.Data.Images[1].Width)
+	})
+
+	t.Run("Should reject a non-positive width", func(t *testing.T) {
+		rec := addImages("/api/v1/products/1/images", `{"images": [{"url": "https://example.com/x.jpg", "width": -1}]}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("Should reject images beyond the per-product cap", func(t *testing.T) {
+		images := make([]string, 10)
+		for i := range images {
+			images[i] = fmt.Sprintf(`{"url": "https://example.com/extra-%d.jpg"}`, i)
+		}
+		rec := addImages("/api/v1/products/1/images", `{"images": [`+strings.Join(images, ",")+`]}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), "at most 10 images")
+	})
+}
+
+func Test_CountProductsByCategory(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH", CategoryID: 1},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	countProducts := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("Should return the count only", func(t *testing.T) {
+		rec := countProducts("/api/v1/categories/1/products/count")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"count": 2}`, rec.Body.String())
+	})
+
+	t.Run("Should return zero for an empty category", func(t *testing.T) {
+		rec := countProducts("/api/v1/categories/2/products/count")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"count": 0}`, rec.Body.String())
+	})
+
+	t.Run("Should return 404 for an unknown category", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, countProducts("/api/v1/categories/99/products/count").Code)
+	})
+}
+
+func Test_FeaturedProducts(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+	adminToken, _ := middleware.GenerateToken(1, "admin", "admin@example.com", domain.RoleAdmin)
+	userToken, _ := middleware.GenerateToken(2, "demo", "demo@example.com", domain.RoleUser)
+
+	setFeatured := func(path string, token string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	getFeatured := func() []response.ProductResponse {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products/featured", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var featured []response.ProductResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &featured))
+		return featured
+	}
+
+	t.Run("Should let an admin feature a product", func(t *testing.T) {
+		rec := setFeatured("/api/v1/products/2/featured", adminToken, `{"featured": true}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var updated response.MutationResponse[response.ProductResponse]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+		assert.True(t, updated.Data.IsFeatured)
+
+		featured := getFeatured()
+		assert.Len(t, featured, 1)
+		assert.Equal(t, "Ütü", featured[0].Name)
+	})
+
+	t.Run("Should let an admin unfeature a product", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, setFeatured("/api/v1/products/2/featured", adminToken, `{"featured": false}`).Code)
+		assert.Empty(t, getFeatured())
+	})
+
+	t.Run("Should forbid non-admins", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, setFeatured("/api/v1/products/2/featured", userToken, `{"featured": true}`).Code)
+	})
+
+	t.Run("Should require the featured field", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, setFeatured("/api/v1/products/2/featured", adminToken, `{}`).Code)
+	})
+
+	t.Run("Should return 404 for an unknown product", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, setFeatured("/api/v1/products/99/featured", adminToken, `{"featured": true}`).Code)
+	})
+}
+
+func Test_GetProductImages(t *testing.T) {
+	e := echo.New()
+	productRepository := testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH", CategoryID: 1},
+	})
+	assert.NoError(t, productRepository.AddProductImages(1, []domain.ProductImage{
+		{Url: "https://example.com/front.jpg", IsMain: true}, {Url: "https://example.com/side.jpg"},
+	}))
+	productService := service.NewProductService(productRepository, service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	getImages := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("Should list the images in display order", func(t *testing.T) {
+		rec := getImages("/api/v1/products/1/images")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var images []domain.ProductImage
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &images))
+		assert.Equal(t, []domain.ProductImage{
+			{Id: 1, Url: "https://example.com/front.jpg", IsMain: true, DisplayOrder: 0},
+			{Id: 2, Url: "https://example.com/side.jpg", DisplayOrder: 1},
+		}, images)
+	})
+
+	t.Run("Should return an empty array for a product without images", func(t *testing.T) {
+		rec := getImages("/api/v1/products/2/images")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `[]`, rec.Body.String())
+	})
+
+	t.Run("Should return 404 for a missing product", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, getImages("/api/v1/products/99/images").Code)
+	})
+
+	t.Run("Should reject an invalid id", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, getImages("/api/v1/products/abc/images").Code)
+	})
+}
+
+func Test_NormalizeImageOrder(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", Images: []domain.ProductImage{
+			{Id: 1, Url: "https://example.com/front.jpg", DisplayOrder: 0},
+			{Id: 2, Url: "https://example.com/back.jpg", DisplayOrder: 4},
+		}},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+	adminToken, _ := middleware.GenerateToken(1, "admin", "admin@example.com", domain.RoleAdmin)
+	userToken, _ := middleware.GenerateToken(2, "demo", "demo@example.com", domain.RoleUser)
+
+	normalize := func(path string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should require the admin role", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, normalize("/api/v1/products/1/images/normalize", userToken).Code)
+		assert.Equal(t, http.StatusForbidden, normalize("/api/v1/products/images/normalize", userToken).Code)
+	})
+
+	t.Run("Should return the compacted images", func(t *testing.T) {
+		rec := normalize("/api/v1/products/1/images/normalize", adminToken)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var images []domain.ProductImage
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &images))
+		assert.Equal(t, []domain.ProductImage{
+			{Id: 1, Url: "https://example.com/front.jpg", DisplayOrder: 0},
+			{Id: 2, Url: "https://example.com/back.jpg", DisplayOrder: 1},
+		}, images)
+	})
+
+	t.Run("Should return 404 for a missing product", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, normalize("/api/v1/products/99/images/normalize", adminToken).Code)
+	})
+
+	t.Run("Should report how many products the batch renumbered", func(t *testing.T) {
+		rec := normalize("/api/v1/products/images/normalize", adminToken)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"normalized_products": 0}`, rec.Body.String())
+	})
+}
+
+func Test_ToResponse_MainImage(t *testing.T) {
+	t.Run("Should use the flagged main image", func(t *testing.T) {
+		productResponse := response.ToResponse(domain.Product{
+			ImageUrls: []string{"https://example.com/front.jpg", "https://example.com/box.jpg"},
+			Images:    []domain.ProductImage{{Url: "https://example.com/front.jpg"}, {Url: "https://example.com/box.jpg", IsMain: true}},
+		})
+		assert.Equal(t, "https://example.com/box.jpg", *productResponse.MainImage)
+	})
+
+	t.Run("Should fall back to the first image in display order", func(t *testing.T) {
+		productResponse := response.ToResponse(domain.Product{
+			ImageUrls: []string{"https://example.com/front.jpg", "https://example.com/side.jpg"},
+			Images:    []domain.ProductImage{{Url: "https://example.com/front.jpg"}, {Url: "https://example.com/side.jpg"}},
+		})
+		assert.Equal(t, "https://example.com/front.jpg", *productResponse.MainImage)
+	})
+
+	t.Run("Should be null without images", func(t *testing.T) {
+		body, err := json.Marshal(response.ToResponse(domain.Product{}))
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), `"main_image":null`)
+	})
+}
+
+func Test_GetProductStats(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Discount: decimal.NewFromInt(10), Store: "ABC TECH", CategoryID: 1},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+
+	getStats := func(token string, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products/stats"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should return 403 for a non-admin user", func(t *testing.T) {
+		token, _ := middleware.GenerateToken(1, "demo", "demo@example.com", domain.RoleUser)
+		assert.Equal(t, http.StatusForbidden, getStats(token, "").Code)
+	})
+
+	t.Run("Should return the stats for an admin", func(t *testing.T) {
+		token, _ := middleware.GenerateToken(2, "admin", "admin@example.com", domain.RoleAdmin)
+		rec := getStats(token, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"total_products":1,"distinct_stores":1,"currency":"TRY","avg_price":"3000.00","discounted_products":1}`, rec.Body.String())
+	})
+
+	t.Run("Should average in the requested currency and reject unknown ones", func(t *testing.T) {
+		token, _ := middleware.GenerateToken(2, "admin", "admin@example.com", domain.RoleAdmin)
+		rec := getStats(token, "?currency=eur")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"total_products":1,"distinct_stores":1,"currency":"EUR","avg_price":"0.00","discounted_products":1}`, rec.Body.String())
+
+		assert.Equal(t, http.StatusBadRequest, getStats(token, "?currency=XYZ").Code)
+	})
+}
+
+func Test_ProductStatusRoutes(t *testing.T) {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH", CategoryID: 1, Status: domain.ProductStatusDraft},
+	}), service.DefaultProductSettings)
+	controller.NewProductController(productService, nil, nil, controller.DefaultProductPageSize, controller.DefaultMaxBatchSize, true).RegisterRoutes(e)
+	userToken, _ := middleware.GenerateToken(1, "demo", "demo@example.com", domain.RoleUser)
+	adminToken, _ := middleware.GenerateToken(2, "admin", "admin@example.com", domain.RoleAdmin)
+
+	send := func(method string, path string, token string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should list only active products publicly", func(t *testing.T) {
+		rec := send(http.MethodGet, "/api/v1/products", "", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"active"`)
+		assert.NotContains(t, rec.Body.String(), `"status":"draft"`)
+	})
+
+	t.Run("Should only let admins filter by status", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, send(http.MethodGet, "/api/v1/products?status=all", "", "").Code)
+		assert.Equal(t, http.StatusForbidden, send(http.MethodGet, "/api/v1/products?status=all", userToken, "").Code)
+		assert.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/api/v1/products?status=all", "garbage", "").Code)
+
+		rec := send(http.MethodGet, "/api/v1/products?status=draft", adminToken, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"Ütü"`)
+		assert.NotContains(t, rec.Body.String(), `"name":"AirFryer"`)
+
+		assert.Equal(t, http.StatusBadRequest, send(http.MethodGet, "/api/v1/products?status=archived", adminToken, "").Code)
+	})
+
+	t.Run("Should change the status of a product", func(t *testing.T) {
+		rec := send(http.MethodPut, "/api/v1/products/1/status", userToken, `{"status": "discontinued"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"discontinued"`)
+
+		assert.Equal(t, http.StatusBadRequest, send(http.MethodPut, "/api/v1/products/1/status", userToken, `{"status": "archived"}`).Code)
+		assert.Equal(t, http.StatusNotFound, send(http.MethodPut, "/api/v1/products/99/status", userToken, `{"status": "active"}`).Code)
+		assert.Equal(t, http.StatusUnauthorized, send(http.MethodPut, "/api/v1/products/1/status", "", `{"status": "active"}`).Code)
+	})
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"product-app/common/decimal"
+	"product-app/controller"
+	"product-app/controller/response"
+	"product-app/domain"
+	"product-app/middleware"
+	"product-app/service"
+	testservice "product-app/test/service"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ReviewEndpoints(t *testing.T) {
+	newServer := func() *echo.Echo {
+		e := echo.New()
+		productRepo := testservice.NewFakeProductRepository([]domain.Product{
+			{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		})
+		reviewService := service.NewReviewService(testservice.NewFakeReviewRepository(), productRepo)
+		controller.NewReviewController(reviewService).RegisterRoutes(e)
+		return e
+	}
+	token, _ := middleware.GenerateToken(7, "demo", "demo@example.com", domain.RoleUser)
+
+	addReview := func(e *echo.Echo, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	listReviews := func(e *echo.Echo, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should accept ratings between 1 and 5 and reject others with 422", func(t *testing.T) {
+		e := newServer()
+		for body, expected := range map[string]int{
+			`{"rating": 0}`: http.StatusUnprocessableEntity,
+			`{"rating": 1}`: http.StatusCreated,
+			`{"rating": 5}`: http.StatusCreated,
+			`{"rating": 6}`: http.StatusUnprocessableEntity,
+		} {
+			assert.Equal(t, expected, addReview(e, "/api/v1/products/1/reviews", body).Code, body)
+		}
+	})
+
+	t.Run("Should replace the caller's earlier review", func(t *testing.T) {
+		e := newServer()
+		assert.Equal(t, http.StatusCreated, addReview(e, "/api/v1/products/1/reviews", `{"rating": 2, "comment": "Too loud"}`).Code)
+		assert.Equal(t, http.StatusCreated, addReview(e, "/api/v1/products/1/reviews", `{"rating": 4, "comment": "Got used to it"}`).Code)
+
+		rec := listReviews(e, "/api/v1/products/1/reviews")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var list response.ReviewListResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+		assert.Equal(t, 1, list.ReviewCount)
+		assert.Equal(t, 4.0, list.AverageRating)
+		assert.Equal(t, "Got used to it", list.Reviews[0].Comment)
+		assert.Equal(t, int64(7), list.Reviews[0].UserId)
+	})
+
+	t.Run("Should return 404 for a missing product", func(t *testing.T) {
+		e := newServer()
+		assert.Equal(t, http.StatusNotFound, addReview(e, "/api/v1/products/5/reviews", `{"rating": 4}`).Code)
+		assert.Equal(t, http.StatusNotFound, listReviews(e, "/api/v1/products/5/reviews").Code)
+	})
+
+	t.Run("Should return 401 without a token", func(t *testing.T) {
+		e := newServer()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/products/1/reviews", strings.NewReader(`{"rating": 4}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"product-app/common/decimal"
+	"product-app/controller"
+	"product-app/controller/response"
+	"product-app/domain"
+	"product-app/service"
+	testservice "product-app/test/service"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSearchServer() *echo.Echo {
+	e := echo.New()
+	productService := service.NewProductService(testservice.NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Description: "Yağsız fritöz", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		{Id: 2, Name: "Ütü", Description: "Buharlı ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+		{Id: 3, Name: "Fritöz Sepeti", Description: "AirFryer aksesuarı", Price: decimal.NewFromInt(200), Store: "ABC TECH"},
+	}), service.DefaultProductSettings)
+	categoryService := service.NewCategoryService(testservice.NewFakeCategoryRepository([]domain.Category{
+		{Id: 1, Name: "Home Appliances", Description: "AirFryer, ütü ve diğerleri"},
+		{Id: 2, Name: "Books", Description: "Books and educational materials"},
+	}), service.DefaultCategorySettings)
+	controller.NewSearchController(productService, categoryService, 1).RegisterRoutes(e)
+	return e
+}
+
+func search(e *echo.Echo, query string) (*httptest.ResponseRecorder, response.SearchResponse) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q="+query, nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var searchResponse response.SearchResponse
+	json.Unmarshal(rec.Body.Bytes(), &searchResponse)
+	return rec, searchResponse
+}
+
+func Test_Search(t *testing.T) {
+	e := newSearchServer()
+
+	t.Run("Should return both sections limited to the configured size", func(t *testing.T) {
+		rec, searchResponse := search(e, "airfryer")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, searchResponse.Products, 1)
+		assert.Equal(t, "AirFryer", searchResponse.Products[0].Name)
+		assert.Len(t, searchResponse.Categories, 1)
+	})
+
+	t.Run("Should return empty sections rather than null", func(t *testing.T) {
+		rec, _ := search(e, "telefon")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"products": [], "categories": []}`, rec.Body.String())
+	})
+
+	t.Run("Should reject an empty query", func(t *testing.T) {
+		rec, _ := search(e, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
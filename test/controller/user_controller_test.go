@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"product-app/controller"
+	"product-app/controller/response"
+	"product-app/domain"
+	"product-app/middleware"
+	"product-app/service"
+	testservice "product-app/test/service"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CheckAvailability(t *testing.T) {
+	e := echo.New()
+	userService := service.NewUserService(testservice.NewFakeUserRepository(), service.DefaultPasswordHashParams)
+	controller.NewUserController(userService).RegisterRoutes(e)
+
+	checkAvailability := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/available"+query, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should report availability and leave unchecked values null", func(t *testing.T) {
+		rec := checkAvailability("?username=demo")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"username_available": true, "email_available": null}`, rec.Body.String())
+	})
+
+	t.Run("Should throttle repeated checks from the same client", func(t *testing.T) {
+		var rec *httptest.ResponseRecorder
+		for i := 0; i < 50; i++ {
+			rec = checkAvailability("?email=demo@example.com")
+		}
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	})
+}
+
+func Test_UserResponse_OmitsPasswordHash(t *testing.T) {
+	passwordHash := "$argon2id$v=19$m=65536,t=1,p=4$c2FsdA$aGFzaA"
+
+	t.Run("Should not serialize the hash of a mapped user", func(t *testing.T) {
+		body, err := json.Marshal(response.ToUserResponse(domain.User{Id: 1, Username: "demo", PasswordHash: passwordHash}))
+		assert.NoError(t, err)
+		assert.NotContains(t, string(body), "password")
+		assert.NotContains(t, string(body), passwordHash)
+	})
+
+	t.Run("Should return the registered user without the hash", func(t *testing.T) {
+		e := echo.New()
+		userService := service.NewUserService(testservice.NewFakeUserRepository(), service.DefaultPasswordHashParams)
+		controller.NewUserController(userService).RegisterRoutes(e)
+
+		body := `{"username": "demo", "email": "demo@example.com", "password": "demo123", "first_name": "Demo", "last_name": "User"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var registered response.MutationResponse[response.UserResponse]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &registered))
+		assert.Equal(t, int64(1), registered.Id)
+		assert.Equal(t, "demo", registered.Data.Username)
+		assert.NotContains(t, rec.Body.String(), "password")
+	})
+
+	t.Run("Should not return the hash on login", func(t *testing.T) {
+		e := echo.New()
+		fakeRepo := testservice.NewFakeUserRepository()
+		userService := service.NewUserService(fakeRepo, service.DefaultPasswordHashParams)
+		controller.NewUserController(userService).RegisterRoutes(e)
+		_, err := userService.Register("demo", "demo@example.com", "demo123", "Demo", "User")
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"username_or_email": "demo", "password": "demo123"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"username":"demo"`)
+		assert.NotContains(t, rec.Body.String(), "password")
+		assert.NotContains(t, rec.Body.String(), "$argon2id$")
+	})
+}
+
+func Test_GetCurrentUser(t *testing.T) {
+	e := echo.New()
+	userService := service.NewUserService(testservice.NewFakeUserRepository(), service.DefaultPasswordHashParams)
+	controller.NewUserController(userService).RegisterRoutes(e)
+	user, err := userService.Register("demo", "demo@example.com", "demo123", "Demo", "User")
+	assert.NoError(t, err)
+
+	getMe := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should return the token's user", func(t *testing.T) {
+		token, _ := middleware.GenerateToken(user.Id, user.Username, user.Email, user.Role)
+		rec := getMe(token)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var me response.UserResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &me))
+		assert.Equal(t, user.Id, me.Id)
+		assert.Equal(t, "demo", me.Username)
+		assert.NotContains(t, rec.Body.String(), "password")
+	})
+
+	t.Run("Should return 401 without a token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, getMe("").Code)
+	})
+
+	t.Run("Should return 404 once the user is deleted", func(t *testing.T) {
+		token, _ := middleware.GenerateToken(99, "gone", "gone@example.com", domain.RoleUser)
+		assert.Equal(t, http.StatusNotFound, getMe(token).Code)
+	})
+}
+
+func Test_AuthRequestValidation(t *testing.T) {
+	e := echo.New()
+	userService := service.NewUserService(testservice.NewFakeUserRepository(), service.DefaultPasswordHashParams)
+	controller.NewUserController(userService).RegisterRoutes(e)
+
+	post := func(path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	fieldErrors := func(t *testing.T, rec *httptest.ResponseRecorder) map[string]string {
+		var body struct {
+			Fields map[string]string `json:"fields"`
+		}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body.Fields
+	}
+
+	t.Run("Should list every missing register field for an empty body", func(t *testing.T) {
+		rec := post("/api/v1/auth/register", `{}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, map[string]string{
+			"username": "username is required",
+			"email":    "email is required",
+			"password": "password is required",
+		}, fieldErrors(t, rec))
+	})
+
+	t.Run("Should report only the failing fields of a partial register body", func(t *testing.T) {
+		rec := post("/api/v1/auth/register", `{"username": "   ", "email": "not-an-email", "password": "demo123"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, map[string]string{
+			"username": "username is required",
+			"email":    "invalid email format",
+		}, fieldErrors(t, rec))
+	})
+
+	t.Run("Should trim the username and email on register", func(t *testing.T) {
+		rec := post("/api/v1/auth/register",
+			`{"username": "  demo ", "email": " demo@example.com ", "password": "demo123", "first_name": "Demo", "last_name": "User"}`)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var created response.MutationResponse[response.UserResponse]
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+		assert.Equal(t, "demo", created.Data.Username)
+		assert.Equal(t, "demo@example.com", created.Data.Email)
+	})
+
+	t.Run("Should list every missing login field for an empty body", func(t *testing.T) {
+		rec := post("/api/v1/auth/login", `{}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, map[string]string{
+			"username_or_email": "username or email is required",
+			"password":          "password is required",
+		}, fieldErrors(t, rec))
+	})
+
+	t.Run("Should reject a login without a password", func(t *testing.T) {
+		rec := post("/api/v1/auth/login", `{"username_or_email": "demo"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, map[string]string{"password": "password is required"}, fieldErrors(t, rec))
+	})
+
+	t.Run("Should log in with a padded username", func(t *testing.T) {
+		rec := post("/api/v1/auth/login", `{"username_or_email": " demo ", "password": "demo123"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
@@ -0,0 +1,146 @@
+package infrastructure
+
+import (
+	"product-app/domain"
+	"product-app/persistence"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func addTestCategory(t *testing.T, categoryRepository persistence.ICategoryRepository, slug string) int64 {
+	now := time.Now()
+	categoryId, err := categoryRepository.AddCategory(domain.Category{Name: slug, Slug: slug, CreatedAt: now, UpdatedAt: now})
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		dbPool.Exec(ctx, "UPDATE products SET category_id = NULL WHERE category_id = $1", categoryId)
+		dbPool.Exec(ctx, "DELETE FROM categories WHERE id = $1", categoryId)
+	})
+	return categoryId
+}
+
+func productCategoryId(t *testing.T, productId int64) *int64 {
+	var categoryId *int64
+	assert.NoError(t, dbPool.QueryRow(ctx, "SELECT category_id FROM products WHERE id = $1", productId).Scan(&categoryId))
+	return categoryId
+}
+
+func TestReassignProductsAndDelete(t *testing.T) {
+	setup(ctx, dbPool)
+	categoryRepository := persistence.NewCategoryRepository(persistence.SinglePool(dbPool), persistence.DefaultReadRetryPolicy)
+
+	t.Run("ReassignProductsAndDelete", func(t *testing.T) {
+		fromId := addTestCategory(t, categoryRepository, "force-delete-source")
+		toId := addTestCategory(t, categoryRepository, "force-delete-target")
+		_, err := dbPool.Exec(ctx, "UPDATE products SET category_id = $1 WHERE id IN (1, 2)", fromId)
+		assert.NoError(t, err)
+
+		reassigned, err := categoryRepository.ReassignProductsAndDelete(fromId, toId)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), reassigned)
+		assert.Equal(t, &toId, productCategoryId(t, 1))
+		assert.Equal(t, &toId, productCategoryId(t, 2))
+
+		_, err = categoryRepository.GetById(fromId)
+		assert.ErrorIs(t, err, domain.ErrCategoryNotFound)
+	})
+
+	t.Run("ReassignProductsAndDeleteRollsBackWhenDeleteFails", func(t *testing.T) {
+		fromId := addTestCategory(t, categoryRepository, "rollback-source")
+		toId := addTestCategory(t, categoryRepository, "rollback-target")
+		_, err := dbPool.Exec(ctx, "UPDATE products SET category_id = $1 WHERE id IN (1, 2)", fromId)
+		assert.NoError(t, err)
+		before, _ := productRepository.GetById(1)
+
+		// Fail the delete after the products were already moved inside the transaction
+		_, err = dbPool.Exec(ctx, `
+			CREATE FUNCTION fail_category_delete() RETURNS trigger AS $$
+			BEGIN
+				RAISE EXCEPTION 'category delete failed';
+			END;
+			$$ LANGUAGE plpgsql;
+			CREATE TRIGGER fail_category_delete BEFORE DELETE ON categories
+				FOR EACH ROW EXECUTE FUNCTION fail_category_delete();`)
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			dbPool.Exec(ctx, "DROP TRIGGER IF EXISTS fail_category_delete ON categories; DROP FUNCTION IF EXISTS fail_category_delete();")
+		})
+
+		_, err = categoryRepository.ReassignProductsAndDelete(fromId, toId)
+		assert.Error(t, err)
+
+		assert.Equal(t, &fromId, productCategoryId(t, 1))
+		assert.Equal(t, &fromId, productCategoryId(t, 2))
+		after, _ := productRepository.GetById(1)
+		assert.Equal(t, before.Version, after.Version)
+		_, err = categoryRepository.GetById(fromId)
+		assert.NoError(t, err)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestCategoryNameUniqueness(t *testing.T) {
+	setup(ctx, dbPool)
+	categoryRepository := persistence.NewCategoryRepository(persistence.SinglePool(dbPool), persistence.DefaultReadRetryPolicy)
+
+	categoryId := addTestCategory(t, categoryRepository, "unique-name")
+
+	t.Run("CategoryExistsByName", func(t *testing.T) {
+		exists, err := categoryRepository.CategoryExistsByName("  UNIQUE-NAME ", 0)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+
+		exists, err = categoryRepository.CategoryExistsByName("unique-name", categoryId)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("UniqueIndexRejectsNormalizedDuplicate", func(t *testing.T) {
+		now := time.Now()
+		_, err := categoryRepository.AddCategory(domain.Category{Name: "Unique-Name", Slug: "unique-name-2", CreatedAt: now, UpdatedAt: now})
+		assert.ErrorIs(t, err, domain.ErrCategoryNameTaken)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestSoftDeleteCategory(t *testing.T) {
+	setup(ctx, dbPool)
+	categoryRepository := persistence.NewCategoryRepository(persistence.SinglePool(dbPool), persistence.DefaultReadRetryPolicy)
+
+	categoryId := addTestCategory(t, categoryRepository, "soft-delete")
+	assert.NoError(t, productRepository.UpdateProductCategory(1, categoryId))
+
+	t.Run("DeleteByIdRefusesCategoryWithProducts", func(t *testing.T) {
+		assert.ErrorIs(t, categoryRepository.DeleteById(categoryId), domain.ErrCategoryNotEmpty)
+
+		_, err := categoryRepository.GetById(categoryId)
+		assert.NoError(t, err)
+	})
+
+	t.Run("DeleteByIdHidesCategory", func(t *testing.T) {
+		assert.NoError(t, productRepository.UpdateProductCategory(1, 0))
+		assert.NoError(t, categoryRepository.DeleteById(categoryId))
+
+		_, err := categoryRepository.GetById(categoryId)
+		assert.ErrorIs(t, err, domain.ErrCategoryNotFound)
+		for _, category := range categoryRepository.GetAllCategories() {
+			assert.NotEqual(t, categoryId, category.Id)
+		}
+		exists, err := productRepository.CategoryExists(categoryId)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+		assert.ErrorIs(t, categoryRepository.DeleteById(categoryId), domain.ErrCategoryNotFound)
+	})
+
+	t.Run("RestoreCategory", func(t *testing.T) {
+		assert.NoError(t, categoryRepository.RestoreCategory(categoryId))
+
+		category, err := categoryRepository.GetById(categoryId)
+		assert.NoError(t, err)
+		assert.Equal(t, "soft-delete", category.Slug)
+
+		assert.ErrorIs(t, categoryRepository.RestoreCategory(categoryId), domain.ErrCategoryNotFound)
+	})
+	clear(ctx, dbPool)
+}
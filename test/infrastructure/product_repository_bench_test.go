@@ -0,0 +1,88 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+const benchmarkProductCount = 200
+const benchmarkImagesPerProduct = 3
+
+func seedBenchmarkProducts(b *testing.B) {
+	b.Helper()
+	clear(ctx, dbPool)
+
+	for i := 0; i < benchmarkProductCount; i++ {
+		var productId int64
+		err := dbPool.QueryRow(ctx, `INSERT INTO products (name, slug, price, description, discount, store)
+			VALUES ($1, $2, 100, 'benchmark', 0, 'ABC TECH') RETURNING id`, fmt.Sprintf("Product %d", i), fmt.Sprintf("product-%d", i)).Scan(&productId)
+		if err != nil {
+			b.Fatalf("seeding product: %v", err)
+		}
+		for order := 0; order < benchmarkImagesPerProduct; order++ {
+			_, err := dbPool.Exec(ctx, `INSERT INTO product_images (product_id, image_urls, is_main_image, display_order)
+				VALUES ($1, $2, $3, $4)`, productId, fmt.Sprintf("https://example.com/%d/%d.jpg", productId, order), order == 0, order)
+			if err != nil {
+				b.Fatalf("seeding image: %v", err)
+			}
+		}
+	}
+}
+
+// loadImagesPerProduct reproduces the previous behaviour: one image query per product.
+func loadImagesPerProduct(ctx context.Context, dbPool *pgxpool.Pool) (int, error) {
+	rows, err := dbPool.Query(ctx, `SELECT id FROM products`)
+	if err != nil {
+		return 0, err
+	}
+	var productIds []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		productIds = append(productIds, id)
+	}
+	rows.Close()
+
+	imageCount := 0
+	for _, productId := range productIds {
+		imageRows, err := dbPool.Query(ctx, `SELECT image_urls FROM product_images WHERE product_id = $1 ORDER BY display_order`, productId)
+		if err != nil {
+			return 0, err
+		}
+		for imageRows.Next() {
+			imageCount++
+		}
+		imageRows.Close()
+	}
+	return imageCount, nil
+}
+
+func BenchmarkGetAllProducts_PerProductImageQueries(b *testing.B) {
+	seedBenchmarkProducts(b)
+	defer clear(ctx, dbPool)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := loadImagesPerProduct(ctx, dbPool); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetAllProducts_BatchedImageQuery(b *testing.B) {
+	seedBenchmarkProducts(b)
+	defer clear(ctx, dbPool)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if products := productRepository.GettAllProducts(); len(products) != benchmarkProductCount {
+			b.Fatalf("expected %d products, got %d", benchmarkProductCount, len(products))
+		}
+	}
+}
@@ -4,11 +4,18 @@ import (
 	"context"
 	"fmt"
 	"github.com/labstack/gommon/log"
+	"math"
 	"os"
+	"product-app/common/decimal"
 	"product-app/common/postgresql"
 	"product-app/domain"
 	"product-app/persistence"
+	"product-app/persistence/migration"
+	"product-app/service/model"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -31,7 +38,11 @@ func TestMain(m *testing.M) {
 		MaxConnectionIdleTime: "30s",
 	})
 
-	productRepository = persistence.NewProductRepository(dbPool)
+	if err := migration.Up(ctx, dbPool); err != nil {
+		log.Fatalf("Unable to apply migrations to test database: %v", err)
+	}
+
+	productRepository = persistence.NewProductRepository(persistence.SinglePool(dbPool), persistence.DefaultReadRetryPolicy, persistence.DefaultMaxImagesPerProduct)
 	fmt.Println("Before all tests")
 	exitCode := m.Run()
 	fmt.Println("After all tests")
@@ -44,7 +55,7 @@ func setup(ctx context.Context, dbPool *pgxpool.Pool) {
 }
 
 func clear(ctx context.Context, dbPool *pgxpool.Pool) {
-	_, err := dbPool.Exec(ctx, "TRUNCATE product_images, products RESTART IDENTITY CASCADE;")
+	_, err := dbPool.Exec(ctx, "TRUNCATE product_images, product_deletions, products RESTART IDENTITY CASCADE;")
 	if err != nil {
 		log.Printf("Error truncating tables: %v", err)
 	}
@@ -54,10 +65,10 @@ func TestGetAllProducts(t *testing.T) {
 	setup(ctx, dbPool)
 
 	expectedProducts := []domain.Product{
-		{Id: 1, Name: "AirFryer", Price: 3000, Description: "AirFryer açıklaması", Discount: 22, Store: "ABC TECH"},
-		{Id: 2, Name: "Ütü", Price: 3000, Description: "Ütü açıklaması", Discount: 10, Store: "ABC TECH"},
-		{Id: 3, Name: "Çamaşır Makinesi", Price: 3000, Description: "Çamaşır Makinesi açıklaması", Discount: 15, Store: "ABC TECH"},
-		{Id: 4, Name: "Lambader", Price: 3000, Description: "Lambader açıklaması", Discount: 0, Store: "Dekorasyon Sarayı"},
+		{Id: 1, Name: "AirFryer", Slug: "airfryer", Price: decimal.NewFromInt(3000), Currency: "TRY", Description: "AirFryer açıklaması", Discount: decimal.NewFromInt(22), Store: "ABC TECH", Version: 1},
+		{Id: 2, Name: "Ütü", Slug: "utu", Price: decimal.NewFromInt(3000), Currency: "TRY", Description: "Ütü açıklaması", Discount: decimal.NewFromInt(10), Store: "ABC TECH", Version: 1},
+		{Id: 3, Name: "Çamaşır Makinesi", Slug: "camasir-makinesi", Price: decimal.NewFromInt(3000), Currency: "TRY", Description: "Çamaşır Makinesi açıklaması", Discount: decimal.NewFromInt(15), Store: "ABC TECH", Version: 1},
+		{Id: 4, Name: "Lambader", Slug: "lambader", Price: decimal.NewFromInt(3000), Currency: "TRY", Description: "Lambader açıklaması", Discount: decimal.NewFromInt(0), Store: "Dekorasyon Sarayı", Version: 1},
 	}
 	t.Run("GetAllProducts", func(t *testing.T) {
 		actualProducts := productRepository.GettAllProducts()
@@ -72,8 +83,8 @@ func TestGetAllProductsByStore(t *testing.T) {
 	setup(ctx, dbPool)
 
 	expectedProducts := []domain.Product{
-		{Id: 1, Name: "AirFryer", Price: 3000, Description: "AirFryer açıklaması", Discount: 22, Store: "ABC TECH"},
-		{Id: 2, Name: "Ütü", Price: 1500, Description: "Ütü açıklaması", Discount: 10, Store: "ABC TECH"},
+		{Id: 1, Name: "AirFryer", Slug: "airfryer", Price: decimal.NewFromInt(3000), Currency: "TRY", Description: "AirFryer açıklaması", Discount: decimal.NewFromInt(22), Store: "ABC TECH", Version: 1},
+		{Id: 2, Name: "Ütü", Slug: "utu", Price: decimal.NewFromInt(1500), Currency: "TRY", Description: "Ütü açıklaması", Discount: decimal.NewFromInt(10), Store: "ABC TECH", Version: 1},
 	}
 	t.Run("GetAllProductsByStore", func(t *testing.T) {
 		actualProducts := productRepository.GetAllProductsByStore("ABC TECH")
@@ -81,15 +92,22 @@ func TestGetAllProductsByStore(t *testing.T) {
 		assert.Equal(t, expectedProducts, actualProducts, "Ürünler eşleşmeli")
 	})
 
+	t.Run("GetAllProductsByStoreUnknownStore", func(t *testing.T) {
+		actualProducts := productRepository.GetAllProductsByStore("Olmayan Mağaza")
+		assert.NotNil(t, actualProducts)
+		assert.Empty(t, actualProducts)
+	})
+
 	clear(ctx, dbPool)
 }
 
 func TestAddProduct(t *testing.T) {
 	newProduct := domain.Product{
 		Name:        "Phone",
-		Price:       3000.0,
+		Slug:        "phone",
+		Price:       decimal.NewFromInt(3000),
 		Description: "Hello, this is Apple phone",
-		Discount:    0.0,
+		Discount:    decimal.NewFromInt(0),
 		Store:       "Kırtasiye Merkezi",
 		ImageUrls:   []string{"https://example.com/iphone16-front.jpg"},
 	}
@@ -98,6 +116,16 @@ func TestAddProduct(t *testing.T) {
 		actualProducts := productRepository.GettAllProducts()
 		assert.Equal(t, 1, len(actualProducts))
 	})
+	t.Run("AddProductWithLongDescription", func(t *testing.T) {
+		longProduct := newProduct
+		longProduct.Slug = "phone-long"
+		longProduct.Description = strings.Repeat("a", 2000)
+		productId, err := productRepository.AddProduct(longProduct)
+		assert.NoError(t, err)
+		actualProduct, err := productRepository.GetById(productId)
+		assert.NoError(t, err)
+		assert.Len(t, actualProduct.Description, 2000)
+	})
 
 	clear(ctx, dbPool)
 }
@@ -109,10 +137,13 @@ func TestGetProductById(t *testing.T) {
 		expectedProduct := domain.Product{
 			Id:          1,
 			Name:        "AirFryer",
-			Price:       3000.0,
+			Slug:        "airfryer",
+			Price:       decimal.NewFromInt(3000),
+			Currency:    "TRY",
 			Description: "AirFryer açıklaması",
-			Discount:    22.0,
+			Discount:    decimal.NewFromInt(22),
 			Store:       "ABC TECH",
+			Version:     1,
 		}
 		assert.Equal(t, expectedProduct, actualProduct)
 		_, err := productRepository.GetById(5)
@@ -122,6 +153,417 @@ func TestGetProductById(t *testing.T) {
 	clear(ctx, dbPool)
 }
 
+func TestGetByIdWithCategory(t *testing.T) {
+	setup(ctx, dbPool)
+	t.Run("GetByIdWithCategory", func(t *testing.T) {
+		actualProduct, err := productRepository.GetByIdWithCategory(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "AirFryer", actualProduct.Name)
+		assert.Nil(t, actualProduct.Category)
+
+		_, err = productRepository.GetByIdWithCategory(5)
+		assert.ErrorIs(t, err, domain.ErrProductNotFound)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestFindByPriceRange(t *testing.T) {
+	setup(ctx, dbPool)
+	minPrice, maxPrice := decimal.NewFromInt(1000), decimal.NewFromInt(2500)
+	t.Run("FindByPriceRange", func(t *testing.T) {
+		products, total, err := productRepository.Find(model.ProductFilter{Currency: "TRY", MinPrice: &minPrice, MaxPrice: &maxPrice})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, "Ütü", products[0].Name)
+
+		products, _, err = productRepository.Find(model.ProductFilter{Currency: "USD", MinPrice: &minPrice})
+		assert.NoError(t, err)
+		assert.Empty(t, products)
+
+		_, _, err = productRepository.Find(model.ProductFilter{MinPrice: &minPrice, MaxPrice: &maxPrice})
+		assert.ErrorIs(t, err, persistence.ErrPriceRangeWithoutCurrency)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestGetByIdImageOrder(t *testing.T) {
+	setup(ctx, dbPool)
+	t.Run("GetByIdImageOrder", func(t *testing.T) {
+		// Insert out of order; the two display_order 1 images must come back by id
+		for _, image := range []struct {
+			url          string
+			displayOrder int
+		}{
+			{"https://example.com/airfryer-back.jpg", 2},
+			{"https://example.com/airfryer-front.jpg", 0},
+			{"https://example.com/airfryer-side.jpg", 1},
+			{"https://example.com/airfryer-top.jpg", 1},
+		} {
+			_, err := dbPool.Exec(ctx, `INSERT INTO product_images (product_id, image_urls, display_order) VALUES (1, $1, $2)`,
+				image.url, image.displayOrder)
+			assert.NoError(t, err)
+		}
+
+		actualProduct, err := productRepository.GetById(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"https://example.com/airfryer-front.jpg",
+			"https://example.com/airfryer-side.jpg",
+			"https://example.com/airfryer-top.jpg",
+			"https://example.com/airfryer-back.jpg",
+		}, actualProduct.ImageUrls)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestNormalizeImageOrder(t *testing.T) {
+	setup(ctx, dbPool)
+	insertImages := func(productId int64, displayOrders ...int) {
+		for i, displayOrder := range displayOrders {
+			_, err := dbPool.Exec(ctx, `INSERT INTO product_images (product_id, image_urls, display_order) VALUES ($1, $2, $3)`,
+				productId, fmt.Sprintf("https://example.com/%d-%d.jpg", productId, i), displayOrder)
+			assert.NoError(t, err)
+		}
+	}
+	displayOrders := func(productId int64) map[string]int {
+		images, err := productRepository.GetProductImages(productId)
+		assert.NoError(t, err)
+		orders := map[string]int{}
+		for _, image := range images {
+			orders[image.Url] = image.DisplayOrder
+		}
+		return orders
+	}
+
+	t.Run("NormalizeImageOrder", func(t *testing.T) {
+		insertImages(1, 5, 0, 2)
+		insertImages(2, 0, 3)
+
+		assert.NoError(t, productRepository.NormalizeImageOrder(1))
+		assert.Equal(t, map[string]int{"https://example.com/1-1.jpg": 0, "https://example.com/1-2.jpg": 1, "https://example.com/1-0.jpg": 2}, displayOrders(1))
+		assert.Equal(t, map[string]int{"https://example.com/2-0.jpg": 0, "https://example.com/2-1.jpg": 3}, displayOrders(2))
+
+		assert.ErrorIs(t, productRepository.NormalizeImageOrder(999), domain.ErrProductNotFound)
+	})
+
+	t.Run("NormalizeAllImageOrders", func(t *testing.T) {
+		normalized, err := productRepository.NormalizeAllImageOrders()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), normalized)
+		assert.Equal(t, map[string]int{"https://example.com/2-0.jpg": 0, "https://example.com/2-1.jpg": 1}, displayOrders(2))
+
+		normalized, err = productRepository.NormalizeAllImageOrders()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), normalized)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestAddProductImages(t *testing.T) {
+	setup(ctx, dbPool)
+	t.Run("AddProductImages", func(t *testing.T) {
+		cappedRepository := persistence.NewProductRepository(persistence.SinglePool(dbPool), persistence.DefaultReadRetryPolicy, 2)
+
+		width, height, mimeType := 1200, 800, "image/webp"
+		err := cappedRepository.AddProductImages(1, []domain.ProductImage{
+			{Url: "https://example.com/airfryer-front.jpg", Width: &width, Height: &height, MimeType: &mimeType},
+		})
+		assert.NoError(t, err)
+		err = cappedRepository.AddProductImages(1, []domain.ProductImage{
+			{Url: "https://example.com/airfryer-side.jpg"}, {Url: "https://example.com/airfryer-back.jpg"},
+		})
+		assert.ErrorIs(t, err, persistence.ErrTooManyImages)
+		assert.Contains(t, err.Error(), "at most 2 images")
+		err = cappedRepository.AddProductImages(1, []domain.ProductImage{{Url: "https://example.com/airfryer-side.jpg"}})
+		assert.NoError(t, err)
+
+		actualProduct, err := productRepository.GetById(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"https://example.com/airfryer-front.jpg",
+			"https://example.com/airfryer-side.jpg",
+		}, actualProduct.ImageUrls)
+		assert.Equal(t, []domain.ProductImage{
+			{Id: 1, Url: "https://example.com/airfryer-front.jpg", IsMain: true, DisplayOrder: 0, Width: &width, Height: &height, MimeType: &mimeType},
+			{Id: 2, Url: "https://example.com/airfryer-side.jpg", DisplayOrder: 1},
+		}, actualProduct.Images)
+	})
+	t.Run("AddAfterGappedOrders", func(t *testing.T) {
+		// Orders 2 and 3 remain after the main image at order 0 was removed
+		_, err := dbPool.Exec(ctx, `INSERT INTO product_images (product_id, image_urls, is_main_image, display_order) VALUES
+            (4, 'https://example.com/lamp-side.jpg', false, 2),
+            (4, 'https://example.com/lamp-back.jpg', false, 3)`)
+		assert.NoError(t, err)
+
+		assert.NoError(t, productRepository.AddProductImages(4, []domain.ProductImage{{Url: "https://example.com/lamp-top.jpg"}}))
+
+		images, err := productRepository.GetProductImages(4)
+		assert.NoError(t, err)
+		assert.Len(t, images, 3)
+		assert.Equal(t, "https://example.com/lamp-top.jpg", images[2].Url)
+		assert.Equal(t, 4, images[2].DisplayOrder)
+		assert.True(t, images[2].IsMain)
+	})
+	t.Run("ConcurrentUploadsRespectCap", func(t *testing.T) {
+		cappedRepository := persistence.NewProductRepository(persistence.SinglePool(dbPool), persistence.DefaultReadRetryPolicy, 2)
+
+		var wg sync.WaitGroup
+		errs := make([]error, 4)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = cappedRepository.AddProductImages(3, []domain.ProductImage{{Url: fmt.Sprintf("https://example.com/phone-%d.jpg", i)}})
+			}(i)
+		}
+		wg.Wait()
+
+		images, err := productRepository.GetProductImages(3)
+		assert.NoError(t, err)
+		assert.Len(t, images, 2)
+		rejected := 0
+		for _, err := range errs {
+			if err != nil {
+				assert.ErrorIs(t, err, persistence.ErrTooManyImages)
+				rejected++
+			}
+		}
+		assert.Equal(t, 2, rejected)
+	})
+	t.Run("ImageDimensionConstraint", func(t *testing.T) {
+		_, err := dbPool.Exec(ctx, `INSERT INTO product_images (product_id, image_urls, display_order, width) VALUES (2, 'https://example.com/iron.jpg', 0, 0)`)
+		assert.Error(t, err)
+	})
+	t.Run("ImageDisplayOrderRequired", func(t *testing.T) {
+		_, err := dbPool.Exec(ctx, `INSERT INTO product_images (product_id, image_urls, display_order) VALUES (2, 'https://example.com/iron.jpg', NULL)`)
+		assert.Error(t, err)
+	})
+	t.Run("ImageUrlLengthConstraint", func(t *testing.T) {
+		longUrl := "https://example.com/" + strings.Repeat("a", persistence.MaxImageUrlLength)
+		_, err := dbPool.Exec(ctx, `INSERT INTO product_images (product_id, image_urls, display_order) VALUES (2, $1, 0)`, longUrl)
+		assert.Error(t, err)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestGetProductsCreatedBetween(t *testing.T) {
+	setup(ctx, dbPool)
+	t.Run("GetProductsCreatedBetween", func(t *testing.T) {
+		weekStart := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+		_, err := dbPool.Exec(ctx, `UPDATE products SET created_at = $1`, weekStart.Add(-24*time.Hour))
+		assert.NoError(t, err)
+		_, err = dbPool.Exec(ctx, `UPDATE products SET created_at = $1 WHERE id = 2`, weekStart)
+		assert.NoError(t, err)
+
+		products, err := productRepository.GetProductsCreatedBetween(weekStart, weekStart.Add(7*24*time.Hour))
+		assert.NoError(t, err)
+		assert.Len(t, products, 1)
+		assert.Equal(t, int64(2), products[0].Id)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestGetAllProductsByUser(t *testing.T) {
+	setup(ctx, dbPool)
+	t.Run("GetAllProductsByUser", func(t *testing.T) {
+		var userId int64
+		err := dbPool.QueryRow(ctx, `INSERT INTO users (username, email, password, first_name, last_name)
+			VALUES ('seller', 'seller@example.com', 'x', 'Demo', 'Seller') RETURNING id`).Scan(&userId)
+		assert.NoError(t, err)
+		defer dbPool.Exec(ctx, `DELETE FROM users WHERE id = $1`, userId)
+
+		_, err = dbPool.Exec(ctx, `UPDATE products SET user_id = $1 WHERE id = 2`, userId)
+		assert.NoError(t, err)
+		_, err = dbPool.Exec(ctx, `INSERT INTO product_images (product_id, image_urls, display_order) VALUES (2, 'https://example.com/iron.jpg', 0)`)
+		assert.NoError(t, err)
+
+		products := productRepository.GetAllProductsByUser(userId)
+		assert.Len(t, products, 1)
+		assert.Equal(t, int64(2), products[0].Id)
+		assert.Equal(t, []string{"https://example.com/iron.jpg"}, products[0].ImageUrls)
+		assert.Empty(t, productRepository.GetAllProductsByUser(userId+1))
+
+		productId, err := productRepository.AddProduct(domain.Product{Name: "Süpürge", Slug: "supurge", Price: decimal.NewFromInt(2000), Currency: "TRY",
+			Description: "Süpürge açıklaması", Store: "ABC TECH", Status: domain.ProductStatusActive, UserId: userId})
+		assert.NoError(t, err)
+		assert.Len(t, productRepository.GetAllProductsByUser(userId), 2)
+
+		anonymousId, err := productRepository.AddProduct(domain.Product{Name: "Tost Makinesi", Slug: "tost-makinesi", Price: decimal.NewFromInt(800), Currency: "TRY",
+			Description: "Tost Makinesi açıklaması", Store: "ABC TECH", Status: domain.ProductStatusActive})
+		assert.NoError(t, err)
+		var ownerIds []*int64
+		rows, err := dbPool.Query(ctx, `SELECT user_id FROM products WHERE id IN ($1, $2) ORDER BY id`, productId, anonymousId)
+		assert.NoError(t, err)
+		for rows.Next() {
+			var ownerId *int64
+			assert.NoError(t, rows.Scan(&ownerId))
+			ownerIds = append(ownerIds, ownerId)
+		}
+		rows.Close()
+		assert.Equal(t, []*int64{&userId, nil}, ownerIds)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestGetProductImages(t *testing.T) {
+	setup(ctx, dbPool)
+	t.Run("GetProductImages", func(t *testing.T) {
+		_, err := dbPool.Exec(ctx, `INSERT INTO product_images (product_id, image_urls, is_main_image, display_order) VALUES
+			(1, 'https://example.com/airfryer-side.jpg', false, 1),
+			(1, 'https://example.com/airfryer-front.jpg', true, 0)`)
+		assert.NoError(t, err)
+
+		images, err := productRepository.GetProductImages(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.ProductImage{
+			{Id: 2, Url: "https://example.com/airfryer-front.jpg", IsMain: true, DisplayOrder: 0},
+			{Id: 1, Url: "https://example.com/airfryer-side.jpg", DisplayOrder: 1},
+		}, images)
+	})
+	t.Run("GetProductImagesWithoutImages", func(t *testing.T) {
+		images, err := productRepository.GetProductImages(2)
+		assert.NoError(t, err)
+		assert.NotNil(t, images)
+		assert.Empty(t, images)
+	})
+	t.Run("GetProductImagesOfMissingProduct", func(t *testing.T) {
+		_, err := productRepository.GetProductImages(99)
+		assert.ErrorIs(t, err, domain.ErrProductNotFound)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestCountProductsByCategory(t *testing.T) {
+	setup(ctx, dbPool)
+	categoryRepository := persistence.NewCategoryRepository(persistence.SinglePool(dbPool), persistence.DefaultReadRetryPolicy)
+	t.Run("CountProductsByCategory", func(t *testing.T) {
+		categoryId := addTestCategory(t, categoryRepository, "count-products")
+		_, err := dbPool.Exec(ctx, `UPDATE products SET category_id = $1 WHERE id IN (1, 2, 3)`, categoryId)
+		assert.NoError(t, err)
+		assert.NoError(t, productRepository.UpdateProductStatus(3, domain.ProductStatusDraft))
+
+		count, err := productRepository.CountProductsByCategory(categoryId)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+	t.Run("CountProductsByEmptyCategory", func(t *testing.T) {
+		categoryId := addTestCategory(t, categoryRepository, "count-products-empty")
+
+		count, err := productRepository.CountProductsByCategory(categoryId)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestUpdateProductCategory(t *testing.T) {
+	setup(ctx, dbPool)
+	categoryRepository := persistence.NewCategoryRepository(persistence.SinglePool(dbPool), persistence.DefaultReadRetryPolicy)
+	t.Run("UpdateProductCategory", func(t *testing.T) {
+		categoryId := addTestCategory(t, categoryRepository, "move-products")
+		assert.NoError(t, productRepository.UpdateProductCategory(1, categoryId))
+
+		product, err := productRepository.GetById(1)
+		assert.NoError(t, err)
+		assert.Equal(t, categoryId, product.CategoryID)
+	})
+	t.Run("ClearProductCategory", func(t *testing.T) {
+		assert.NoError(t, productRepository.UpdateProductCategory(1, 0))
+
+		product, err := productRepository.GetById(1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), product.CategoryID)
+	})
+	t.Run("PatchCategoryToZero", func(t *testing.T) {
+		categoryId := addTestCategory(t, categoryRepository, "patch-products")
+		assert.NoError(t, productRepository.UpdateProductCategory(2, categoryId))
+		product, _ := productRepository.GetById(2)
+
+		noCategory := int64(0)
+		assert.NoError(t, productRepository.UpdateProductPartial(2, model.ProductPatch{Version: product.Version, CategoryID: &noCategory}, 0))
+		product, err := productRepository.GetById(2)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), product.CategoryID)
+	})
+	t.Run("UpdateCategoryOfMissingProduct", func(t *testing.T) {
+		assert.ErrorIs(t, productRepository.UpdateProductCategory(99, 0), domain.ErrProductNotFound)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestGetByIdMainImage(t *testing.T) {
+	setup(ctx, dbPool)
+	t.Run("GetByIdMainImage", func(t *testing.T) {
+		// The flagged image wins even though another image comes first in display order
+		_, err := dbPool.Exec(ctx, `INSERT INTO product_images (product_id, image_urls, is_main_image, display_order) VALUES
+			(1, 'https://example.com/airfryer-front.jpg', false, 0),
+			(1, 'https://example.com/airfryer-box.jpg', true, 2),
+			(1, 'https://example.com/airfryer-side.jpg', NULL, 1),
+			(2, 'https://example.com/iron-side.jpg', false, 1),
+			(2, 'https://example.com/iron-front.jpg', false, 0)`)
+		assert.NoError(t, err)
+
+		airFryer, err := productRepository.GetById(1)
+		assert.NoError(t, err)
+		mainImage, ok := airFryer.MainImage()
+		assert.True(t, ok)
+		assert.Equal(t, "https://example.com/airfryer-box.jpg", mainImage)
+
+		iron, err := productRepository.GetById(2)
+		assert.NoError(t, err)
+		mainImage, ok = iron.MainImage()
+		assert.True(t, ok)
+		assert.Equal(t, "https://example.com/iron-front.jpg", mainImage)
+
+		withoutImages, err := productRepository.GetById(3)
+		assert.NoError(t, err)
+		_, ok = withoutImages.MainImage()
+		assert.False(t, ok)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestGetBySlug(t *testing.T) {
+	setup(ctx, dbPool)
+	t.Run("GetBySlug", func(t *testing.T) {
+		actualProduct, err := productRepository.GetBySlug("camasir-makinesi")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), actualProduct.Id)
+
+		_, err = productRepository.GetBySlug("unknown")
+		assert.ErrorIs(t, err, domain.ErrProductNotFound)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestProductStatus(t *testing.T) {
+	setup(ctx, dbPool)
+	t.Run("ProductStatus", func(t *testing.T) {
+		assert.NoError(t, productRepository.UpdateProductStatus(2, domain.ProductStatusDiscontinued))
+
+		activeProducts, total, err := productRepository.Find(model.ProductFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(activeProducts)), total)
+		for _, product := range activeProducts {
+			assert.NotEqual(t, int64(2), product.Id)
+			assert.Equal(t, domain.ProductStatusActive, product.Status)
+		}
+
+		discontinued, _, err := productRepository.Find(model.ProductFilter{Status: domain.ProductStatusDiscontinued})
+		assert.NoError(t, err)
+		assert.Len(t, discontinued, 1)
+
+		product, err := productRepository.GetById(2)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ProductStatusDiscontinued, product.Status)
+		assert.Equal(t, 2, product.Version)
+
+		_, err = dbPool.Exec(ctx, `UPDATE products SET status = 'archived' WHERE id = 1`)
+		assert.Error(t, err)
+	})
+	clear(ctx, dbPool)
+}
+
 func TestDeleteById(t *testing.T) {
 	setup(ctx, dbPool)
 	t.Run("DeleteById", func(t *testing.T) {
@@ -130,6 +572,88 @@ func TestDeleteById(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "product not found with id 1")
 	})
+	t.Run("DeleteByIdRemovesImages", func(t *testing.T) {
+		err := productRepository.AddProductImages(2, []domain.ProductImage{
+			{Url: "https://example.com/iron-front.jpg"}, {Url: "https://example.com/iron-side.jpg"},
+		})
+		assert.NoError(t, err)
+
+		assert.NoError(t, productRepository.DeleteById(2))
+
+		var remainingImages int
+		err = dbPool.QueryRow(ctx, `SELECT COUNT(*) FROM product_images WHERE product_id = 2`).Scan(&remainingImages)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, remainingImages)
+	})
+	t.Run("DeleteByIdKeepsImagesWhenProductDeleteFails", func(t *testing.T) {
+		err := productRepository.AddProductImages(3, []domain.ProductImage{{Url: "https://example.com/phone.jpg"}})
+		assert.NoError(t, err)
+
+		_, err = dbPool.Exec(ctx, `
+			CREATE FUNCTION fail_product_delete() RETURNS trigger AS $$
+			BEGIN
+				RAISE EXCEPTION 'product delete failed';
+			END;
+			$$ LANGUAGE plpgsql;
+			CREATE TRIGGER fail_product_delete BEFORE DELETE ON products
+				FOR EACH ROW EXECUTE FUNCTION fail_product_delete();`)
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			dbPool.Exec(ctx, "DROP TRIGGER IF EXISTS fail_product_delete ON products; DROP FUNCTION IF EXISTS fail_product_delete();")
+		})
+
+		assert.Error(t, productRepository.DeleteById(3))
+
+		actualProduct, err := productRepository.GetById(3)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"https://example.com/phone.jpg"}, actualProduct.ImageUrls)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestDeleteByIdsDryRun(t *testing.T) {
+	setup(ctx, dbPool)
+	t.Run("DeleteByIdsDryRun", func(t *testing.T) {
+		before := len(productRepository.GettAllProducts())
+
+		deleted, notFoundIds, err := productRepository.DeleteByIds([]int64{1, 2, 999}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+		assert.Equal(t, []int64{999}, notFoundIds)
+		assert.Len(t, productRepository.GettAllProducts(), before)
+		_, err = productRepository.GetById(1)
+		assert.NoError(t, err)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestProductStock(t *testing.T) {
+	setup(ctx, dbPool)
+	t.Run("UntrackedStock", func(t *testing.T) {
+		product, err := productRepository.GetById(1)
+		assert.NoError(t, err)
+		assert.Nil(t, product.Stock)
+	})
+	t.Run("AddProductWithStock", func(t *testing.T) {
+		stock := 4
+		productId, err := productRepository.AddProduct(domain.Product{Name: "Kettle", Slug: "kettle", Price: decimal.NewFromInt(800),
+			Store: "ABC TECH", Currency: "TRY", Status: domain.ProductStatusActive, Stock: &stock})
+		assert.NoError(t, err)
+
+		product, err := productRepository.GetById(productId)
+		assert.NoError(t, err)
+		assert.Equal(t, &stock, product.Stock)
+	})
+	t.Run("PatchStock", func(t *testing.T) {
+		product, _ := productRepository.GetById(1)
+		soldOut := 0
+		assert.NoError(t, productRepository.UpdateProductPartial(1, model.ProductPatch{Version: product.Version, Stock: &soldOut}, 0))
+
+		product, err := productRepository.GetById(1)
+		assert.NoError(t, err)
+		assert.Equal(t, &soldOut, product.Stock)
+		assert.Equal(t, domain.AvailabilityOutOfStock, product.Availability().Reason)
+	})
 	clear(ctx, dbPool)
 }
 
@@ -137,10 +661,36 @@ func TestUpdatePrice(t *testing.T) {
 	setup(ctx, dbPool)
 	t.Run("UpdatePrice", func(t *testing.T) {
 		productBeforeUpdate, _ := productRepository.GetById(1)
-		assert.Equal(t, float32(3000.0), productBeforeUpdate.Price)
-		productRepository.UpdatePrice(1, 4000.0)
+		assert.Equal(t, decimal.NewFromInt(3000), productBeforeUpdate.Price)
+		productRepository.UpdatePrice(1, decimal.NewFromInt(4000), productBeforeUpdate.Version, 0)
 		productAfterUpdate, _ := productRepository.GetById(1)
-		assert.Equal(t, float32(4000.0), productAfterUpdate.Price)
+		assert.Equal(t, decimal.NewFromInt(4000), productAfterUpdate.Price)
+
+		history, err := productRepository.GetPriceHistory(1)
+		assert.NoError(t, err)
+		assert.Len(t, history, 1)
+		assert.Equal(t, decimal.NewFromInt(3000), history[0].OldPrice)
+		assert.Equal(t, decimal.NewFromInt(4000), history[0].NewPrice)
+		assert.Nil(t, history[0].ChangedBy)
+	})
+	t.Run("UpdatePriceWithStaleVersion", func(t *testing.T) {
+		err := productRepository.UpdatePrice(2, decimal.NewFromInt(100), 99, 0)
+		assert.ErrorIs(t, err, domain.ErrProductVersionConflict)
+
+		history, err := productRepository.GetPriceHistory(2)
+		assert.NoError(t, err)
+		assert.Empty(t, history)
+	})
+	t.Run("UpdateProductPartialRecordsPriceChange", func(t *testing.T) {
+		productBeforeUpdate, _ := productRepository.GetById(2)
+		newPrice := decimal.NewFromInt(2500)
+		assert.NoError(t, productRepository.UpdateProductPartial(2, model.ProductPatch{Version: productBeforeUpdate.Version, Price: &newPrice}, 0))
+
+		history, err := productRepository.GetPriceHistory(2)
+		assert.NoError(t, err)
+		assert.Len(t, history, 1)
+		assert.Equal(t, productBeforeUpdate.Price, history[0].OldPrice)
+		assert.Equal(t, newPrice, history[0].NewPrice)
 	})
 	clear(ctx, dbPool)
 }
@@ -155,3 +705,104 @@ func TestDeleteAllProducts(t *testing.T) {
 	})
 	clear(ctx, dbPool)
 }
+
+func TestGetProductChanges(t *testing.T) {
+	setup(ctx, dbPool)
+	var lastSync time.Time
+	assert.NoError(t, dbPool.QueryRow(ctx, "SELECT MAX(updated_at) FROM products").Scan(&lastSync))
+	after := model.ChangeCursor{ChangedAt: lastSync, ProductId: math.MaxInt64}
+
+	t.Run("GetProductChangesHoldsBackRecentChanges", func(t *testing.T) {
+		assert.NoError(t, productRepository.UpdatePrice(3, decimal.NewFromInt(2500), 1, 0))
+		assert.NoError(t, productRepository.AttachTags(4, []string{"sync"}))
+		assert.NoError(t, productRepository.DeleteById(1))
+
+		changes, err := productRepository.GetProductChanges(after, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+	// Age the changes past the lag, keeping their order
+	_, err := dbPool.Exec(ctx, `UPDATE products SET updated_at = updated_at - interval '1 minute'`)
+	assert.NoError(t, err)
+	_, err = dbPool.Exec(ctx, `UPDATE product_deletions SET deleted_at = deleted_at - interval '1 minute'`)
+	assert.NoError(t, err)
+	after.ChangedAt = after.ChangedAt.Add(-time.Minute)
+
+	t.Run("GetProductChangesListsUpdatesAndDeletes", func(t *testing.T) {
+		changes, err := productRepository.GetProductChanges(after, 10)
+		assert.NoError(t, err)
+		assert.Len(t, changes, 3)
+		assert.Equal(t, []int64{3, 4, 1}, []int64{changes[0].ProductId, changes[1].ProductId, changes[2].ProductId})
+		assert.False(t, changes[0].Deleted)
+		assert.True(t, changes[2].Deleted)
+	})
+	t.Run("GetProductChangesRespectsLimit", func(t *testing.T) {
+		changes, err := productRepository.GetProductChanges(after, 1)
+		assert.NoError(t, err)
+		assert.Len(t, changes, 1)
+		assert.Equal(t, int64(3), changes[0].ProductId)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestFindCombinedFilters(t *testing.T) {
+	setup(ctx, dbPool)
+	categoryRepository := persistence.NewCategoryRepository(persistence.SinglePool(dbPool), persistence.DefaultReadRetryPolicy)
+	t.Run("FindCombinesSearchStoreAndCategory", func(t *testing.T) {
+		categoryId := addTestCategory(t, categoryRepository, "combined-filters")
+		_, err := dbPool.Exec(ctx, `UPDATE products SET category_id = $1 WHERE id IN (1, 2, 4)`, categoryId)
+		assert.NoError(t, err)
+
+		products, total, err := productRepository.Find(model.ProductFilter{Search: "Lambader", CategoryId: categoryId})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, int64(4), products[0].Id)
+
+		products, total, err = productRepository.Find(model.ProductFilter{
+			Search: "açıklaması", Store: "ABC TECH", CategoryId: categoryId, PageRequest: model.PageRequest{Limit: 1},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Len(t, products, 1)
+		assert.Equal(t, int64(1), products[0].Id)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestSetFeatured(t *testing.T) {
+	setup(ctx, dbPool)
+	t.Run("SetFeatured", func(t *testing.T) {
+		assert.NoError(t, productRepository.SetFeatured(2, true))
+		assert.NoError(t, productRepository.SetFeatured(4, true))
+		assert.NoError(t, productRepository.SetFeatured(4, false))
+
+		featured, _, err := productRepository.Find(model.ProductFilter{Featured: true})
+		assert.NoError(t, err)
+		assert.Len(t, featured, 1)
+		assert.Equal(t, int64(2), featured[0].Id)
+		assert.True(t, featured[0].IsFeatured)
+		assert.Equal(t, 2, featured[0].Version)
+	})
+	t.Run("SetFeaturedOfMissingProduct", func(t *testing.T) {
+		assert.ErrorIs(t, productRepository.SetFeatured(99, true), domain.ErrProductNotFound)
+	})
+	clear(ctx, dbPool)
+}
+
+func TestFindAfterId(t *testing.T) {
+	setup(ctx, dbPool)
+	t.Run("FindAfterIdListsFollowingProductsWithoutTotal", func(t *testing.T) {
+		afterId := int64(2)
+		products, total, err := productRepository.Find(model.ProductFilter{AfterId: &afterId, PageRequest: model.PageRequest{Limit: 1}})
+		assert.NoError(t, err)
+		assert.Len(t, products, 1)
+		assert.Equal(t, int64(3), products[0].Id)
+		assert.Equal(t, int64(0), total)
+	})
+	t.Run("FindAfterIdRejectsSort", func(t *testing.T) {
+		afterId := int64(0)
+		_, _, err := productRepository.Find(model.ProductFilter{AfterId: &afterId, PageRequest: model.PageRequest{Sort: "price_asc"}})
+		assert.Error(t, err)
+	})
+	clear(ctx, dbPool)
+}
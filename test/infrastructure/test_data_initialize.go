@@ -7,12 +7,12 @@ import (
 	"github.com/labstack/gommon/log"
 )
 
-var INSERT_PRODUCTS = `INSERT INTO products (name, price, description, discount, store) 
+var INSERT_PRODUCTS = `INSERT INTO products (name, slug, price, description, discount, store) 
 VALUES
-('AirFryer', 3000.0, 'AirFryer açıklaması', 22.0, 'ABC TECH'),
-('Ütü', 1500.0, 'Ütü açıklaması', 10.0, 'ABC TECH'),
-('Çamaşır Makinesi', 10000.0, 'Çamaşır Makinesi açıklaması', 15.0, 'ABC TECH'),
-('Lambader', 2000.0, 'Lambader açıklaması', 0.0, 'Dekorasyon Sarayı');`
+('AirFryer', 'airfryer', 3000.0, 'AirFryer açıklaması', 22.0, 'ABC TECH'),
+('Ütü', 'utu', 1500.0, 'Ütü açıklaması', 10.0, 'ABC TECH'),
+('Çamaşır Makinesi', 'camasir-makinesi', 10000.0, 'Çamaşır Makinesi açıklaması', 15.0, 'ABC TECH'),
+('Lambader', 'lambader', 2000.0, 'Lambader açıklaması', 0.0, 'Dekorasyon Sarayı');`
 
 func TestDataInitialize(ctx context.Context, dbPool *pgxpool.Pool) {
 	insertProductsResult, insertProductsErr := dbPool.Exec(ctx, INSERT_PRODUCTS)
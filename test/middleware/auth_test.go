@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"product-app/domain"
+	"product-app/middleware"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_JWTMiddleware_IssuerAndAudience(t *testing.T) {
+	t.Cleanup(func() {
+		middleware.ConfigureTokenClaims("", "")
+	})
+
+	e := echo.New()
+	e.GET("/private", func(c echo.Context) error { return c.NoContent(http.StatusOK) }, middleware.JWTMiddleware())
+
+	send := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/private", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	tokenFor := func(issuer string, audience string) string {
+		middleware.ConfigureTokenClaims(issuer, audience)
+		token, err := middleware.GenerateToken(1, "demo", "demo@example.com", domain.RoleUser)
+		assert.NoError(t, err)
+		return token
+	}
+
+	validToken := tokenFor("catalog-gateway", "product-api")
+	wrongIssuerToken := tokenFor("someone-else", "product-api")
+	wrongAudienceToken := tokenFor("catalog-gateway", "orders-api")
+	middleware.ConfigureTokenClaims("catalog-gateway", "product-api")
+
+	t.Run("Should accept a token with the configured issuer and audience", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send(validToken).Code)
+	})
+
+	t.Run("Should reject a token from another issuer", func(t *testing.T) {
+		rec := send(wrongIssuerToken)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Invalid or expired token")
+	})
+
+	t.Run("Should reject a token for another audience", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, send(wrongAudienceToken).Code)
+	})
+}
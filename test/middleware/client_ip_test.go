@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"product-app/middleware"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ClientIPExtractor(t *testing.T) {
+	newServer := func(t *testing.T, trustedProxies ...string) *echo.Echo {
+		e := echo.New()
+		extractor, err := middleware.ClientIPExtractor(trustedProxies)
+		assert.NoError(t, err)
+		e.IPExtractor = extractor
+		e.GET("/ip", func(c echo.Context) error { return c.String(http.StatusOK, c.RealIP()) },
+			middleware.RateLimit(1, time.Minute))
+		return e
+	}
+	send := func(e *echo.Echo, remoteAddr string, forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should ignore a forwarded header without trusted proxies", func(t *testing.T) {
+		rec := send(newServer(t), "203.0.113.7:5000", "198.51.100.1")
+		assert.Equal(t, "203.0.113.7", rec.Body.String())
+	})
+
+	t.Run("Should ignore a forwarded header sent by an untrusted client", func(t *testing.T) {
+		rec := send(newServer(t, "10.0.0.0/8"), "203.0.113.7:5000", "198.51.100.1")
+		assert.Equal(t, "203.0.113.7", rec.Body.String())
+	})
+
+	t.Run("Should read the client behind a trusted proxy", func(t *testing.T) {
+		rec := send(newServer(t, "10.0.0.0/8"), "10.1.2.3:5000", "198.51.100.1")
+		assert.Equal(t, "198.51.100.1", rec.Body.String())
+	})
+
+	t.Run("Should skip spoofed entries the client prepended", func(t *testing.T) {
+		rec := send(newServer(t, "10.0.0.5", "10.0.1.0/24"), "10.0.0.5:5000", "1.1.1.1, 198.51.100.1, 10.0.1.9")
+		assert.Equal(t, "198.51.100.1", rec.Body.String())
+	})
+
+	t.Run("Should rate limit clients behind the proxy separately", func(t *testing.T) {
+		e := newServer(t, "10.0.0.0/8")
+		assert.Equal(t, http.StatusOK, send(e, "10.1.2.3:5000", "198.51.100.1").Code)
+		assert.Equal(t, http.StatusOK, send(e, "10.1.2.3:5000", "198.51.100.2").Code)
+		assert.Equal(t, http.StatusTooManyRequests, send(e, "10.1.2.3:5000", "198.51.100.1").Code)
+	})
+
+	t.Run("Should reject invalid proxy entries", func(t *testing.T) {
+		for _, proxy := range []string{"10.0.0.0/33", "load-balancer"} {
+			_, err := middleware.ClientIPExtractor([]string{proxy})
+			assert.Error(t, err, proxy)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"product-app/middleware"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RequireJSON(t *testing.T) {
+	e := echo.New()
+	e.Use(middleware.RequireJSON("/upload"))
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.POST("/products", ok)
+	e.DELETE("/products/:id", ok)
+	e.POST("/upload", ok)
+
+	send := func(method string, path string, contentType string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set(echo.HeaderContentType, contentType)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should reject a form encoded body", func(t *testing.T) {
+		rec := send(http.MethodPost, "/products", echo.MIMEApplicationForm, "name=AirFryer&price=3000")
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	})
+
+	t.Run("Should reject a body without a content type", func(t *testing.T) {
+		rec := send(http.MethodPost, "/products", "", `{"name": "AirFryer"}`)
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	})
+
+	t.Run("Should accept JSON with a charset", func(t *testing.T) {
+		rec := send(http.MethodPost, "/products", "application/json; charset=utf-8", `{"name": "AirFryer"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Should let requests without a body through", func(t *testing.T) {
+		rec := send(http.MethodDelete, "/products/1", "", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Should skip exempt routes", func(t *testing.T) {
+		rec := send(http.MethodPost, "/upload", "multipart/form-data; boundary=x", "--x--")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
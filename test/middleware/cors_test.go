@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"product-app/middleware"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CORS(t *testing.T) {
+	newServer := func(maxAge int) *echo.Echo {
+		e := echo.New()
+		e.Use(middleware.CORS([]string{"https://shop.example.com"}, maxAge))
+		e.GET("/products", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+		return e
+	}
+	preflight := func(e *echo.Echo, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/products", nil)
+		req.Header.Set(echo.HeaderOrigin, origin)
+		req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodGet)
+		req.Header.Set(echo.HeaderAccessControlRequestHeaders, "Authorization")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Should let browsers cache the preflight for the configured time", func(t *testing.T) {
+		rec := preflight(newServer(600), "https://shop.example.com")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "https://shop.example.com", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+		assert.Equal(t, "Authorization", rec.Header().Get(echo.HeaderAccessControlAllowHeaders))
+		assert.Equal(t, "600", rec.Header().Get(echo.HeaderAccessControlMaxAge))
+	})
+
+	t.Run("Should ask browsers not to cache the preflight when max age is zero", func(t *testing.T) {
+		rec := preflight(newServer(0), "https://shop.example.com")
+		assert.Equal(t, "0", rec.Header().Get(echo.HeaderAccessControlMaxAge))
+	})
+
+	t.Run("Should not allow other origins", func(t *testing.T) {
+		rec := preflight(newServer(600), "https://evil.example.com")
+		assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+		assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlMaxAge))
+	})
+
+	t.Run("Should mark simple requests from allowed origins", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/products", nil)
+		req.Header.Set(echo.HeaderOrigin, "https://shop.example.com")
+		rec := httptest.NewRecorder()
+		newServer(600).ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "https://shop.example.com", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+		assert.Contains(t, rec.Header().Get(echo.HeaderAccessControlExposeHeaders), "X-Total-Count")
+	})
+}
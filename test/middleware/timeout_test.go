@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"product-app/middleware"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Timeout(t *testing.T) {
+	e := echo.New()
+	e.Use(middleware.Timeout(20*time.Millisecond, "/export"))
+	slow := func(c echo.Context) error {
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-c.Request().Context().Done():
+		}
+		return c.JSON(http.StatusOK, map[string]string{"status": "done"})
+	}
+	e.GET("/slow", slow)
+	e.GET("/export", slow)
+	e.GET("/fast", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"status": "done"})
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("Should answer 503 with the error body when the handler is too slow", func(t *testing.T) {
+		rec := get("/slow")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+		assert.JSONEq(t, `{"error": "Request timed out"}`, rec.Body.String())
+	})
+
+	t.Run("Should pass fast handlers through", func(t *testing.T) {
+		rec := get("/fast")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status": "done"}`, rec.Body.String())
+	})
+
+	t.Run("Should not limit exempt routes", func(t *testing.T) {
+		rec := get("/export")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Should be disabled by a zero timeout", func(t *testing.T) {
+		disabled := echo.New()
+		disabled.Use(middleware.Timeout(0))
+		disabled.GET("/slow", slow)
+		rec := httptest.NewRecorder()
+		disabled.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
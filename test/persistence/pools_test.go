@@ -0,0 +1,25 @@
+package persistence
+
+import (
+	"product-app/persistence"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewPools(t *testing.T) {
+	primary, replica := new(pgxpool.Pool), new(pgxpool.Pool)
+
+	t.Run("Should send reads to the replica", func(t *testing.T) {
+		pools := persistence.NewPools(primary, replica)
+		assert.Same(t, primary, pools.Primary)
+		assert.Same(t, replica, pools.Read)
+	})
+
+	t.Run("Should fall back to the primary without a replica", func(t *testing.T) {
+		pools := persistence.NewPools(primary, nil)
+		assert.Same(t, primary, pools.Read)
+		assert.Equal(t, pools, persistence.SinglePool(primary))
+	})
+}
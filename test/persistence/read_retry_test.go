@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"product-app/persistence"
+	"syscall"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubQuerier fails the first failures calls with err and succeeds afterwards.
+type stubQuerier struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (stub *stubQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	stub.calls++
+	if stub.calls <= stub.failures {
+		return nil, stub.err
+	}
+	return nil, nil
+}
+
+func (stub *stubQuerier) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	stub.calls++
+	if stub.calls <= stub.failures {
+		return stubRow{err: stub.err}
+	}
+	return stubRow{}
+}
+
+type stubRow struct {
+	err error
+}
+
+func (row stubRow) Scan(dest ...interface{}) error {
+	if row.err != nil {
+		return row.err
+	}
+	*dest[0].(*int) = 42
+	return nil
+}
+
+var testPolicy = persistence.RetryPolicy{Retries: 2, Backoff: 0}
+
+func Test_RetryingReader(t *testing.T) {
+	t.Run("Should retry a row read that fails once with a refused connection", func(t *testing.T) {
+		stub := &stubQuerier{failures: 1, err: syscall.ECONNREFUSED}
+		var value int
+		err := persistence.NewRetryingReader(stub, testPolicy).QueryRow(context.Background(), "SELECT 42").Scan(&value)
+		assert.NoError(t, err)
+		assert.Equal(t, 42, value)
+		assert.Equal(t, 2, stub.calls)
+	})
+
+	t.Run("Should retry a query that fails once with an admin shutdown", func(t *testing.T) {
+		stub := &stubQuerier{failures: 1, err: &pgconn.PgError{Code: "57P01"}}
+		_, err := persistence.NewRetryingReader(stub, testPolicy).Query(context.Background(), "SELECT 1")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, stub.calls)
+	})
+
+	t.Run("Should give up after the configured retries", func(t *testing.T) {
+		stub := &stubQuerier{failures: 5, err: syscall.ECONNREFUSED}
+		_, err := persistence.NewRetryingReader(stub, testPolicy).Query(context.Background(), "SELECT 1")
+		assert.ErrorIs(t, err, syscall.ECONNREFUSED)
+		assert.Equal(t, 3, stub.calls)
+	})
+
+	t.Run("Should not retry errors that are not transient", func(t *testing.T) {
+		stub := &stubQuerier{failures: 1, err: errors.New("syntax error")}
+		var value int
+		err := persistence.NewRetryingReader(stub, testPolicy).QueryRow(context.Background(), "SELEC 42").Scan(&value)
+		assert.EqualError(t, err, "syntax error")
+		assert.Equal(t, 1, stub.calls)
+	})
+}
@@ -11,24 +11,5 @@ docker exec -it postgres-test-db psql -U postgres -d postgres -c "CREATE DATABAS
 sleep 3
 echo "✅ Test database 'productapp_unit_test' created"
 
-# Test tablolarını oluştur
-docker exec -it postgres-test-db psql -U postgres -d productapp_unit_test -c "
-CREATE TABLE IF NOT EXISTS products (
-  id BIGSERIAL PRIMARY KEY,
-  name VARCHAR(255) NOT NULL,
-  price DOUBLE PRECISION NOT NULL,
-  description VARCHAR(350) NOT NULL,
-  discount DOUBLE PRECISION,
-  store VARCHAR(255) NOT NULL
-);
-
-CREATE TABLE IF NOT EXISTS product_images (
-  id BIGSERIAL PRIMARY KEY,
-  product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
-  image_urls TEXT NOT NULL,
-  is_main_image BOOLEAN DEFAULT FALSE,
-  display_order INT DEFAULT 0
-);"
-
-sleep 2
-echo "✅ Test tables created successfully in 'productapp_unit_test'"
+# Tablolar test/infrastructure TestMain içinde migration'lar ile oluşturulur
+echo "ℹ️  Tables are created by the embedded migrations when the integration tests start"
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"product-app/domain"
+	"product-app/service"
+	"product-app/service/model"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AddCategory_ShouldRecordAuditFields(t *testing.T) {
+	fakeRepo := NewFakeCategoryRepository([]domain.Category{})
+	categoryService := service.NewCategoryService(fakeRepo, service.DefaultCategorySettings)
+	creatorId := int64(7)
+
+	created, err := categoryService.AddCategory(domain.Category{
+		Name:        "Home Garden",
+		Description: "Home improvement and gardening supplies",
+		CreatedBy:   &creatorId,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), created.Id)
+
+	category, err := categoryService.GetById(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "home-garden", category.Slug)
+	assert.Equal(t, &creatorId, category.CreatedBy)
+	assert.False(t, category.CreatedAt.IsZero())
+	assert.Equal(t, category.CreatedAt, category.UpdatedAt)
+
+	t.Run("Update should refresh updated_at and keep the creator", func(t *testing.T) {
+		err := categoryService.UpdateCategory(domain.Category{
+			Id:          1,
+			Name:        "Garden",
+			Description: "Gardening supplies",
+		})
+		assert.NoError(t, err)
+
+		updated, _ := categoryService.GetById(1)
+		assert.Equal(t, &creatorId, updated.CreatedBy)
+		assert.Equal(t, category.CreatedAt, updated.CreatedAt)
+		assert.False(t, updated.UpdatedAt.Before(category.UpdatedAt))
+	})
+}
+
+func Test_CategoryDescription(t *testing.T) {
+	t.Run("Should require a description by default", func(t *testing.T) {
+		categoryService := service.NewCategoryService(NewFakeCategoryRepository([]domain.Category{}), service.DefaultCategorySettings)
+		_, err := categoryService.AddCategory(domain.Category{Name: "Garden"})
+		assert.EqualError(t, err, "category description is required")
+		assert.Empty(t, categoryService.GetAllCategories())
+	})
+
+	t.Run("Should accept a category without a description when optional", func(t *testing.T) {
+		categoryService := service.NewCategoryService(NewFakeCategoryRepository([]domain.Category{}), service.CategorySettings{
+			RequireDescription: false, MaxDescriptionLength: 20,
+		})
+		created, err := categoryService.AddCategory(domain.Category{Name: "Garden"})
+		assert.NoError(t, err)
+		assert.Equal(t, "", created.Description)
+
+		assert.NoError(t, categoryService.UpdateCategory(domain.Category{Id: created.Id, Name: "Garden", Description: "Outdoor"}))
+		assert.NoError(t, categoryService.UpdateCategory(domain.Category{Id: created.Id, Name: "Garden"}))
+	})
+
+	t.Run("Should enforce the maximum length in both modes", func(t *testing.T) {
+		for _, required := range []bool{true, false} {
+			categoryService := service.NewCategoryService(NewFakeCategoryRepository([]domain.Category{}), service.CategorySettings{
+				RequireDescription: required, MaxDescriptionLength: 20,
+			})
+			_, err := categoryService.AddCategory(domain.Category{Name: "Garden", Description: strings.Repeat("ç", 21)})
+			assert.ErrorIs(t, err, model.ErrDescriptionTooLong)
+
+			_, err = categoryService.AddCategory(domain.Category{Name: "Garden", Description: strings.Repeat("ç", 20)})
+			assert.NoError(t, err)
+		}
+	})
+}
+
+func Test_CategoryName_CaseInsensitiveUniqueness(t *testing.T) {
+	fakeRepo := NewFakeCategoryRepository([]domain.Category{
+		{Id: 1, Name: "Phones", Slug: "phones", Description: "Mobile phones"},
+		{Id: 2, Name: "Tablets", Slug: "tablets", Description: "Tablets"},
+	})
+	categoryService := service.NewCategoryService(fakeRepo, service.DefaultCategorySettings)
+
+	t.Run("Should reject a name that only differs in case or spaces", func(t *testing.T) {
+		for _, name := range []string{"phones", "PHONES", "  Phones "} {
+			_, err := categoryService.AddCategory(domain.Category{Name: name, Description: "Duplicate"})
+			assert.ErrorIs(t, err, domain.ErrCategoryNameTaken, name)
+		}
+		assert.Len(t, categoryService.GetAllCategories(), 2)
+	})
+
+	t.Run("Should reject renaming onto another category's name", func(t *testing.T) {
+		err := categoryService.UpdateCategory(domain.Category{Id: 2, Name: "pHONES", Description: "Tablets"})
+		assert.ErrorIs(t, err, domain.ErrCategoryNameTaken)
+	})
+
+	t.Run("Should allow a category to change the casing of its own name", func(t *testing.T) {
+		err := categoryService.UpdateCategory(domain.Category{Id: 1, Name: "PHONES", Description: "Mobile phones"})
+		assert.NoError(t, err)
+		updated, _ := categoryService.GetById(1)
+		assert.Equal(t, "PHONES", updated.Name)
+	})
+
+	t.Run("Should keep the original casing and drop surrounding spaces", func(t *testing.T) {
+		created, err := categoryService.AddCategory(domain.Category{Name: " iPhone Cases ", Description: "Cases"})
+		assert.NoError(t, err)
+		assert.Equal(t, "iPhone Cases", created.Name)
+	})
+}
+
+func Test_DeleteCategory_ReassignsProducts(t *testing.T) {
+	fakeRepo := NewFakeCategoryRepository([]domain.Category{
+		{Id: 1, Name: "Electronics", Slug: "electronics", Description: "Electronic devices"},
+		{Id: 2, Name: "Home", Slug: "home", Description: "Home appliances"},
+	})
+	fakeRepo.productCategoryIds = map[int64]int64{10: 1, 11: 1, 12: 2}
+	categoryService := service.NewCategoryService(fakeRepo, service.DefaultCategorySettings)
+
+	t.Run("Should refuse to delete a category that still has products", func(t *testing.T) {
+		err := categoryService.DeleteById(1)
+		assert.ErrorIs(t, err, domain.ErrCategoryNotEmpty)
+	})
+
+	t.Run("Should move the products to Uncategorized when forced", func(t *testing.T) {
+		reassigned, err := categoryService.ForceDeleteById(1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), reassigned)
+
+		uncategorized, err := categoryService.GetBySlug(domain.UncategorizedSlug)
+		assert.NoError(t, err)
+		assert.Equal(t, uncategorized.Id, fakeRepo.productCategoryIds[10])
+		assert.Equal(t, uncategorized.Id, fakeRepo.productCategoryIds[11])
+		assert.Equal(t, int64(2), fakeRepo.productCategoryIds[12])
+
+		_, err = categoryService.GetById(1)
+		assert.ErrorIs(t, err, domain.ErrCategoryNotFound)
+	})
+
+	t.Run("Should never delete Uncategorized", func(t *testing.T) {
+		uncategorized, err := categoryService.EnsureUncategorized()
+		assert.NoError(t, err)
+
+		_, err = categoryService.ForceDeleteById(uncategorized.Id)
+		assert.ErrorIs(t, err, domain.ErrUncategorizedProtected)
+		assert.ErrorIs(t, categoryService.DeleteById(uncategorized.Id), domain.ErrUncategorizedProtected)
+	})
+
+	t.Run("Should report an unknown category as not found", func(t *testing.T) {
+		_, err := categoryService.ForceDeleteById(99)
+		assert.ErrorIs(t, err, domain.ErrCategoryNotFound)
+	})
+}
+
+func Test_ForceDeleteCategory_RollsBackOnFailedDelete(t *testing.T) {
+	fakeRepo := NewFakeCategoryRepository([]domain.Category{
+		{Id: 1, Name: "Electronics", Slug: "electronics", Description: "Electronic devices"},
+	})
+	fakeRepo.productCategoryIds = map[int64]int64{10: 1, 11: 1}
+	categoryService := service.NewCategoryService(fakeRepo, service.DefaultCategorySettings)
+	deleteErr := errors.New("connection reset")
+	fakeRepo.FailDeletes(deleteErr)
+
+	t.Run("Should leave the products in place when the delete fails", func(t *testing.T) {
+		reassigned, err := categoryService.ForceDeleteById(1)
+		assert.ErrorIs(t, err, deleteErr)
+		assert.Equal(t, int64(0), reassigned)
+		assert.Equal(t, map[int64]int64{10: 1, 11: 1}, fakeRepo.productCategoryIds)
+
+		_, err = categoryService.GetById(1)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_SoftDeleteCategory(t *testing.T) {
+	fakeRepo := NewFakeCategoryRepository([]domain.Category{
+		{Id: 1, Name: "Electronics", Slug: "electronics", Description: "Electronic devices"},
+		{Id: 2, Name: "Home", Slug: "home", Description: "Home appliances"},
+	})
+	categoryService := service.NewCategoryService(fakeRepo, service.DefaultCategorySettings)
+
+	t.Run("Should hide a deleted category from reads", func(t *testing.T) {
+		assert.NoError(t, categoryService.DeleteById(1))
+
+		_, err := categoryService.GetById(1)
+		assert.ErrorIs(t, err, domain.ErrCategoryNotFound)
+		assert.Len(t, categoryService.GetAllCategories(), 1)
+		matches, err := categoryService.SearchCategories("electronic", 10)
+		assert.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+
+	t.Run("Should keep the name of a deleted category reserved", func(t *testing.T) {
+		_, err := categoryService.AddCategory(domain.Category{Name: "Electronics", Description: "Gadgets"})
+		assert.ErrorIs(t, err, domain.ErrCategoryNameTaken)
+	})
+
+	t.Run("Should restore a deleted category", func(t *testing.T) {
+		restored, err := categoryService.RestoreCategory(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "electronics", restored.Slug)
+		assert.Len(t, categoryService.GetAllCategories(), 2)
+	})
+
+	t.Run("Should report a category that is not deleted as not found", func(t *testing.T) {
+		_, err := categoryService.RestoreCategory(2)
+		assert.ErrorIs(t, err, domain.ErrCategoryNotFound)
+		_, err = categoryService.RestoreCategory(99)
+		assert.ErrorIs(t, err, domain.ErrCategoryNotFound)
+	})
+}
+
+func Test_EnsureUncategorized(t *testing.T) {
+	categoryService := service.NewCategoryService(NewFakeCategoryRepository([]domain.Category{}), service.DefaultCategorySettings)
+
+	first, err := categoryService.EnsureUncategorized()
+	assert.NoError(t, err)
+	assert.Equal(t, domain.UncategorizedName, first.Name)
+
+	second, err := categoryService.EnsureUncategorized()
+	assert.NoError(t, err)
+	assert.Equal(t, first.Id, second.Id)
+	assert.Len(t, categoryService.GetAllCategories(), 1)
+}
+
+func int64Pointer(value int64) *int64 {
+	return &value
+}
+
+func Test_GetCategoryTree(t *testing.T) {
+	t.Run("Should nest subcategories under their parents", func(t *testing.T) {
+		categoryService := service.NewCategoryService(NewFakeCategoryRepository([]domain.Category{
+			{Id: 1, Name: "Electronics", Slug: "electronics"},
+			{Id: 2, Name: "Phones", Slug: "phones", ParentId: int64Pointer(1)},
+			{Id: 3, Name: "Smartphones", Slug: "smartphones", ParentId: int64Pointer(2)},
+			{Id: 4, Name: "Home", Slug: "home"},
+			{Id: 5, Name: "Orphan", Slug: "orphan", ParentId: int64Pointer(99)},
+		}), service.DefaultCategorySettings)
+
+		tree, err := categoryService.GetCategoryTree()
+		assert.NoError(t, err)
+		assert.Len(t, tree, 3)
+		assert.Equal(t, "electronics", tree[0].Slug)
+		assert.Len(t, tree[0].Children, 1)
+		assert.Equal(t, "phones", tree[0].Children[0].Slug)
+		assert.Equal(t, "smartphones", tree[0].Children[0].Children[0].Slug)
+		assert.Empty(t, tree[1].Children)
+		assert.NotNil(t, tree[1].Children)
+		assert.Equal(t, "orphan", tree[2].Slug)
+	})
+
+	t.Run("Should report a parent cycle instead of recursing", func(t *testing.T) {
+		categoryService := service.NewCategoryService(NewFakeCategoryRepository([]domain.Category{
+			{Id: 1, Name: "Electronics", Slug: "electronics"},
+			{Id: 2, Name: "Phones", Slug: "phones", ParentId: int64Pointer(3)},
+			{Id: 3, Name: "Smartphones", Slug: "smartphones", ParentId: int64Pointer(2)},
+		}), service.DefaultCategorySettings)
+
+		_, err := categoryService.GetCategoryTree()
+		assert.ErrorIs(t, err, domain.ErrCategoryCycle)
+	})
+}
+
+func Test_CategoryParent(t *testing.T) {
+	categoryService := service.NewCategoryService(NewFakeCategoryRepository([]domain.Category{
+		{Id: 1, Name: "Electronics", Slug: "electronics", Description: "Electronic devices"},
+		{Id: 2, Name: "Phones", Slug: "phones", Description: "Phones", ParentId: int64Pointer(1)},
+	}), service.DefaultCategorySettings)
+
+	t.Run("Should create a category under an existing parent", func(t *testing.T) {
+		created, err := categoryService.AddCategory(domain.Category{Name: "Smartphones", Description: "Smartphones", ParentId: int64Pointer(2)})
+		assert.NoError(t, err)
+		assert.Equal(t, int64Pointer(2), created.ParentId)
+	})
+
+	t.Run("Should reject an unknown parent", func(t *testing.T) {
+		_, err := categoryService.AddCategory(domain.Category{Name: "Tablets", Description: "Tablets", ParentId: int64Pointer(99)})
+		assert.ErrorIs(t, err, domain.ErrCategoryNotFound)
+	})
+
+	t.Run("Should reject moving a category below itself or its descendants", func(t *testing.T) {
+		err := categoryService.UpdateCategory(domain.Category{Id: 1, Name: "Electronics", Description: "Electronic devices", ParentId: int64Pointer(1)})
+		assert.ErrorIs(t, err, domain.ErrCategoryCycle)
+
+		err = categoryService.UpdateCategory(domain.Category{Id: 1, Name: "Electronics", Description: "Electronic devices", ParentId: int64Pointer(2)})
+		assert.ErrorIs(t, err, domain.ErrCategoryCycle)
+	})
+}
+
+func Test_UniqueCategorySlug(t *testing.T) {
+	testCases := []struct {
+		description   string
+		existingSlugs []string
+		expected      string
+	}{
+		{description: "free slug", existingSlugs: []string{"garden"}, expected: "home-garden"},
+		{description: "one collision", existingSlugs: []string{"home-garden"}, expected: "home-garden-2"},
+		{description: "several collisions", existingSlugs: []string{"home-garden", "home-garden-2", "home-garden-3"}, expected: "home-garden-4"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			categories := make([]domain.Category, len(testCase.existingSlugs))
+			for i, slug := range testCase.existingSlugs {
+				categories[i] = domain.Category{Id: int64(i) + 1, Name: fmt.Sprintf("Existing %d", i+1), Slug: slug}
+			}
+			categoryService := service.NewCategoryService(NewFakeCategoryRepository(categories), service.DefaultCategorySettings)
+
+			created, err := categoryService.AddCategory(domain.Category{Name: "Home Garden", Description: "Home and garden"})
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, created.Slug)
+		})
+	}
+
+	t.Run("Should keep the slug a category already owns", func(t *testing.T) {
+		categoryService := service.NewCategoryService(NewFakeCategoryRepository([]domain.Category{
+			{Id: 1, Name: "Home Garden", Slug: "home-garden", Description: "Home and garden"},
+		}), service.DefaultCategorySettings)
+
+		assert.NoError(t, categoryService.UpdateCategory(domain.Category{Id: 1, Name: "Home Garden", Description: "Gardening supplies"}))
+		updated, _ := categoryService.GetById(1)
+		assert.Equal(t, "home-garden", updated.Slug)
+	})
+}
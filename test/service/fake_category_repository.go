@@ -0,0 +1,173 @@
+package service
+
+import (
+	"fmt"
+	"product-app/domain"
+	"slices"
+	"strings"
+)
+
+type FakeCategoryRepository struct {
+	categories []domain.Category
+	// deletedCategories holds the soft-deleted categories until they are restored
+	deletedCategories []domain.Category
+	// productCategoryIds maps product ids to their category, standing in for the products table
+	productCategoryIds map[int64]int64
+	// deleteErr, when set, makes the delete step of ReassignProductsAndDelete fail
+	deleteErr error
+}
+
+// FailDeletes makes every later ReassignProductsAndDelete fail at the delete step.
+func (fakeRepository *FakeCategoryRepository) FailDeletes(err error) {
+	fakeRepository.deleteErr = err
+}
+
+func NewFakeCategoryRepository(initialCategories []domain.Category) *FakeCategoryRepository {
+	return &FakeCategoryRepository{
+		categories:         initialCategories,
+		productCategoryIds: map[int64]int64{},
+	}
+}
+
+// AssignProduct records that a product belongs to the category.
+func (fakeRepository *FakeCategoryRepository) AssignProduct(productId int64, categoryId int64) {
+	fakeRepository.productCategoryIds[productId] = categoryId
+}
+
+func (fakeRepository *FakeCategoryRepository) GetAllCategories() []domain.Category {
+	return fakeRepository.categories
+}
+
+func (fakeRepository *FakeCategoryRepository) GetById(categoryId int64) (domain.Category, error) {
+	for _, category := range fakeRepository.categories {
+		if category.Id == categoryId {
+			return category, nil
+		}
+	}
+	return domain.Category{}, fmt.Errorf("%w with id %d", domain.ErrCategoryNotFound, categoryId)
+}
+
+// GetCurrentById and GetCurrentBySlug match GetById and GetBySlug, the fake has no replica.
+func (fakeRepository *FakeCategoryRepository) GetCurrentById(categoryId int64) (domain.Category, error) {
+	return fakeRepository.GetById(categoryId)
+}
+
+func (fakeRepository *FakeCategoryRepository) GetCurrentBySlug(slug string) (domain.Category, error) {
+	return fakeRepository.GetBySlug(slug)
+}
+
+func (fakeRepository *FakeCategoryRepository) GetBySlug(slug string) (domain.Category, error) {
+	for _, category := range slices.Concat(fakeRepository.categories, fakeRepository.deletedCategories) {
+		if category.Slug == slug {
+			return category, nil
+		}
+	}
+	return domain.Category{}, fmt.Errorf("%w with slug %s", domain.ErrCategoryNotFound, slug)
+}
+
+func (fakeRepository *FakeCategoryRepository) SearchCategories(query string, limit int) ([]domain.Category, error) {
+	matches := []domain.Category{}
+	for _, category := range fakeRepository.categories {
+		if len(matches) == limit {
+			break
+		}
+		if strings.Contains(strings.ToLower(category.Name), strings.ToLower(query)) ||
+			strings.Contains(strings.ToLower(category.Description), strings.ToLower(query)) {
+			matches = append(matches, category)
+		}
+	}
+	return matches, nil
+}
+
+func (fakeRepository *FakeCategoryRepository) CategoryExistsByName(name string, excludeCategoryId int64) (bool, error) {
+	normalized := strings.ToLower(strings.TrimSpace(name))
+	for _, category := range slices.Concat(fakeRepository.categories, fakeRepository.deletedCategories) {
+		if category.Id != excludeCategoryId && strings.ToLower(strings.TrimSpace(category.Name)) == normalized {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (fakeRepository *FakeCategoryRepository) AddCategory(category domain.Category) (int64, error) {
+	category.Id = int64(len(fakeRepository.categories)+len(fakeRepository.deletedCategories)) + 1
+	fakeRepository.categories = append(fakeRepository.categories, category)
+	return category.Id, nil
+}
+
+func (fakeRepository *FakeCategoryRepository) UpdateCategory(category domain.Category) error {
+	for i, existing := range fakeRepository.categories {
+		if existing.Id == category.Id {
+			category.CreatedBy = existing.CreatedBy
+			category.CreatedAt = existing.CreatedAt
+			fakeRepository.categories[i] = category
+			return nil
+		}
+	}
+	return fmt.Errorf("%w with id %d", domain.ErrCategoryNotFound, category.Id)
+}
+
+func (fakeRepository *FakeCategoryRepository) DeleteById(categoryId int64) error {
+	productCount, _ := fakeRepository.CountProducts(categoryId)
+	if productCount > 0 {
+		return fmt.Errorf("%w: category with id %d has %d products", domain.ErrCategoryNotEmpty, categoryId, productCount)
+	}
+	return fakeRepository.remove(categoryId)
+}
+
+// remove soft-deletes the category without checking for products.
+func (fakeRepository *FakeCategoryRepository) remove(categoryId int64) error {
+	for i, existing := range fakeRepository.categories {
+		if existing.Id == categoryId {
+			fakeRepository.categories = append(fakeRepository.categories[:i], fakeRepository.categories[i+1:]...)
+			fakeRepository.deletedCategories = append(fakeRepository.deletedCategories, existing)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w with id %d", domain.ErrCategoryNotFound, categoryId)
+}
+
+func (fakeRepository *FakeCategoryRepository) RestoreCategory(categoryId int64) error {
+	for i, deleted := range fakeRepository.deletedCategories {
+		if deleted.Id == categoryId {
+			fakeRepository.deletedCategories = append(fakeRepository.deletedCategories[:i], fakeRepository.deletedCategories[i+1:]...)
+			fakeRepository.categories = append(fakeRepository.categories, deleted)
+			return nil
+		}
+	}
+	return fmt.Errorf("deleted %w with id %d", domain.ErrCategoryNotFound, categoryId)
+}
+
+func (fakeRepository *FakeCategoryRepository) CountProducts(categoryId int64) (int64, error) {
+	var count int64
+	for _, productCategoryId := range fakeRepository.productCategoryIds {
+		if productCategoryId == categoryId {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ReassignProductsAndDelete stages the reassignment on a copy and only applies it once the
+// delete succeeded, mirroring the rollback of the real transaction.
+func (fakeRepository *FakeCategoryRepository) ReassignProductsAndDelete(fromCategoryId int64, toCategoryId int64) (int64, error) {
+	staged := make(map[int64]int64, len(fakeRepository.productCategoryIds))
+	var reassigned int64
+	for productId, productCategoryId := range fakeRepository.productCategoryIds {
+		if productCategoryId == fromCategoryId {
+			productCategoryId = toCategoryId
+			reassigned++
+		}
+		staged[productId] = productCategoryId
+	}
+
+	if fakeRepository.deleteErr != nil {
+		return 0, fakeRepository.deleteErr
+	}
+	if err := fakeRepository.remove(fromCategoryId); err != nil {
+		return 0, err
+	}
+	fakeRepository.deletedCategories = fakeRepository.deletedCategories[:len(fakeRepository.deletedCategories)-1]
+	fakeRepository.productCategoryIds = staged
+	return reassigned, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"product-app/domain"
+	"product-app/persistence"
+	"time"
+)
+
+type FakeIdempotencyRepository struct {
+	keys map[string]domain.IdempotencyKey
+	// completeErr, when set, is returned by CompleteKey
+	completeErr error
+}
+
+func NewFakeIdempotencyRepository() *FakeIdempotencyRepository {
+	return &FakeIdempotencyRepository{
+		keys: map[string]domain.IdempotencyKey{},
+	}
+}
+
+func (fakeRepository *FakeIdempotencyRepository) GetByKey(key string) (domain.IdempotencyKey, error) {
+	idempotencyKey, ok := fakeRepository.keys[key]
+	if !ok {
+		return domain.IdempotencyKey{}, fmt.Errorf("%w: %s", persistence.ErrIdempotencyKeyNotFound, key)
+	}
+	return idempotencyKey, nil
+}
+
+func (fakeRepository *FakeIdempotencyRepository) ReserveKey(key string, requestHash string, expiredBefore time.Time) (bool, error) {
+	if existing, ok := fakeRepository.keys[key]; ok && !existing.CreatedAt.Before(expiredBefore) {
+		return false, nil
+	}
+	fakeRepository.keys[key] = domain.IdempotencyKey{Key: key, RequestHash: requestHash, CreatedAt: time.Now()}
+	return true, nil
+}
+
+func (fakeRepository *FakeIdempotencyRepository) CompleteKey(key string, productId int64) error {
+	if fakeRepository.completeErr != nil {
+		return fakeRepository.completeErr
+	}
+	idempotencyKey := fakeRepository.keys[key]
+	idempotencyKey.ProductId = productId
+	fakeRepository.keys[key] = idempotencyKey
+	return nil
+}
+
+func (fakeRepository *FakeIdempotencyRepository) ReleaseKey(key string) error {
+	if fakeRepository.keys[key].ProductId == 0 {
+		delete(fakeRepository.keys, key)
+	}
+	return nil
+}
+
+// errFakeCompleteKey stands in for a database failure while saving a key.
+var errFakeCompleteKey = errors.New("connection lost")
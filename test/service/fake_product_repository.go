@@ -1,58 +1,223 @@
 package service
 
 import (
-	"errors"
 	"fmt"
+	"product-app/common/decimal"
 	"product-app/domain"
 	"product-app/persistence"
+	"product-app/service/model"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 )
 
+// fakeCategoryIds are the categories the fake repository treats as existing.
+var fakeCategoryIds = map[int64]bool{1: true, 2: true, 3: true}
+
 type FakeProductRepository struct {
-	products []domain.Product
+	products  []domain.Product
+	createdAt map[int64]time.Time
+	updatedAt map[int64]time.Time
+	deletedAt map[int64]time.Time
+	ownerIds  map[int64]int64
+	ratings   map[int64]domain.ProductRating
+	history   []domain.PriceChange
+	imageId   int64
 }
 
 // DeleteAllProducts implements persistence.IProductRepository.
 func (fakeRepository *FakeProductRepository) DeleteAllProducts() error {
+	for _, product := range fakeRepository.products {
+		fakeRepository.deletedAt[product.Id] = time.Now()
+	}
 	fakeRepository.products = []domain.Product{}
 	return nil
 }
 
 // GetAllProductsByUser implements persistence.IProductRepository.
 func (fakeRepository *FakeProductRepository) GetAllProductsByUser(userId int64) []domain.Product {
-	return fakeRepository.products
+	products, _, _ := fakeRepository.Find(model.ProductFilter{UserId: userId, Status: model.ProductStatusAll})
+	return products
 }
 
+// NewFakeProductRepository treats products without a status as active and without a
+// currency as TRY, like the column defaults.
 func NewFakeProductRepository(initialProducts []domain.Product) persistence.IProductRepository {
+	for i := range initialProducts {
+		if initialProducts[i].Status == "" {
+			initialProducts[i].Status = domain.ProductStatusActive
+		}
+		if initialProducts[i].Currency == "" {
+			initialProducts[i].Currency = "TRY"
+		}
+	}
 	return &FakeProductRepository{
-		products: initialProducts,
+		products:  initialProducts,
+		createdAt: map[int64]time.Time{},
+		updatedAt: map[int64]time.Time{},
+		deletedAt: map[int64]time.Time{},
+		ownerIds:  map[int64]int64{},
+		ratings:   map[int64]domain.ProductRating{},
 	}
 }
+
+// SetOwner records the user that created a product, mirroring products.user_id.
+func (fakeRepository *FakeProductRepository) SetOwner(productId int64, userId int64) {
+	fakeRepository.ownerIds[productId] = userId
+}
+
+// SetRating sets the review aggregate GetRating reports; products without one have no reviews.
+func (fakeRepository *FakeProductRepository) SetRating(productId int64, rating domain.ProductRating) {
+	fakeRepository.ratings[productId] = rating
+}
+
+func (fakeRepository *FakeProductRepository) GetRating(productId int64) (domain.ProductRating, error) {
+	return fakeRepository.ratings[productId], nil
+}
+
+// SetCreatedAt records when a product was created; products without one count as created at the zero time.
+func (fakeRepository *FakeProductRepository) SetCreatedAt(productId int64, createdAt time.Time) {
+	fakeRepository.createdAt[productId] = createdAt
+}
+
+// SetUpdatedAt records when a product last changed; products without one fall back to
+// their creation time.
+func (fakeRepository *FakeProductRepository) SetUpdatedAt(productId int64, updatedAt time.Time) {
+	fakeRepository.updatedAt[productId] = updatedAt
+}
+
 func (fakeRepository *FakeProductRepository) GettAllProducts() []domain.Product {
-	return fakeRepository.products
+	products, _, _ := fakeRepository.Find(model.ProductFilter{})
+	return products
 }
 
 func (fakeRepository *FakeProductRepository) GetAllProductsByStore(storeName string) []domain.Product {
-	var productsByStore []domain.Product
-	for _, product := range fakeRepository.products {
-		if product.Store == storeName {
-			productsByStore = append(productsByStore, product)
-		}
-	}
-	return productsByStore
+	products, _, _ := fakeRepository.Find(model.ProductFilter{Store: storeName})
+	return products
 }
 
-func (fakeRepository *FakeProductRepository) AddProduct(product domain.Product) error {
+func (fakeRepository *FakeProductRepository) AddProduct(product domain.Product) (int64, error) {
+	productId := int64(len(fakeRepository.products)) + 1
 	fakeRepository.products = append(fakeRepository.products, domain.Product{
-		Id:          int64(len(fakeRepository.products)) + 1,
+		Id:          productId,
 		Name:        product.Name,
+		Slug:        product.Slug,
 		Price:       product.Price,
+		Currency:    product.Currency,
 		Description: product.Description,
 		Discount:    product.Discount,
 		Store:       product.Store,
 		ImageUrls:   product.ImageUrls,
 		CategoryID:  product.CategoryID,
+		Tags:        product.Tags,
+		Status:      product.Status,
+		Stock:       product.Stock,
 	})
-	return nil
+	if product.UserId != 0 {
+		fakeRepository.ownerIds[productId] = product.UserId
+	}
+	return productId, nil
+}
+
+func (fakeRepository *FakeProductRepository) AddProductImages(productId int64, images []domain.ProductImage) error {
+	for i := range fakeRepository.products {
+		if fakeRepository.products[i].Id != productId {
+			continue
+		}
+		if len(fakeRepository.products[i].ImageUrls)+len(images) > persistence.DefaultMaxImagesPerProduct {
+			return fmt.Errorf("%w: a product can have at most %d images", persistence.ErrTooManyImages, persistence.DefaultMaxImagesPerProduct)
+		}
+		nextDisplayOrder, hasMainImage := 0, false
+		for _, existing := range fakeRepository.products[i].Images {
+			if existing.DisplayOrder >= nextDisplayOrder {
+				nextDisplayOrder = existing.DisplayOrder + 1
+			}
+			hasMainImage = hasMainImage || existing.IsMain
+		}
+		for j, image := range images {
+			fakeRepository.imageId++
+			image.Id = fakeRepository.imageId
+			image.DisplayOrder = nextDisplayOrder + j
+			image.IsMain = !hasMainImage && j == 0
+			fakeRepository.products[i].ImageUrls = append(fakeRepository.products[i].ImageUrls, image.Url)
+			fakeRepository.products[i].Images = append(fakeRepository.products[i].Images, image)
+		}
+		return nil
+	}
+	return domain.ErrProductNotFound
+}
+
+func (fakeRepository *FakeProductRepository) GetProductImages(productId int64) ([]domain.ProductImage, error) {
+	for _, product := range fakeRepository.products {
+		if product.Id == productId {
+			return append([]domain.ProductImage{}, product.Images...), nil
+		}
+	}
+	return nil, domain.ErrProductNotFound
+}
+
+func (fakeRepository *FakeProductRepository) NormalizeImageOrder(productId int64) error {
+	for i := range fakeRepository.products {
+		if fakeRepository.products[i].Id == productId {
+			renumberImages(&fakeRepository.products[i])
+			return nil
+		}
+	}
+	return domain.ErrProductNotFound
+}
+
+func (fakeRepository *FakeProductRepository) NormalizeAllImageOrders() (int64, error) {
+	var changed int64
+	for i := range fakeRepository.products {
+		if renumberImages(&fakeRepository.products[i]) {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// renumberImages sorts the product's images by display order, id breaking ties, and
+// numbers them from zero like the repository; it reports whether any order changed.
+// Products seeded with ImageUrls alone have no orders to fix and are left alone.
+func renumberImages(product *domain.Product) bool {
+	if len(product.Images) == 0 {
+		return false
+	}
+	sort.SliceStable(product.Images, func(i, j int) bool {
+		if product.Images[i].DisplayOrder != product.Images[j].DisplayOrder {
+			return product.Images[i].DisplayOrder < product.Images[j].DisplayOrder
+		}
+		return product.Images[i].Id < product.Images[j].Id
+	})
+	changed := false
+	product.ImageUrls = product.ImageUrls[:0]
+	for position := range product.Images {
+		if product.Images[position].DisplayOrder != position {
+			product.Images[position].DisplayOrder = position
+			changed = true
+		}
+		product.ImageUrls = append(product.ImageUrls, product.Images[position].Url)
+	}
+	return changed
+}
+
+// productNotFoundError keeps the fake's historical message while matching
+// domain.ErrProductNotFound like the real repository's errors do.
+type productNotFoundError struct {
+	productId int64
+}
+
+func productNotFound(productId int64) error {
+	return productNotFoundError{productId: productId}
+}
+
+func (notFound productNotFoundError) Error() string {
+	return fmt.Sprintf("Product not found with id %d", notFound.productId)
+}
+
+func (notFound productNotFoundError) Is(target error) bool {
+	return target == domain.ErrProductNotFound
 }
 
 func (fakeRepository *FakeProductRepository) GetById(productId int64) (domain.Product, error) {
@@ -61,7 +226,7 @@ func (fakeRepository *FakeProductRepository) GetById(productId int64) (domain.Pr
 			return product, nil
 		}
 	}
-	return domain.Product{}, errors.New(fmt.Sprintf("Product not found with id %d", productId))
+	return domain.Product{}, productNotFound(productId)
 }
 func (fakeRepository *FakeProductRepository) DeleteById(productId int64) error {
 	foundIndex := -1
@@ -73,25 +238,430 @@ func (fakeRepository *FakeProductRepository) DeleteById(productId int64) error {
 	}
 
 	if foundIndex == -1 {
-		return errors.New(fmt.Sprintf("Product not found with id %d", productId))
+		return productNotFound(productId)
 	}
 
 	fakeRepository.products = append(fakeRepository.products[:foundIndex], fakeRepository.products[foundIndex+1:]...)
+	fakeRepository.deletedAt[productId] = time.Now()
 	return nil
 }
 
-func (fakeRepository *FakeProductRepository) UpdatePrice(productId int64, newPrice float32) error {
+func (fakeRepository *FakeProductRepository) UpdatePrice(productId int64, newPrice decimal.Decimal, version int, changedBy int64) error {
 	found := false
 
 	for i, product := range fakeRepository.products {
 		if product.Id == productId {
+			if product.Version != version {
+				return domain.ErrProductVersionConflict
+			}
+			fakeRepository.history = append(fakeRepository.history, domain.PriceChange{
+				Id:        int64(len(fakeRepository.history)) + 1,
+				ProductId: productId,
+				OldPrice:  product.Price,
+				NewPrice:  newPrice,
+				ChangedAt: time.Now(),
+				ChangedBy: &changedBy,
+			})
 			fakeRepository.products[i].Price = newPrice
+			fakeRepository.products[i].Version++
+			fakeRepository.updatedAt[productId] = time.Now()
 			found = true
 			break
 		}
 	}
 	if !found {
-		return errors.New(fmt.Sprintf("Product not found with id %d", productId))
+		return productNotFound(productId)
 	}
 	return nil
 }
+
+func (fakeRepository *FakeProductRepository) UpdateProductCategory(productId int64, categoryId int64) error {
+	for i := range fakeRepository.products {
+		if fakeRepository.products[i].Id == productId {
+			fakeRepository.products[i].CategoryID = categoryId
+			fakeRepository.products[i].Version++
+			fakeRepository.updatedAt[productId] = time.Now()
+			return nil
+		}
+	}
+	return fmt.Errorf("%w with id %d", domain.ErrProductNotFound, productId)
+}
+
+func (fakeRepository *FakeProductRepository) UpdateProductStatus(productId int64, status string) error {
+	for i := range fakeRepository.products {
+		if fakeRepository.products[i].Id == productId {
+			fakeRepository.products[i].Status = status
+			fakeRepository.products[i].Version++
+			fakeRepository.updatedAt[productId] = time.Now()
+			return nil
+		}
+	}
+	return fmt.Errorf("%w with id %d", domain.ErrProductNotFound, productId)
+}
+
+func (fakeRepository *FakeProductRepository) SetFeatured(productId int64, featured bool) error {
+	for i := range fakeRepository.products {
+		if fakeRepository.products[i].Id == productId {
+			fakeRepository.products[i].IsFeatured = featured
+			fakeRepository.products[i].Version++
+			fakeRepository.updatedAt[productId] = time.Now()
+			return nil
+		}
+	}
+	return fmt.Errorf("%w with id %d", domain.ErrProductNotFound, productId)
+}
+
+func (fakeRepository *FakeProductRepository) GetPriceHistory(productId int64) ([]domain.PriceChange, error) {
+	history := []domain.PriceChange{}
+	for i := len(fakeRepository.history) - 1; i >= 0; i-- {
+		if fakeRepository.history[i].ProductId == productId {
+			history = append(history, fakeRepository.history[i])
+		}
+	}
+	return history, nil
+}
+
+func (fakeRepository *FakeProductRepository) UpdateProductPartial(productId int64, patch model.ProductPatch, changedBy int64) error {
+	for i, product := range fakeRepository.products {
+		if product.Id != productId {
+			continue
+		}
+		if product.Version != patch.Version {
+			return domain.ErrProductVersionConflict
+		}
+		fakeRepository.products[i].Version++
+		fakeRepository.updatedAt[productId] = time.Now()
+		if patch.Name != nil {
+			fakeRepository.products[i].Name = *patch.Name
+		}
+		if patch.Slug != nil {
+			fakeRepository.products[i].Slug = *patch.Slug
+		}
+		if patch.Price != nil {
+			fakeRepository.history = append(fakeRepository.history, domain.PriceChange{
+				Id:        int64(len(fakeRepository.history)) + 1,
+				ProductId: productId,
+				OldPrice:  product.Price,
+				NewPrice:  *patch.Price,
+				ChangedAt: time.Now(),
+				ChangedBy: &changedBy,
+			})
+			fakeRepository.products[i].Price = *patch.Price
+		}
+		if patch.Description != nil {
+			fakeRepository.products[i].Description = *patch.Description
+		}
+		if patch.Discount != nil {
+			fakeRepository.products[i].Discount = *patch.Discount
+		}
+		if patch.Store != nil {
+			fakeRepository.products[i].Store = *patch.Store
+		}
+		if patch.CategoryID != nil {
+			fakeRepository.products[i].CategoryID = *patch.CategoryID
+		}
+		if patch.Stock != nil {
+			fakeRepository.products[i].Stock = patch.Stock
+		}
+		return nil
+	}
+	return fmt.Errorf("%w with id %d", domain.ErrProductNotFound, productId)
+}
+
+func (fakeRepository *FakeProductRepository) GetProductsByCategoryId(categoryId int64, priceRange model.PriceRange, pageRequest model.PageRequest) ([]domain.Product, int64, error) {
+	return fakeRepository.Find(model.ProductFilter{
+		CategoryId:  categoryId,
+		Currency:    priceRange.Currency,
+		MinPrice:    priceRange.Min,
+		MaxPrice:    priceRange.Max,
+		PageRequest: pageRequest,
+	})
+}
+
+func (fakeRepository *FakeProductRepository) GetAllProductsByTag(tag string) []domain.Product {
+	products, _, _ := fakeRepository.Find(model.ProductFilter{Tag: tag})
+	return products
+}
+
+func (fakeRepository *FakeProductRepository) GetProductsCreatedBetween(from time.Time, to time.Time) ([]domain.Product, error) {
+	products, _, err := fakeRepository.Find(model.ProductFilter{
+		CreatedFrom: &from,
+		CreatedTo:   &to,
+		PageRequest: model.PageRequest{Sort: "newest"},
+	})
+	return products, err
+}
+
+func (fakeRepository *FakeProductRepository) AttachTags(productId int64, tags []string) error {
+	for i, product := range fakeRepository.products {
+		if product.Id == productId {
+			fakeRepository.products[i].Tags = append(fakeRepository.products[i].Tags, tags...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w with id %d", domain.ErrProductNotFound, productId)
+}
+
+func (fakeRepository *FakeProductRepository) DetachTag(productId int64, tag string) error {
+	for i, product := range fakeRepository.products {
+		if product.Id != productId {
+			continue
+		}
+		for j, productTag := range product.Tags {
+			if productTag == tag {
+				fakeRepository.products[i].Tags = append(product.Tags[:j], product.Tags[j+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("tag %s not found on product %d", tag, productId)
+	}
+	return fmt.Errorf("%w with id %d", domain.ErrProductNotFound, productId)
+}
+
+func (fakeRepository *FakeProductRepository) DeleteByIds(productIds []int64, dryRun bool) (int64, []int64, error) {
+	var deleted int64
+	notFoundIds := []int64{}
+	for _, productId := range productIds {
+		if dryRun {
+			if _, err := fakeRepository.GetById(productId); err != nil {
+				notFoundIds = append(notFoundIds, productId)
+				continue
+			}
+			deleted++
+			continue
+		}
+		if err := fakeRepository.DeleteById(productId); err != nil {
+			notFoundIds = append(notFoundIds, productId)
+			continue
+		}
+		deleted++
+	}
+	return deleted, notFoundIds, nil
+}
+
+func (fakeRepository *FakeProductRepository) Find(filter model.ProductFilter) ([]domain.Product, int64, error) {
+	if (filter.MinPrice != nil || filter.MaxPrice != nil) && filter.Currency == "" {
+		return nil, 0, persistence.ErrPriceRangeWithoutCurrency
+	}
+	var matches []domain.Product
+	wantedStatus := filter.Status
+	if wantedStatus == "" {
+		wantedStatus = domain.ProductStatusActive
+	}
+	for _, product := range fakeRepository.products {
+		if wantedStatus != model.ProductStatusAll && product.Status != wantedStatus {
+			continue
+		}
+		if filter.AfterId != nil && product.Id <= *filter.AfterId {
+			continue
+		}
+		if filter.Ids != nil && !slices.Contains(filter.Ids, product.Id) {
+			continue
+		}
+		if filter.Store != "" && product.Store != filter.Store {
+			continue
+		}
+		if filter.CategoryId > 0 && product.CategoryID != filter.CategoryId {
+			continue
+		}
+		if filter.UserId > 0 && fakeRepository.ownerIds[product.Id] != filter.UserId {
+			continue
+		}
+		if filter.Tag != "" && !containsTag(product.Tags, filter.Tag) {
+			continue
+		}
+		if filter.Currency != "" && product.Currency != filter.Currency {
+			continue
+		}
+		if filter.MinPrice != nil && product.Price.Cmp(*filter.MinPrice) < 0 {
+			continue
+		}
+		if filter.MaxPrice != nil && product.Price.Cmp(*filter.MaxPrice) > 0 {
+			continue
+		}
+		if filter.CreatedFrom != nil && fakeRepository.createdAt[product.Id].Before(*filter.CreatedFrom) {
+			continue
+		}
+		if filter.CreatedTo != nil && fakeRepository.createdAt[product.Id].After(*filter.CreatedTo) {
+			continue
+		}
+		if filter.Featured && !product.IsFeatured {
+			continue
+		}
+		if filter.Search != "" &&
+			!strings.Contains(strings.ToLower(product.Name), strings.ToLower(filter.Search)) &&
+			!strings.Contains(strings.ToLower(product.Description), strings.ToLower(filter.Search)) {
+			continue
+		}
+		matches = append(matches, product)
+	}
+	total := int64(len(matches))
+	sortProducts(matches, filter.Sort)
+
+	if filter.Offset >= len(matches) {
+		return []domain.Product{}, total, nil
+	}
+	matches = matches[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matches) {
+		matches = matches[:filter.Limit]
+	}
+	return matches, total, nil
+}
+
+// sortProducts mirrors the repository's sort keys; unknown keys keep insertion order.
+func sortProducts(products []domain.Product, sortKey string) {
+	less := map[string]func(a, b domain.Product) bool{
+		"price_asc":     func(a, b domain.Product) bool { return a.Price.Cmp(b.Price) < 0 },
+		"price_desc":    func(a, b domain.Product) bool { return a.Price.Cmp(b.Price) > 0 },
+		"name_asc":      func(a, b domain.Product) bool { return a.Name < b.Name },
+		"name_desc":     func(a, b domain.Product) bool { return a.Name > b.Name },
+		"discount_desc": func(a, b domain.Product) bool { return a.Discount.Cmp(b.Discount) > 0 },
+		"newest":        func(a, b domain.Product) bool { return a.Id > b.Id },
+	}[sortKey]
+	if less == nil {
+		return
+	}
+	sort.SliceStable(products, func(i, j int) bool { return less(products[i], products[j]) })
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, productTag := range tags {
+		if productTag == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (fakeRepository *FakeProductRepository) CategoryExists(categoryId int64) (bool, error) {
+	return fakeCategoryIds[categoryId], nil
+}
+
+func (fakeRepository *FakeProductRepository) GetBySlug(slug string) (domain.Product, error) {
+	for _, product := range fakeRepository.products {
+		if product.Slug == slug {
+			return product, nil
+		}
+	}
+	return domain.Product{}, fmt.Errorf("%w with slug %s", domain.ErrProductNotFound, slug)
+}
+
+// GetCurrentById, GetCurrentBySlug and GetCurrentByIds match the plain lookups, the fake
+// has no replica.
+func (fakeRepository *FakeProductRepository) GetCurrentById(productId int64) (domain.Product, error) {
+	return fakeRepository.GetById(productId)
+}
+
+func (fakeRepository *FakeProductRepository) GetCurrentBySlug(slug string) (domain.Product, error) {
+	return fakeRepository.GetBySlug(slug)
+}
+
+func (fakeRepository *FakeProductRepository) GetCurrentByIds(productIds []int64) ([]domain.Product, error) {
+	products := []domain.Product{}
+	for _, product := range fakeRepository.products {
+		if slices.Contains(productIds, product.Id) {
+			products = append(products, product)
+		}
+	}
+	return products, nil
+}
+
+func (fakeRepository *FakeProductRepository) GetByIdWithCategory(productId int64) (domain.ProductWithCategory, error) {
+	product, err := fakeRepository.GetById(productId)
+	if err != nil {
+		return domain.ProductWithCategory{}, err
+	}
+	productWithCategory := domain.ProductWithCategory{Product: product}
+	if fakeCategoryIds[product.CategoryID] {
+		productWithCategory.Category = &domain.Category{Id: product.CategoryID, Name: fmt.Sprintf("Category %d", product.CategoryID)}
+	}
+	return productWithCategory, nil
+}
+
+func (fakeRepository *FakeProductRepository) GetProductStats(currency string) (domain.ProductStats, error) {
+	productStats := domain.ProductStats{Currency: currency}
+	stores := map[string]bool{}
+	var sum decimal.Decimal
+	var priced int64
+	for _, product := range fakeRepository.products {
+		productStats.TotalProducts++
+		stores[product.Store] = true
+		if product.Currency == currency {
+			sum = sum.Add(product.Price)
+			priced++
+		}
+		if product.Discount.Sign() > 0 {
+			productStats.DiscountedProducts++
+		}
+	}
+	productStats.DistinctStores = int64(len(stores))
+	if priced > 0 {
+		productStats.AvgPrice = sum.DivInt(priced)
+	}
+	return productStats, nil
+}
+
+func (fakeRepository *FakeProductRepository) CountProductsByCategory(categoryId int64) (int64, error) {
+	var count int64
+	for _, product := range fakeRepository.products {
+		if product.CategoryID == categoryId && product.Status == domain.ProductStatusActive {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (fakeRepository *FakeProductRepository) GetPriceStatsByCategory(categoryId int64, currency string) (domain.PriceStats, error) {
+	priceStats := domain.PriceStats{CategoryId: categoryId, Currency: currency}
+	var sum decimal.Decimal
+	for _, product := range fakeRepository.products {
+		if product.CategoryID != categoryId || product.Status != domain.ProductStatusActive || product.Currency != currency {
+			continue
+		}
+		if priceStats.ProductCount == 0 || product.Price.Cmp(priceStats.MinPrice) < 0 {
+			priceStats.MinPrice = product.Price
+		}
+		if product.Price.Cmp(priceStats.MaxPrice) > 0 {
+			priceStats.MaxPrice = product.Price
+		}
+		sum = sum.Add(product.Price)
+		priceStats.ProductCount++
+	}
+	if priceStats.ProductCount > 0 {
+		priceStats.AvgPrice = sum.DivInt(priceStats.ProductCount)
+	}
+	return priceStats, nil
+}
+
+// GetProductChanges lists changes without ChangeFeedLag, the fake has no transactions to wait for.
+func (fakeRepository *FakeProductRepository) GetProductChanges(after model.ChangeCursor, limit int) ([]domain.ProductChange, error) {
+	isAfter := func(change domain.ProductChange) bool {
+		return change.ChangedAt.After(after.ChangedAt) ||
+			(change.ChangedAt.Equal(after.ChangedAt) && change.ProductId > after.ProductId)
+	}
+	changes := []domain.ProductChange{}
+	for _, product := range fakeRepository.products {
+		changedAt, ok := fakeRepository.updatedAt[product.Id]
+		if !ok {
+			changedAt = fakeRepository.createdAt[product.Id]
+		}
+		if change := (domain.ProductChange{ProductId: product.Id, ChangedAt: changedAt}); isAfter(change) {
+			changes = append(changes, change)
+		}
+	}
+	for productId, deletedAt := range fakeRepository.deletedAt {
+		if change := (domain.ProductChange{ProductId: productId, ChangedAt: deletedAt, Deleted: true}); isAfter(change) {
+			changes = append(changes, change)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if !changes[i].ChangedAt.Equal(changes[j].ChangedAt) {
+			return changes[i].ChangedAt.Before(changes[j].ChangedAt)
+		}
+		return changes[i].ProductId < changes[j].ProductId
+	})
+	if len(changes) > limit {
+		changes = changes[:limit]
+	}
+	return changes, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"product-app/domain"
+)
+
+type FakeReviewRepository struct {
+	reviews []domain.Review
+}
+
+func NewFakeReviewRepository() *FakeReviewRepository {
+	return &FakeReviewRepository{
+		reviews: []domain.Review{},
+	}
+}
+
+// AddReview replaces the user's earlier review of the product, like the upsert in the real repository.
+func (fakeRepository *FakeReviewRepository) AddReview(review domain.Review) (int64, error) {
+	for i, existing := range fakeRepository.reviews {
+		if existing.ProductId == review.ProductId && existing.UserId == review.UserId {
+			review.Id = existing.Id
+			fakeRepository.reviews[i] = review
+			return review.Id, nil
+		}
+	}
+	review.Id = int64(len(fakeRepository.reviews)) + 1
+	fakeRepository.reviews = append(fakeRepository.reviews, review)
+	return review.Id, nil
+}
+
+func (fakeRepository *FakeReviewRepository) GetReviewsByProduct(productId int64) ([]domain.Review, error) {
+	reviews := []domain.Review{}
+	for _, review := range fakeRepository.reviews {
+		if review.ProductId == productId {
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews, nil
+}
+
+func (fakeRepository *FakeReviewRepository) GetAverageRating(productId int64) (float64, error) {
+	total, count := 0, 0
+	for _, review := range fakeRepository.reviews {
+		if review.ProductId == productId {
+			total += review.Rating
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	return float64(total) / float64(count), nil
+}
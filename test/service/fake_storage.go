@@ -0,0 +1,25 @@
+package service
+
+import (
+	"product-app/storage"
+	"time"
+)
+
+type FakeStorage struct {
+	// Presigned holds the keys uploads were signed for, in order
+	Presigned []string
+}
+
+func (fakeStorage *FakeStorage) PresignUpload(key string, contentType string, ttl time.Duration) (storage.SignedUpload, error) {
+	fakeStorage.Presigned = append(fakeStorage.Presigned, key)
+	return storage.SignedUpload{
+		Url:       "https://storage.example.com/upload/" + key + "?signature=fake",
+		Method:    "PUT",
+		Headers:   map[string]string{"Content-Type": contentType},
+		ExpiresAt: time.Now().Add(ttl),
+	}, nil
+}
+
+func (fakeStorage *FakeStorage) PublicUrl(key string) string {
+	return "https://cdn.example.com/" + key
+}
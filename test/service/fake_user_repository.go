@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"product-app/domain"
+	"strings"
+	"time"
+)
+
+type FakeUserRepository struct {
+	users             []domain.User
+	updatePasswordErr error
+	touchLastLoginErr error
+}
+
+func NewFakeUserRepository() *FakeUserRepository {
+	return &FakeUserRepository{
+		users: []domain.User{},
+	}
+}
+
+func (fakeRepository *FakeUserRepository) GetById(userId int64) (domain.User, error) {
+	for _, user := range fakeRepository.users {
+		if user.Id == userId {
+			return user, nil
+		}
+	}
+	return domain.User{}, fmt.Errorf("%w with id %d", domain.ErrUserNotFound, userId)
+}
+
+func (fakeRepository *FakeUserRepository) GetByUsername(username string) (domain.User, error) {
+	for _, user := range fakeRepository.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return domain.User{}, fmt.Errorf("user with username %s not found", username)
+}
+
+func (fakeRepository *FakeUserRepository) GetByEmail(email string) (domain.User, error) {
+	for _, user := range fakeRepository.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+	return domain.User{}, fmt.Errorf("user with email %s not found", email)
+}
+
+func (fakeRepository *FakeUserRepository) GetByUsernameOrEmail(identifier string) (domain.User, error) {
+	for _, user := range fakeRepository.users {
+		if user.Username == identifier {
+			return user, nil
+		}
+	}
+	for _, user := range fakeRepository.users {
+		if strings.EqualFold(user.Email, identifier) {
+			return user, nil
+		}
+	}
+	return domain.User{}, fmt.Errorf("user not found with username or email %s", identifier)
+}
+
+func (fakeRepository *FakeUserRepository) ExistsByUsername(username string) (bool, error) {
+	_, err := fakeRepository.GetByUsername(username)
+	return err == nil, nil
+}
+
+func (fakeRepository *FakeUserRepository) ExistsByEmail(email string) (bool, error) {
+	for _, user := range fakeRepository.users {
+		if strings.EqualFold(user.Email, email) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (fakeRepository *FakeUserRepository) AddUser(user domain.User) (int64, error) {
+	user.Id = int64(len(fakeRepository.users)) + 1
+	fakeRepository.users = append(fakeRepository.users, user)
+	return user.Id, nil
+}
+
+func (fakeRepository *FakeUserRepository) UpdateUser(user domain.User) error {
+	for i, existing := range fakeRepository.users {
+		if existing.Id == user.Id {
+			user.PasswordHash = existing.PasswordHash
+			fakeRepository.users[i] = user
+			return nil
+		}
+	}
+	return fmt.Errorf("user with id %d not found", user.Id)
+}
+
+func (fakeRepository *FakeUserRepository) UpdatePassword(userId int64, hashedPassword string) error {
+	if fakeRepository.updatePasswordErr != nil {
+		return fakeRepository.updatePasswordErr
+	}
+	for i, existing := range fakeRepository.users {
+		if existing.Id == userId {
+			fakeRepository.users[i].PasswordHash = hashedPassword
+			return nil
+		}
+	}
+	return fmt.Errorf("user with id %d not found", userId)
+}
+
+func (fakeRepository *FakeUserRepository) TouchLastLogin(userId int64) error {
+	if fakeRepository.touchLastLoginErr != nil {
+		return fakeRepository.touchLastLoginErr
+	}
+	for i, existing := range fakeRepository.users {
+		if existing.Id == userId {
+			now := time.Now()
+			fakeRepository.users[i].LastLoginAt = &now
+			return nil
+		}
+	}
+	return fmt.Errorf("user with id %d not found", userId)
+}
+
+func (fakeRepository *FakeUserRepository) DeleteById(userId int64) error {
+	for i, existing := range fakeRepository.users {
+		if existing.Id == userId {
+			fakeRepository.users = append(fakeRepository.users[:i], fakeRepository.users[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("user not found")
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"product-app/common/decimal"
+	"product-app/domain"
+	"product-app/service"
+	"product-app/service/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_IdempotentProductCreation(t *testing.T) {
+	productCreate := model.ProductCreate{
+		Name:       "Ütü",
+		Price:      decimal.NewFromInt(2000),
+		Store:      "ABC TECH",
+		CategoryID: 1,
+	}
+
+	t.Run("Should not create duplicate product for repeated key", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository([]domain.Product{})
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+		idempotencyService := service.NewIdempotencyService(NewFakeIdempotencyRepository(), productService, time.Hour)
+
+		firstId, replayed, err := idempotencyService.CreateProduct("key-1", productCreate)
+		assert.NoError(t, err)
+		assert.False(t, replayed)
+
+		secondId, replayed, err := idempotencyService.CreateProduct("key-1", productCreate)
+		assert.NoError(t, err)
+		assert.True(t, replayed)
+		assert.Equal(t, firstId, secondId)
+		assert.Len(t, productService.GetAllProducts(), 1)
+	})
+
+	t.Run("Should reject reused key with different body", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository([]domain.Product{})
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+		idempotencyService := service.NewIdempotencyService(NewFakeIdempotencyRepository(), productService, time.Hour)
+
+		_, _, err := idempotencyService.CreateProduct("key-1", productCreate)
+		assert.NoError(t, err)
+
+		changed := productCreate
+		changed.Price = decimal.NewFromInt(2500)
+		_, _, err = idempotencyService.CreateProduct("key-1", changed)
+		assert.ErrorIs(t, err, service.ErrIdempotencyKeyReused)
+		assert.Len(t, productService.GetAllProducts(), 1)
+	})
+
+	t.Run("Should create again once the key has expired", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository([]domain.Product{})
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+		idempotencyRepo := NewFakeIdempotencyRepository()
+		idempotencyService := service.NewIdempotencyService(idempotencyRepo, productService, time.Hour)
+
+		_, _, err := idempotencyService.CreateProduct("key-1", productCreate)
+		assert.NoError(t, err)
+
+		expired := idempotencyRepo.keys["key-1"]
+		expired.CreatedAt = time.Now().Add(-2 * time.Hour)
+		idempotencyRepo.keys["key-1"] = expired
+
+		_, replayed, err := idempotencyService.CreateProduct("key-1", productCreate)
+		assert.NoError(t, err)
+		assert.False(t, replayed)
+		assert.Len(t, productService.GetAllProducts(), 2)
+	})
+
+	t.Run("Should release the key when the product cannot be created", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository([]domain.Product{})
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+		idempotencyRepo := NewFakeIdempotencyRepository()
+		idempotencyService := service.NewIdempotencyService(idempotencyRepo, productService, time.Hour)
+
+		invalid := productCreate
+		invalid.Name = ""
+		_, _, err := idempotencyService.CreateProduct("key-1", invalid)
+		assert.Error(t, err)
+		assert.NotContains(t, idempotencyRepo.keys, "key-1")
+
+		_, replayed, err := idempotencyService.CreateProduct("key-1", productCreate)
+		assert.NoError(t, err)
+		assert.False(t, replayed)
+		assert.Len(t, productService.GetAllProducts(), 1)
+	})
+
+	t.Run("Should answer retries as in progress when saving the key fails", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository([]domain.Product{})
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+		idempotencyRepo := NewFakeIdempotencyRepository()
+		idempotencyRepo.completeErr = errFakeCompleteKey
+		idempotencyService := service.NewIdempotencyService(idempotencyRepo, productService, time.Hour)
+
+		productId, _, err := idempotencyService.CreateProduct("key-1", productCreate)
+		assert.NoError(t, err)
+		assert.NotZero(t, productId)
+
+		_, _, err = idempotencyService.CreateProduct("key-1", productCreate)
+		assert.ErrorIs(t, err, service.ErrIdempotencyKeyInProgress)
+		assert.Len(t, productService.GetAllProducts(), 1)
+	})
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"product-app/common/decimal"
+	"product-app/domain"
+	"product-app/service"
+	"product-app/service/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ImageUpload(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+	fakeStorage := &FakeStorage{}
+	imageUploadService := service.NewImageUploadService(fakeStorage, productService, 15*time.Minute)
+
+	t.Run("Should sign an upload under the product prefix", func(t *testing.T) {
+		imageUpload, err := imageUploadService.CreateUpload(1, "image/webp")
+		assert.NoError(t, err)
+		assert.Regexp(t, `^products/1/[0-9a-f]{32}\.webp$`, imageUpload.Key)
+		assert.Equal(t, "https://cdn.example.com/"+imageUpload.Key, imageUpload.PublicUrl)
+		assert.Equal(t, "image/webp", imageUpload.Upload.Headers["Content-Type"])
+	})
+
+	t.Run("Should reject non-image content types", func(t *testing.T) {
+		_, err := imageUploadService.CreateUpload(1, "application/pdf")
+		assert.ErrorIs(t, err, service.ErrUnsupportedUploadType)
+	})
+
+	t.Run("Should not sign uploads for unknown products", func(t *testing.T) {
+		signed := len(fakeStorage.Presigned)
+		_, err := imageUploadService.CreateUpload(99, "image/png")
+		assert.Error(t, err)
+		assert.Len(t, fakeStorage.Presigned, signed)
+	})
+
+	t.Run("Should record the public URL on confirmation", func(t *testing.T) {
+		imageUpload, err := imageUploadService.CreateUpload(1, "image/png")
+		assert.NoError(t, err)
+
+		width := 1200
+		assert.NoError(t, imageUploadService.ConfirmUpload(1, model.UploadConfirm{Key: imageUpload.Key, Width: &width}))
+
+		product, _ := productService.GetById(1)
+		assert.Contains(t, product.ImageUrls, imageUpload.PublicUrl)
+	})
+
+	t.Run("Should reject a key signed for another product", func(t *testing.T) {
+		imageUpload, err := imageUploadService.CreateUpload(2, "image/jpeg")
+		assert.NoError(t, err)
+
+		err = imageUploadService.ConfirmUpload(1, model.UploadConfirm{Key: imageUpload.Key})
+		assert.ErrorIs(t, err, service.ErrInvalidUploadKey)
+	})
+
+	t.Run("Should reject keys it did not generate", func(t *testing.T) {
+		err := imageUploadService.ConfirmUpload(1, model.UploadConfirm{Key: "products/1/../../secrets.png"})
+		assert.ErrorIs(t, err, service.ErrInvalidUploadKey)
+	})
+}
@@ -1,11 +1,17 @@
 package service
 
 import (
+	"encoding/json"
+	"math"
 	"os"
+	"product-app/common/decimal"
 	"product-app/domain"
+	"product-app/persistence"
 	"product-app/service"
 	"product-app/service/model"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -20,11 +26,11 @@ func TestMain(m *testing.M) {
 func Test_ShouldGetAllProducts(t *testing.T) {
 	t.Run("ShouldGetAllProducts", func(t *testing.T) {
 		initialProducts := []domain.Product{
-			{Id: 1, Name: "AirFryer", Price: 1000.0, Store: "ABC TECH", CategoryID: 1},
-			{Id: 2, Name: "Ütü", Price: 4000.0, Store: "ABC TECH", CategoryID: 1},
+			{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(1000), Store: "ABC TECH", CategoryID: 1},
+			{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(4000), Store: "ABC TECH", CategoryID: 1},
 		}
 		fakeRepo := NewFakeProductRepository(initialProducts)
-		productService := service.NewProductService(fakeRepo)
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
 
 		actualProducts := productService.GetAllProducts()
 		assert.Equal(t, 2, len(actualProducts))
@@ -34,12 +40,12 @@ func Test_ShouldGetAllProducts(t *testing.T) {
 func Test_WhenNoValidationErrorOccurred_ShouldAddProduct(t *testing.T) {
 	t.Run("WhenNoValidationErrorOccurred_ShouldAddProduct", func(t *testing.T) {
 		fakeRepo := NewFakeProductRepository([]domain.Product{})
-		productService := service.NewProductService(fakeRepo)
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
 
-		err := productService.Add(model.ProductCreate{
+		_, err := productService.Add(model.ProductCreate{
 			Name:       "Ütü",
-			Price:      2000.0,
-			Discount:   50,
+			Price:      decimal.NewFromInt(2000),
+			Discount:   decimal.NewFromInt(50),
 			Store:      "ABC TECH",
 			CategoryID: 1,
 		}) // userId parameter added
@@ -48,6 +54,7 @@ func Test_WhenNoValidationErrorOccurred_ShouldAddProduct(t *testing.T) {
 
 		actualProducts := productService.GetAllProducts()
 		assert.Equal(t, 1, len(actualProducts))
+		assert.Equal(t, int64(1), actualProducts[0].CategoryID)
 	})
 }
 
@@ -55,12 +62,12 @@ func Test_WhenDiscountIsHigherThan70_ShouldNotAddProduct(t *testing.T) {
 	t.Run("WhenDiscountIsHigherThan70_ShouldNotAddProduct", func(t *testing.T) {
 
 		fakeRepo := NewFakeProductRepository([]domain.Product{})
-		productService := service.NewProductService(fakeRepo)
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
 
-		err := productService.Add(model.ProductCreate{
+		_, err := productService.Add(model.ProductCreate{
 			Name:       "Ütü",
-			Price:      2000.0,
-			Discount:   75,
+			Price:      decimal.NewFromInt(2000),
+			Discount:   decimal.NewFromInt(75),
 			Store:      "ABC TECH",
 			CategoryID: 1,
 		}) // userId parameter added
@@ -69,14 +76,406 @@ func Test_WhenDiscountIsHigherThan70_ShouldNotAddProduct(t *testing.T) {
 		assert.Equal(t, 0, len(actualProducts))
 
 		assert.Error(t, err)
-		assert.Equal(t, "discount must be between 0 and 70 percent", err.Error())
+		assert.Equal(t, "discount must be between 0 and 70.00 percent", err.Error())
+	})
+}
+
+func Test_ConfiguredMaxDiscount(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+	})
+	productService := service.NewProductService(fakeRepo, service.ProductSettings{MaxDiscount: decimal.NewFromInt(90), DefaultCurrency: service.DefaultCurrency})
+
+	t.Run("Should accept discounts up to the configured ceiling", func(t *testing.T) {
+		_, err := productService.Add(model.ProductCreate{Name: "Ütü", Price: decimal.NewFromInt(2000), Discount: decimal.NewFromInt(85), Store: "Outlet"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should reject discounts above the configured ceiling on create", func(t *testing.T) {
+		_, err := productService.Add(model.ProductCreate{Name: "Ütü", Price: decimal.NewFromInt(2000), Discount: decimal.NewFromInt(95), Store: "Outlet"})
+		assert.EqualError(t, err, "discount must be between 0 and 90.00 percent")
+	})
+
+	t.Run("Should reject discounts above the configured ceiling on patch", func(t *testing.T) {
+		discount := decimal.NewFromInt(95)
+		err := productService.UpdateProductPartial(1, model.ProductPatch{Version: 0, Discount: &discount}, 0)
+		assert.EqualError(t, err, "discount must be between 0 and 90.00 percent")
+	})
+}
+
+func Test_FinalPriceNotPositive(t *testing.T) {
+	seventyPercent := decimal.NewFromInt(70)
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "Kalem", Price: decimal.MustParse("0.02"), Discount: seventyPercent, Store: "ABC TECH"},
+		{Id: 2, Name: "Silgi", Price: decimal.MustParse("0.01"), Store: "ABC TECH"},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	t.Run("Should accept the smallest price a 70 percent discount keeps above zero", func(t *testing.T) {
+		_, err := productService.Add(model.ProductCreate{Name: "Kalem", Price: decimal.MustParse("0.02"), Discount: seventyPercent, Store: "ABC TECH"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should reject a 70 percent discount that rounds the price to zero on create", func(t *testing.T) {
+		_, err := productService.Add(model.ProductCreate{Name: "Silgi", Price: decimal.MustParse("0.01"), Discount: seventyPercent, Store: "ABC TECH"})
+		assert.ErrorIs(t, err, model.ErrFinalPriceNotPositive)
+		assert.EqualError(t, err, "discounted price must be greater than zero: a 70.00 percent discount on 0.01 leaves 0.00")
+
+		var validationError *model.ValidationError
+		assert.ErrorAs(t, err, &validationError)
+		assert.Equal(t, model.CodeFinalPriceNotPositive, validationError.Fields[0].Code)
+	})
+
+	t.Run("Should reject a full discount when the ceiling allows it", func(t *testing.T) {
+		lenientService := service.NewProductService(NewFakeProductRepository([]domain.Product{}), service.ProductSettings{MaxDiscount: decimal.NewFromInt(100), DefaultCurrency: service.DefaultCurrency})
+		_, err := lenientService.Add(model.ProductCreate{Name: "Hediye", Price: decimal.NewFromInt(10), Discount: decimal.NewFromInt(100), Store: "ABC TECH"})
+		assert.ErrorIs(t, err, model.ErrFinalPriceNotPositive)
+	})
+
+	t.Run("Should check a patched discount against the stored price", func(t *testing.T) {
+		err := productService.UpdateProductPartial(2, model.ProductPatch{Discount: &seventyPercent}, 0)
+		assert.ErrorIs(t, err, model.ErrFinalPriceNotPositive)
+	})
+
+	t.Run("Should check a patched price against the stored discount", func(t *testing.T) {
+		tinyPrice := decimal.MustParse("0.01")
+		err := productService.UpdateProductPartial(1, model.ProductPatch{Price: &tinyPrice}, 0)
+		assert.ErrorIs(t, err, model.ErrFinalPriceNotPositive)
+	})
+
+	t.Run("Should check a new price against the stored discount", func(t *testing.T) {
+		err := productService.UpdatePrice(1, decimal.MustParse("0.01"), 0, 1)
+		assert.ErrorIs(t, err, model.ErrFinalPriceNotPositive)
+
+		assert.NoError(t, productService.UpdatePrice(1, decimal.MustParse("0.05"), 0, 1))
+	})
+}
+
+func Test_ProductDescription(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+	})
+	productService := service.NewProductService(fakeRepo, service.ProductSettings{
+		MaxDiscount: service.DefaultMaxDiscount, DefaultCurrency: service.DefaultCurrency, MaxDescriptionLength: 50,
+	})
+
+	t.Run("Should reject a script payload on create", func(t *testing.T) {
+		_, err := productService.Add(model.ProductCreate{Name: "Ütü", Price: decimal.NewFromInt(2000), Store: "Outlet",
+			Description: `<script>alert(1)</script>`})
+		assert.ErrorIs(t, err, model.ErrDescriptionHasMarkup)
+		assert.Len(t, productService.GetAllProducts(), 1)
+	})
+
+	t.Run("Should reject a description over the configured length on patch", func(t *testing.T) {
+		description := strings.Repeat("a", 51)
+		err := productService.UpdateProductPartial(1, model.ProductPatch{Description: &description}, 0)
+		assert.ErrorIs(t, err, model.ErrDescriptionTooLong)
+	})
+
+	t.Run("Should store a plain description unchanged", func(t *testing.T) {
+		productId, err := productService.Add(model.ProductCreate{Name: "Ütü", Price: decimal.NewFromInt(2000), Store: "Outlet",
+			Description: "Steam & dry, 2400W (fast!)"})
+		assert.NoError(t, err)
+		product, _ := productService.GetById(productId)
+		assert.Equal(t, "Steam & dry, 2400W (fast!)", product.Description)
+	})
+}
+
+func Test_NormalizeImageOrder(t *testing.T) {
+	gappedImages := func() []domain.ProductImage {
+		return []domain.ProductImage{
+			{Id: 1, Url: "https://example.com/front.jpg", DisplayOrder: 0},
+			{Id: 3, Url: "https://example.com/back.jpg", DisplayOrder: 5},
+			{Id: 2, Url: "https://example.com/side.jpg", DisplayOrder: 2},
+		}
+	}
+	productService := service.NewProductService(NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", Images: gappedImages()},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH", Images: gappedImages()},
+		{Id: 3, Name: "Lamba", Price: decimal.NewFromInt(500), Store: "XYZ HOME"},
+	}), service.DefaultProductSettings)
+
+	t.Run("Should compact gapped orders and keep the images in order", func(t *testing.T) {
+		images, err := productService.NormalizeImageOrder(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.ProductImage{
+			{Id: 1, Url: "https://example.com/front.jpg", DisplayOrder: 0},
+			{Id: 2, Url: "https://example.com/side.jpg", DisplayOrder: 1},
+			{Id: 3, Url: "https://example.com/back.jpg", DisplayOrder: 2},
+		}, images)
+	})
+
+	t.Run("Should report a missing product", func(t *testing.T) {
+		_, err := productService.NormalizeImageOrder(99)
+		assert.ErrorIs(t, err, domain.ErrProductNotFound)
+	})
+
+	t.Run("Should count only the products it renumbered", func(t *testing.T) {
+		normalized, err := productService.NormalizeAllImageOrders()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), normalized)
+
+		images, _ := productService.GetProductImages(2)
+		for position, image := range images {
+			assert.Equal(t, position, image.DisplayOrder)
+		}
+	})
+}
+
+func Test_DuplicateImageUrls(t *testing.T) {
+	front, back := "https://example.com/front.jpg", "https://example.com/back.jpg"
+
+	t.Run("Should drop repeated urls by default, keeping the first occurrence", func(t *testing.T) {
+		productService := service.NewProductService(NewFakeProductRepository([]domain.Product{}), service.DefaultProductSettings)
+		productId, err := productService.Add(model.ProductCreate{Name: "Ütü", Price: decimal.NewFromInt(2000), Store: "ABC TECH",
+			ImageUrls: []string{front, back, front}})
+		assert.NoError(t, err)
+		product, _ := productService.GetById(productId)
+		assert.Equal(t, []string{front, back}, product.ImageUrls)
+
+		err = productService.AddProductImages(productId, []model.ImageCreate{{Url: "https://example.com/side.jpg"}, {Url: "https://example.com/side.jpg"}})
+		assert.NoError(t, err)
+		product, _ = productService.GetById(productId)
+		assert.Equal(t, []string{front, back, "https://example.com/side.jpg"}, product.ImageUrls)
+	})
+
+	t.Run("Should reject repeated urls when configured", func(t *testing.T) {
+		settings := service.DefaultProductSettings
+		settings.RejectDuplicateImageUrls = true
+		productService := service.NewProductService(NewFakeProductRepository([]domain.Product{
+			{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		}), settings)
+
+		_, err := productService.Add(model.ProductCreate{Name: "Ütü", Price: decimal.NewFromInt(2000), Store: "ABC TECH",
+			ImageUrls: []string{front, back, front}})
+		assert.ErrorIs(t, err, model.ErrDuplicateImageUrl)
+		var validationError *model.ValidationError
+		assert.ErrorAs(t, err, &validationError)
+		assert.Equal(t, "image_urls", validationError.Fields[0].Field)
+		assert.Equal(t, model.CodeDuplicateImageUrl, validationError.Fields[0].Code)
+		assert.Len(t, productService.GetAllProducts(), 1)
+
+		err = productService.AddProductImages(1, []model.ImageCreate{{Url: front}, {Url: front}})
+		assert.ErrorIs(t, err, model.ErrInvalidImage)
+		assert.ErrorIs(t, err, model.ErrDuplicateImageUrl)
+		images, _ := productService.GetProductImages(1)
+		assert.Empty(t, images)
+	})
+}
+
+func Test_ProductCurrency(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{})
+	productService := service.NewProductService(fakeRepo, service.ProductSettings{MaxDiscount: decimal.NewFromInt(70), DefaultCurrency: "EUR"})
+
+	t.Run("Should use the configured default currency when none is given", func(t *testing.T) {
+		productId, err := productService.Add(model.ProductCreate{Name: "Ütü", Price: decimal.NewFromInt(2000), Store: "ABC TECH"})
+		assert.NoError(t, err)
+		product, _ := productService.GetById(productId)
+		assert.Equal(t, "EUR", product.Currency)
+	})
+
+	t.Run("Should keep a supported currency", func(t *testing.T) {
+		productId, err := productService.Add(model.ProductCreate{Name: "Ütü", Price: decimal.NewFromInt(60), Currency: "USD", Store: "ABC TECH"})
+		assert.NoError(t, err)
+		product, _ := productService.GetById(productId)
+		assert.Equal(t, "USD", product.Currency)
+	})
+
+	t.Run("Should reject an unsupported currency", func(t *testing.T) {
+		_, err := productService.Add(model.ProductCreate{Name: "Ütü", Price: decimal.NewFromInt(60), Currency: "usd", Store: "ABC TECH"})
+		assert.ErrorIs(t, err, model.ErrUnsupportedCurrency)
+		assert.Equal(t, 2, len(productService.GetAllProducts()))
+	})
+}
+
+func Test_AddProduct_UncategorizedFallback(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{})
+	settings := service.DefaultProductSettings
+	settings.UncategorizedCategoryId = 3
+	productService := service.NewProductService(fakeRepo, settings)
+
+	t.Run("Should put a product without a category into Uncategorized", func(t *testing.T) {
+		productId, err := productService.Add(model.ProductCreate{Name: "Ütü", Price: decimal.NewFromInt(2000), Store: "ABC TECH"})
+		assert.NoError(t, err)
+		product, _ := productService.GetById(productId)
+		assert.Equal(t, int64(3), product.CategoryID)
+	})
+
+	t.Run("Should keep an explicit category", func(t *testing.T) {
+		productId, err := productService.Add(model.ProductCreate{Name: "Lambader", Price: decimal.NewFromInt(2000), Store: "ABC TECH", CategoryID: 1})
+		assert.NoError(t, err)
+		product, _ := productService.GetById(productId)
+		assert.Equal(t, int64(1), product.CategoryID)
+	})
+
+	t.Run("Should move a product patched with category 0 into Uncategorized", func(t *testing.T) {
+		productId, err := productService.Add(model.ProductCreate{Name: "Kettle", Price: decimal.NewFromInt(900), Store: "ABC TECH", CategoryID: 1})
+		assert.NoError(t, err)
+		noCategory := int64(0)
+		assert.NoError(t, productService.UpdateProductPartial(productId, model.ProductPatch{CategoryID: &noCategory}, 0))
+		product, _ := productService.GetById(productId)
+		assert.Equal(t, int64(3), product.CategoryID)
+	})
+}
+
+func Test_ProductSlug(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	t.Run("Should generate a slug from the name and suffix collisions", func(t *testing.T) {
+		firstId, err := productService.Add(model.ProductCreate{Name: "Air Fryer XL", Price: decimal.NewFromInt(3000), Store: "ABC TECH"})
+		assert.NoError(t, err)
+		secondId, err := productService.Add(model.ProductCreate{Name: "Air Fryer XL", Price: decimal.NewFromInt(2500), Store: "Outlet"})
+		assert.NoError(t, err)
+
+		first, _ := productService.GetById(firstId)
+		second, _ := productService.GetById(secondId)
+		assert.Equal(t, "air-fryer-xl", first.Slug)
+		assert.Equal(t, "air-fryer-xl-2", second.Slug)
+	})
+
+	t.Run("Should use an explicit slug and reject one that is taken", func(t *testing.T) {
+		productId, err := productService.Add(model.ProductCreate{Name: "Ütü", Slug: "buharli-utu", Price: decimal.NewFromInt(1500), Store: "ABC TECH"})
+		assert.NoError(t, err)
+		product, _ := productService.GetBySlug("buharli-utu")
+		assert.Equal(t, productId, product.Id)
+
+		_, err = productService.Add(model.ProductCreate{Name: "Ütü", Slug: "buharli-utu", Price: decimal.NewFromInt(1500), Store: "Outlet"})
+		assert.ErrorIs(t, err, service.ErrSlugTaken)
+	})
+
+	t.Run("Should keep the slug on rename unless one is given", func(t *testing.T) {
+		name := "Air Fryer Pro"
+		assert.NoError(t, productService.UpdateProductPartial(1, model.ProductPatch{Version: 0, Name: &name}, 0))
+		product, _ := productService.GetById(1)
+		assert.Equal(t, "air-fryer-xl", product.Slug)
+
+		slug := "air-fryer-pro"
+		assert.NoError(t, productService.UpdateProductPartial(1, model.ProductPatch{Version: 1, Slug: &slug}, 0))
+		product, _ = productService.GetById(1)
+		assert.Equal(t, "air-fryer-pro", product.Slug)
+
+		taken := "buharli-utu"
+		err := productService.UpdateProductPartial(1, model.ProductPatch{Version: 2, Slug: &taken}, 0)
+		assert.ErrorIs(t, err, service.ErrSlugTaken)
+	})
+
+	t.Run("Should reject a malformed slug", func(t *testing.T) {
+		_, err := productService.Add(model.ProductCreate{Name: "Lambader", Slug: "Lamba Der", Price: decimal.NewFromInt(2000), Store: "ABC TECH"})
+		assert.EqualError(t, err, `slug "Lamba Der" must contain only lowercase letters, digits and single hyphens`)
+	})
+}
+
+func Test_UniqueProductSlug(t *testing.T) {
+	testCases := []struct {
+		description   string
+		existingSlugs []string
+		expected      string
+	}{
+		{description: "free slug", existingSlugs: []string{"kettle"}, expected: "air-fryer"},
+		{description: "one collision", existingSlugs: []string{"air-fryer"}, expected: "air-fryer-2"},
+		{description: "several collisions", existingSlugs: []string{"air-fryer", "air-fryer-2", "air-fryer-3"}, expected: "air-fryer-4"},
+		{description: "gap in suffixes", existingSlugs: []string{"air-fryer", "air-fryer-3"}, expected: "air-fryer-2"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			products := make([]domain.Product, len(testCase.existingSlugs))
+			for i, slug := range testCase.existingSlugs {
+				products[i] = domain.Product{Id: int64(i) + 1, Name: "Existing", Slug: slug, Price: decimal.NewFromInt(1000), Store: "ABC TECH"}
+			}
+			fakeRepo := NewFakeProductRepository(products)
+			productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+			productId, err := productService.Add(model.ProductCreate{Name: "Air Fryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"})
+			assert.NoError(t, err)
+			product, _ := productService.GetById(productId)
+			assert.Equal(t, testCase.expected, product.Slug)
+		})
+	}
+}
+
+func Test_GeneratedProductSlug(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "Air Fryer XL", expected: "air-fryer-xl"},
+		{name: "Ütü", expected: "utu"},
+		{name: "Buharlı Ütü", expected: "buharli-utu"},
+		{name: "Çamaşır Makinesi", expected: "camasir-makinesi"},
+		{name: "Doğal Sabun", expected: "dogal-sabun"},
+		{name: "Öğle Yemeği", expected: "ogle-yemegi"},
+		{name: "İSTANBUL ŞEMSİYE", expected: "istanbul-semsiye"},
+		{name: "Çay   Bardağı", expected: "cay-bardagi"},
+		{name: "日本", expected: "product"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			fakeRepo := NewFakeProductRepository([]domain.Product{})
+			productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+			productId, err := productService.Add(model.ProductCreate{Name: testCase.name, Price: decimal.NewFromInt(1000), Store: "ABC TECH"})
+			assert.NoError(t, err)
+			product, _ := productService.GetById(productId)
+			assert.Equal(t, testCase.expected, product.Slug)
+		})
+	}
+}
+
+func Test_WhenCategoryDoesNotExist_ShouldNotAddProduct(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	t.Run("Should reject unknown category", func(t *testing.T) {
+		_, err := productService.Add(model.ProductCreate{
+			Name:       "Ütü",
+			Price:      decimal.NewFromInt(2000),
+			Store:      "ABC TECH",
+			CategoryID: 99,
+		})
+		assert.ErrorIs(t, err, domain.ErrCategoryNotFound)
+		assert.Equal(t, 0, len(productService.GetAllProducts()))
+	})
+
+	t.Run("Should reject a negative category id before looking it up", func(t *testing.T) {
+		_, err := productService.Add(model.ProductCreate{
+			Name:       "Ütü",
+			Price:      decimal.NewFromInt(2000),
+			Store:      "ABC TECH",
+			CategoryID: -1,
+		})
+		assert.ErrorIs(t, err, model.ErrNegativeCategoryId)
+		assert.NotErrorIs(t, err, domain.ErrCategoryNotFound)
+		var validationError *model.ValidationError
+		assert.ErrorAs(t, err, &validationError)
+		assert.Equal(t, "category_id", validationError.Fields[0].Field)
+		assert.Equal(t, model.CodeNegativeCategoryId, validationError.Fields[0].Code)
+		assert.Equal(t, 0, len(productService.GetAllProducts()))
+	})
+
+	t.Run("Should allow uncategorized product", func(t *testing.T) {
+		_, err := productService.Add(model.ProductCreate{
+			Name:  "Ütü",
+			Price: decimal.NewFromInt(2000),
+			Store: "ABC TECH",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(productService.GetAllProducts()))
+	})
+
+	t.Run("Should reject a negative category id on patch", func(t *testing.T) {
+		categoryId := int64(-3)
+		err := productService.UpdateProductPartial(1, model.ProductPatch{CategoryID: &categoryId}, 0)
+		assert.ErrorIs(t, err, model.ErrNegativeCategoryId)
 	})
 }
 
 func Test_FakeProductRepository_GetById(t *testing.T) {
 	initialProducts := []domain.Product{
-		{Id: 1, Name: "Product A", Price: 10.0, Store: "Store X", CategoryID: 1},
-		{Id: 2, Name: "Product B", Price: 20.0, Store: "Store Y", CategoryID: 1},
+		{Id: 1, Name: "Product A", Price: decimal.NewFromInt(10), Store: "Store X", CategoryID: 1},
+		{Id: 2, Name: "Product B", Price: decimal.NewFromInt(20), Store: "Store Y", CategoryID: 1},
 	}
 	fakeRepo := NewFakeProductRepository(initialProducts)
 
@@ -94,12 +493,76 @@ func Test_FakeProductRepository_GetById(t *testing.T) {
 	})
 }
 
+func Test_GetByIds(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+		{Id: 3, Name: "Lambader", Price: decimal.NewFromInt(2000), Store: "Dekorasyon Sarayı"},
+		{Id: 4, Name: "Tost Makinesi", Price: decimal.NewFromInt(1200), Store: "ABC TECH", Status: domain.ProductStatusDraft},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	ids := func(products []domain.Product) []int64 {
+		productIds := make([]int64, len(products))
+		for i, product := range products {
+			productIds[i] = product.Id
+		}
+		return productIds
+	}
+
+	t.Run("Should return products in the order the ids were given", func(t *testing.T) {
+		products, err := productService.GetByIds([]int64{3, 1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{3, 1, 2}, ids(products))
+		assert.Equal(t, "Lambader", products[0].Name)
+	})
+
+	t.Run("Should skip unknown and inactive ids without reordering the rest", func(t *testing.T) {
+		products, err := productService.GetByIds([]int64{2, 99, 4, 1})
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{2, 1}, ids(products))
+	})
+
+	t.Run("Should list a repeated id once", func(t *testing.T) {
+		products, err := productService.GetByIds([]int64{2, 3, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{2, 3}, ids(products))
+	})
+
+	t.Run("Should reject an empty id list", func(t *testing.T) {
+		_, err := productService.GetByIds(nil)
+		assert.ErrorIs(t, err, service.ErrEmptyIdList)
+	})
+}
+
+func Test_GetByIdWithCategory(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(1000), Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Lambader", Price: decimal.NewFromInt(2000), Store: "Dekorasyon Sarayı"},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	t.Run("Should embed the product category", func(t *testing.T) {
+		product, err := productService.GetByIdWithCategory(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "AirFryer", product.Name)
+		assert.NotNil(t, product.Category)
+		assert.Equal(t, int64(1), product.Category.Id)
+	})
+
+	t.Run("Should return a nil category for uncategorized products", func(t *testing.T) {
+		product, err := productService.GetByIdWithCategory(2)
+		assert.NoError(t, err)
+		assert.Nil(t, product.Category)
+	})
+}
+
 func Test_FakeProductRepository_DeleteById(t *testing.T) {
 	t.Run("Should delete product by ID if found", func(t *testing.T) {
 		initialProducts := []domain.Product{
-			{Id: 1, Name: "Product A", Price: 10.0, Store: "Store X", CategoryID: 1},
-			{Id: 2, Name: "Product B", Price: 20.0, Store: "Store Y", CategoryID: 1},
-			{Id: 3, Name: "Product C", Price: 30.0, Store: "Store X", CategoryID: 1},
+			{Id: 1, Name: "Product A", Price: decimal.NewFromInt(10), Store: "Store X", CategoryID: 1},
+			{Id: 2, Name: "Product B", Price: decimal.NewFromInt(20), Store: "Store Y", CategoryID: 1},
+			{Id: 3, Name: "Product C", Price: decimal.NewFromInt(30), Store: "Store X", CategoryID: 1},
 		}
 		fakeRepo := NewFakeProductRepository(initialProducts)
 
@@ -107,14 +570,14 @@ func Test_FakeProductRepository_DeleteById(t *testing.T) {
 		assert.NoError(t, err)
 		products := fakeRepo.GettAllProducts()
 		assert.Len(t, products, 2)
-		assert.NotContains(t, products, domain.Product{Id: 2, Name: "Product B", Price: 20.0, Store: "Store Y", CategoryID: 1})
+		assert.NotContains(t, products, domain.Product{Id: 2, Name: "Product B", Price: decimal.NewFromInt(20), Store: "Store Y", CategoryID: 1})
 	})
 
 	t.Run("Should return error if product not found", func(t *testing.T) {
 		initialProducts := []domain.Product{
-			{Id: 1, Name: "Product A", Price: 10.0, Store: "Store X", CategoryID: 1},
-			{Id: 2, Name: "Product B", Price: 20.0, Store: "Store Y", CategoryID: 1},
-			{Id: 3, Name: "Product C", Price: 30.0, Store: "Store X", CategoryID: 1},
+			{Id: 1, Name: "Product A", Price: decimal.NewFromInt(10), Store: "Store X", CategoryID: 1},
+			{Id: 2, Name: "Product B", Price: decimal.NewFromInt(20), Store: "Store Y", CategoryID: 1},
+			{Id: 3, Name: "Product C", Price: decimal.NewFromInt(30), Store: "Store X", CategoryID: 1},
 		}
 		fakeRepo := NewFakeProductRepository(initialProducts)
 
@@ -128,14 +591,14 @@ func Test_FakeProductRepository_DeleteById(t *testing.T) {
 
 func Test_FakeProductRepository_UpdatePrice(t *testing.T) {
 	initialProducts := []domain.Product{
-		{Id: 1, Name: "Product A", Price: 10.0, Store: "Store X", CategoryID: 1},
-		{Id: 2, Name: "Product B", Price: 20.0, Store: "Store Y", CategoryID: 1},
+		{Id: 1, Name: "Product A", Price: decimal.NewFromInt(10), Store: "Store X", CategoryID: 1},
+		{Id: 2, Name: "Product B", Price: decimal.NewFromInt(20), Store: "Store Y", CategoryID: 1},
 	}
 	fakeRepo := NewFakeProductRepository(initialProducts)
 
 	t.Run("Should update price if product found", func(t *testing.T) {
-		newPrice := float32(25.0)
-		err := fakeRepo.UpdatePrice(2, newPrice)
+		newPrice := decimal.NewFromInt(25)
+		err := fakeRepo.UpdatePrice(2, newPrice, 0, 1)
 		assert.NoError(t, err)
 		product, err := fakeRepo.GetById(2)
 		assert.NoError(t, err)
@@ -143,12 +606,879 @@ func Test_FakeProductRepository_UpdatePrice(t *testing.T) {
 	})
 
 	t.Run("Should return error if product not found", func(t *testing.T) {
-		newPrice := float32(30.0)
-		err := fakeRepo.UpdatePrice(3, newPrice)
+		newPrice := decimal.NewFromInt(30)
+		err := fakeRepo.UpdatePrice(3, newPrice, 0, 1)
 		assert.Error(t, err)
 		assert.Equal(t, "Product not found with id 3", err.Error())
 		product, err := fakeRepo.GetById(1)
 		assert.NoError(t, err)
-		assert.Equal(t, float32(10.0), product.Price)
+		assert.Equal(t, decimal.NewFromInt(10), product.Price)
+	})
+}
+
+func Test_ProductAvailability(t *testing.T) {
+	inStock, soldOut := 3, 0
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", Stock: &inStock},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH", Stock: &soldOut},
+		{Id: 3, Name: "Lambader", Price: decimal.NewFromInt(2000), Store: "ABC TECH", Stock: &inStock, Status: domain.ProductStatusDiscontinued},
+		{Id: 4, Name: "Tost Makinesi", Price: decimal.NewFromInt(1200), Store: "ABC TECH"},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	t.Run("Should report availability from status and stock", func(t *testing.T) {
+		for productId, expected := range map[int64]domain.Availability{
+			1: {Available: true, Reason: domain.AvailabilityInStock},
+			2: {Available: false, Reason: domain.AvailabilityOutOfStock},
+			3: {Available: false, Reason: domain.AvailabilityNotActive},
+			4: {Available: true, Reason: domain.AvailabilityInStock},
+		} {
+			availability, err := productService.GetAvailability(productId)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, availability, "product %d", productId)
+		}
+	})
+
+	t.Run("Should follow stock updates", func(t *testing.T) {
+		restocked := 5
+		assert.NoError(t, productService.UpdateProductPartial(2, model.ProductPatch{Stock: &restocked}, 0))
+		availability, _ := productService.GetAvailability(2)
+		assert.True(t, availability.Available)
+	})
+
+	t.Run("Should reject negative stock", func(t *testing.T) {
+		negative := -1
+		_, err := productService.Add(model.ProductCreate{Name: "Kettle", Price: decimal.NewFromInt(800), Store: "ABC TECH", Stock: &negative})
+		assert.ErrorIs(t, err, model.ErrNegativeStock)
+		product, _ := productService.GetById(1)
+		assert.ErrorIs(t, productService.UpdateProductPartial(1, model.ProductPatch{Version: product.Version, Stock: &negative}, 0), model.ErrNegativeStock)
+	})
+
+	t.Run("Should return not found for an unknown product", func(t *testing.T) {
+		_, err := productService.GetAvailability(99)
+		assert.ErrorIs(t, err, domain.ErrProductNotFound)
+	})
+}
+
+func Test_UpdateProductPartial(t *testing.T) {
+	initialProducts := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(1000), Store: "ABC TECH", CategoryID: 1},
+	}
+
+	t.Run("Should update only provided fields", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository(append([]domain.Product{}, initialProducts...))
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+		newPrice := decimal.NewFromInt(1500)
+		err := productService.UpdateProductPartial(1, model.ProductPatch{Price: &newPrice}, 0)
+		assert.NoError(t, err)
+
+		product, _ := productService.GetById(1)
+		assert.Equal(t, newPrice, product.Price)
+		assert.Equal(t, "AirFryer", product.Name)
+	})
+
+	t.Run("Should record a patched price in the price history", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository(append([]domain.Product{}, initialProducts...))
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+		newPrice := decimal.NewFromInt(1500)
+		assert.NoError(t, productService.UpdateProductPartial(1, model.ProductPatch{Price: &newPrice}, 7))
+
+		history, err := productService.GetPriceHistory(1)
+		assert.NoError(t, err)
+		assert.Len(t, history, 1)
+		assert.Equal(t, decimal.NewFromInt(1000), history[0].OldPrice)
+		assert.Equal(t, newPrice, history[0].NewPrice)
+		assert.Equal(t, int64(7), *history[0].ChangedBy)
+	})
+
+	t.Run("Should not record history when the price is not patched", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository(append([]domain.Product{}, initialProducts...))
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+		name := "Ütü"
+		assert.NoError(t, productService.UpdateProductPartial(1, model.ProductPatch{Name: &name}, 7))
+
+		history, err := productService.GetPriceHistory(1)
+		assert.NoError(t, err)
+		assert.Empty(t, history)
+	})
+
+	t.Run("Should return error when no fields provided", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository(append([]domain.Product{}, initialProducts...))
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+		err := productService.UpdateProductPartial(1, model.ProductPatch{}, 0)
+		assert.ErrorIs(t, err, service.ErrEmptyProductPatch)
+	})
+
+	t.Run("Should validate provided fields", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository(append([]domain.Product{}, initialProducts...))
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+		discount := decimal.NewFromInt(90)
+		err := productService.UpdateProductPartial(1, model.ProductPatch{Discount: &discount}, 0)
+		assert.Error(t, err)
+		assert.Equal(t, "discount must be between 0 and 70.00 percent", err.Error())
+	})
+
+	t.Run("Should return not found for missing product", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository(append([]domain.Product{}, initialProducts...))
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+		name := "Ütü"
+		err := productService.UpdateProductPartial(5, model.ProductPatch{Name: &name}, 0)
+		assert.ErrorIs(t, err, domain.ErrProductNotFound)
+	})
+}
+
+func Test_ProductTags(t *testing.T) {
+	t.Run("Should normalize and dedupe tags on add", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository([]domain.Product{})
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+		_, err := productService.Add(model.ProductCreate{
+			Name:       "Ütü",
+			Price:      decimal.NewFromInt(2000),
+			Store:      "ABC TECH",
+			CategoryID: 1,
+			Tags:       []string{" Eco ", "eco", "NEW", ""},
+		})
+		assert.NoError(t, err)
+
+		product, _ := fakeRepo.GetById(1)
+		assert.Equal(t, []string{"eco", "new"}, product.Tags)
+	})
+
+	t.Run("Should filter products by normalized tag", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository([]domain.Product{
+			{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(1000), Store: "ABC TECH", Tags: []string{"eco"}},
+			{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(4000), Store: "ABC TECH", Tags: []string{"new"}},
+		})
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+		products, err := productService.GetAllProductsByTag(" ECO")
+		assert.NoError(t, err)
+		assert.Len(t, products, 1)
+		assert.Equal(t, int64(1), products[0].Id)
+	})
+
+	t.Run("Should reject empty tag filter", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository([]domain.Product{})
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+		_, err := productService.GetAllProductsByTag("  ")
+		assert.ErrorIs(t, err, service.ErrEmptyTag)
+	})
+}
+
+func Test_UpdateWithStaleVersion_ShouldConflict(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(1000), Store: "ABC TECH", CategoryID: 1, Version: 1},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	assert.NoError(t, productService.UpdatePrice(1, decimal.NewFromInt(1200), 1, 1))
+
+	err := productService.UpdatePrice(1, decimal.NewFromInt(1300), 1, 1)
+	assert.ErrorIs(t, err, domain.ErrProductVersionConflict)
+
+	newName := "Air Fryer XL"
+	err = productService.UpdateProductPartial(1, model.ProductPatch{Version: 1, Name: &newName}, 0)
+	assert.ErrorIs(t, err, domain.ErrProductVersionConflict)
+
+	product, _ := productService.GetById(1)
+	assert.Equal(t, decimal.NewFromInt(1200), product.Price)
+	assert.Equal(t, 2, product.Version)
+}
+
+func Test_GetPriceHistory(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(1000), Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH", CategoryID: 1},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	t.Run("Should record every price change, most recent first", func(t *testing.T) {
+		assert.NoError(t, productService.UpdatePrice(1, decimal.NewFromInt(800), 0, 7))
+		assert.NoError(t, productService.UpdatePrice(1, decimal.NewFromInt(900), 1, 7))
+
+		history, err := productService.GetPriceHistory(1)
+		assert.NoError(t, err)
+		assert.Len(t, history, 2)
+		assert.Equal(t, decimal.NewFromInt(800), history[0].OldPrice)
+		assert.Equal(t, decimal.NewFromInt(900), history[0].NewPrice)
+		assert.Equal(t, decimal.NewFromInt(1000), history[1].OldPrice)
+		assert.Equal(t, int64(7), *history[1].ChangedBy)
+	})
+
+	t.Run("Should return an empty history for an unchanged product", func(t *testing.T) {
+		history, err := productService.GetPriceHistory(2)
+		assert.NoError(t, err)
+		assert.NotNil(t, history)
+		assert.Empty(t, history)
+	})
+
+	t.Run("Should fail for an unknown product", func(t *testing.T) {
+		_, err := productService.GetPriceHistory(99)
+		assert.Error(t, err)
+	})
+}
+
+func Test_DeleteByIds(t *testing.T) {
+	t.Run("Should delete existing ids and report missing ones", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository([]domain.Product{
+			{Id: 1, Name: "Product A", Price: decimal.NewFromInt(10), Store: "Store X", CategoryID: 1},
+			{Id: 2, Name: "Product B", Price: decimal.NewFromInt(20), Store: "Store Y", CategoryID: 1},
+			{Id: 3, Name: "Product C", Price: decimal.NewFromInt(30), Store: "Store X", CategoryID: 1},
+		})
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+		deleted, notFoundIds, err := productService.DeleteByIds([]int64{1, 3, 7}, false)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+		assert.Equal(t, []int64{7}, notFoundIds)
+		assert.Len(t, productService.GetAllProducts(), 1)
+	})
+
+	t.Run("Should report without deleting in dry run", func(t *testing.T) {
+		fakeRepo := NewFakeProductRepository([]domain.Product{
+			{Id: 1, Name: "Product A", Price: decimal.NewFromInt(10), Store: "Store X", CategoryID: 1},
+			{Id: 2, Name: "Product B", Price: decimal.NewFromInt(20), Store: "Store Y", CategoryID: 1},
+		})
+		productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+		deleted, notFoundIds, err := productService.DeleteByIds([]int64{1, 7}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+		assert.Equal(t, []int64{7}, notFoundIds)
+		assert.Len(t, productService.GetAllProducts(), 2)
+	})
+
+	t.Run("Should reject an empty id list", func(t *testing.T) {
+		productService := service.NewProductService(NewFakeProductRepository([]domain.Product{}), service.DefaultProductSettings)
+
+		_, _, err := productService.DeleteByIds([]int64{}, false)
+		assert.ErrorIs(t, err, service.ErrEmptyIdList)
+	})
+}
+
+func Test_FindProducts_CombinedFilters(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "Buharlı Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Seyahat Ütüsü", Price: decimal.NewFromInt(900), Store: "ABC TECH", CategoryID: 2},
+		{Id: 3, Name: "Kablosuz Ütü", Price: decimal.NewFromInt(2500), Store: "XYZ HOME", CategoryID: 1},
+		{Id: 4, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	t.Run("Should combine a text query and a category", func(t *testing.T) {
+		products, total, err := productService.FindProducts(model.ProductFilter{Search: " ütü ", CategoryId: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, []int64{1, 3}, productIds(products))
+	})
+
+	t.Run("Should combine all three filters", func(t *testing.T) {
+		products, total, err := productService.FindProducts(model.ProductFilter{Search: "ütü", Store: "ABC TECH", CategoryId: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, []int64{1}, productIds(products))
+	})
+
+	t.Run("Should count matches beyond the page", func(t *testing.T) {
+		products, total, err := productService.FindProducts(model.ProductFilter{Store: "ABC TECH", PageRequest: model.PageRequest{Limit: 1}})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Len(t, products, 1)
+	})
+}
+
+func productIds(products []domain.Product) []int64 {
+	ids := make([]int64, len(products))
+	for i, product := range products {
+		ids[i] = product.Id
+	}
+	return ids
+}
+
+func Test_GetProductsByCategoryId_Pagination(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "Product A", Price: decimal.NewFromInt(10), Store: "Store X", CategoryID: 1},
+		{Id: 2, Name: "Product B", Price: decimal.NewFromInt(20), Store: "Store Y", CategoryID: 1},
+		{Id: 3, Name: "Product C", Price: decimal.NewFromInt(30), Store: "Store X", CategoryID: 1},
+		{Id: 4, Name: "Product D", Price: decimal.NewFromInt(40), Store: "Store X", CategoryID: 2},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	t.Run("Should return requested page and total", func(t *testing.T) {
+		products, total, err := productService.GetProductsByCategoryId(1, model.PriceRange{}, model.PageRequest{Limit: 2, Offset: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Len(t, products, 2)
+		assert.Equal(t, int64(2), products[0].Id)
+	})
+
+	t.Run("Should reject unsupported sort", func(t *testing.T) {
+		_, _, err := productService.GetProductsByCategoryId(1, model.PriceRange{}, model.PageRequest{Sort: "color"})
+		assert.ErrorIs(t, err, service.ErrInvalidPageRequest)
+	})
+
+	t.Run("Should reject negative offset", func(t *testing.T) {
+		_, _, err := productService.GetProductsByCategoryId(1, model.PriceRange{}, model.PageRequest{Offset: -1})
+		assert.ErrorIs(t, err, service.ErrInvalidPageRequest)
+	})
+
+	t.Run("Should return an empty page for an existing category without products", func(t *testing.T) {
+		products, total, err := productService.GetProductsByCategoryId(3, model.PriceRange{}, model.PageRequest{})
+		assert.NoError(t, err)
+		assert.Empty(t, products)
+		assert.Equal(t, int64(0), total)
+	})
+
+	t.Run("Should report an unknown category as not found", func(t *testing.T) {
+		_, _, err := productService.GetProductsByCategoryId(99, model.PriceRange{}, model.PageRequest{})
+		assert.ErrorIs(t, err, domain.ErrCategoryNotFound)
+	})
+}
+
+func Test_GetProductsByStore(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "Product A", Price: decimal.NewFromInt(10), Store: "Store X"},
+		{Id: 2, Name: "Product B", Price: decimal.NewFromInt(20), Store: "Store Y"},
+		{Id: 3, Name: "Product C", Price: decimal.NewFromInt(30), Store: "Store X"},
+		{Id: 4, Name: "Product D", Price: decimal.NewFromInt(40), Store: "Store X"},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	t.Run("Should count the whole store, not just the page", func(t *testing.T) {
+		products, total, err := productService.GetProductsByStore("Store X", model.PageRequest{Limit: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Len(t, products, 2)
+		assert.Equal(t, int64(1), products[0].Id)
+		assert.Equal(t, int64(3), products[1].Id)
+	})
+
+	t.Run("Should return an empty page past the end", func(t *testing.T) {
+		products, total, err := productService.GetProductsByStore("Store X", model.PageRequest{Limit: 2, Offset: 4})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Empty(t, products)
+	})
+
+	t.Run("Should reject unsupported sort", func(t *testing.T) {
+		_, _, err := productService.GetProductsByStore("Store X", model.PageRequest{Sort: "color"})
+		assert.ErrorIs(t, err, service.ErrInvalidPageRequest)
+	})
+
+	t.Run("Should return an empty, non-nil list for an unknown store", func(t *testing.T) {
+		products, total, err := productService.GetProductsByStore("Store Z", model.PageRequest{Limit: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), total)
+		assert.NotNil(t, products)
+		assert.Empty(t, products)
+
+		allProducts := productService.GetAllProductsByStore("Store Z")
+		assert.NotNil(t, allProducts)
+		serialized, err := json.Marshal(allProducts)
+		assert.NoError(t, err)
+		assert.Equal(t, "[]", string(serialized))
+	})
+}
+
+func Test_GetProductsAfter(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "Product A", Price: decimal.NewFromInt(10), Store: "Store X"},
+		{Id: 2, Name: "Product B", Price: decimal.NewFromInt(20), Store: "Store Y"},
+		{Id: 3, Name: "Product C", Price: decimal.NewFromInt(30), Store: "Store X"},
+		{Id: 4, Name: "Product D", Price: decimal.NewFromInt(40), Store: "Store X", Status: domain.ProductStatusDraft},
+		{Id: 5, Name: "Product E", Price: decimal.NewFromInt(50), Store: "Store X"},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	t.Run("Should walk every active product once, page by page", func(t *testing.T) {
+		var seen []int64
+		afterId := int64(0)
+		for {
+			products, next, err := productService.GetProductsAfter("", afterId, 2)
+			assert.NoError(t, err)
+			for _, product := range products {
+				seen = append(seen, product.Id)
+			}
+			if next == nil {
+				break
+			}
+			afterId = *next
+		}
+		assert.Equal(t, []int64{1, 2, 3, 5}, seen)
+	})
+
+	t.Run("Should not skip rows inserted before the cursor", func(t *testing.T) {
+		first, next, err := productService.GetProductsAfter("Store X", 0, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), first[0].Id)
+
+		// With offsets, a product inserted ahead of the page would push "Product C" to the next one
+		_, err = fakeRepo.AddProduct(domain.Product{Name: "Product F", Price: decimal.NewFromInt(60), Store: "Store X", Status: domain.ProductStatusActive})
+		assert.NoError(t, err)
+
+		second, _, err := productService.GetProductsAfter("Store X", *next, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), second[0].Id)
+	})
+
+	t.Run("Should reject a non-positive limit and a negative cursor", func(t *testing.T) {
+		_, _, err := productService.GetProductsAfter("", 0, 0)
+		assert.ErrorIs(t, err, service.ErrInvalidPageRequest)
+		_, _, err = productService.GetProductsAfter("", -1, 10)
+		assert.ErrorIs(t, err, service.ErrInvalidPageRequest)
+	})
+}
+
+func Test_GetProductsByCategoryId_PriceRange(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Currency: "TRY", Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Currency: "TRY", Store: "ABC TECH", CategoryID: 1},
+		{Id: 3, Name: "Çamaşır Makinesi", Price: decimal.NewFromInt(10000), Currency: "TRY", Store: "ABC TECH", CategoryID: 1},
+		{Id: 4, Name: "Kettle", Price: decimal.NewFromInt(50), Currency: "USD", Store: "ABC TECH", CategoryID: 1},
+		{Id: 5, Name: "Lambader", Price: decimal.NewFromInt(2000), Currency: "TRY", Store: "Dekorasyon Sarayı", CategoryID: 2},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+	minPrice, maxPrice := decimal.NewFromInt(1000), decimal.NewFromInt(5000)
+
+	t.Run("Should combine the category and price bounds in the default currency", func(t *testing.T) {
+		products, total, err := productService.GetProductsByCategoryId(1,
+			model.PriceRange{Min: &minPrice, Max: &maxPrice},
+			model.PageRequest{Sort: "price_asc"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, int64(2), products[0].Id)
+		assert.Equal(t, int64(1), products[1].Id)
+	})
+
+	t.Run("Should filter in the requested currency", func(t *testing.T) {
+		products, _, err := productService.GetProductsByCategoryId(1, model.PriceRange{Max: &maxPrice, Currency: "USD"}, model.PageRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, products, 1)
+		assert.Equal(t, int64(4), products[0].Id)
+	})
+
+	t.Run("Should reject a minimum above the maximum", func(t *testing.T) {
+		_, _, err := productService.GetProductsByCategoryId(1, model.PriceRange{Min: &maxPrice, Max: &minPrice}, model.PageRequest{})
+		assert.ErrorIs(t, err, service.ErrInvalidPriceRange)
+	})
+
+	t.Run("Should reject a negative bound", func(t *testing.T) {
+		negative := decimal.NewFromInt(-1)
+		_, _, err := productService.GetProductsByCategoryId(1, model.PriceRange{Min: &negative}, model.PageRequest{})
+		assert.ErrorIs(t, err, service.ErrInvalidPriceRange)
+	})
+
+	t.Run("Should reject an unsupported currency", func(t *testing.T) {
+		_, _, err := productService.GetProductsByCategoryId(1, model.PriceRange{Min: &minPrice, Currency: "JPY"}, model.PageRequest{})
+		assert.ErrorIs(t, err, model.ErrUnsupportedCurrency)
+	})
+}
+
+func Test_GetRelatedProducts(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Discount: decimal.NewFromInt(22), Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Discount: decimal.NewFromInt(10), Store: "ABC TECH", CategoryID: 1},
+		{Id: 3, Name: "Çamaşır Makinesi", Price: decimal.NewFromInt(10000), Discount: decimal.NewFromInt(15), Store: "ABC TECH", CategoryID: 1},
+		{Id: 4, Name: "Lambader", Price: decimal.NewFromInt(2000), Store: "Dekorasyon Sarayı", CategoryID: 2},
+		{Id: 5, Name: "Kitap", Price: decimal.NewFromInt(100), Store: "Kitap Dünyası"},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	t.Run("Should return siblings by highest discount excluding the product", func(t *testing.T) {
+		related, err := productService.GetRelatedProducts(2, 4)
+		assert.NoError(t, err)
+		assert.Len(t, related, 2)
+		assert.Equal(t, int64(1), related[0].Id)
+		assert.Equal(t, int64(3), related[1].Id)
+	})
+
+	t.Run("Should respect the limit", func(t *testing.T) {
+		related, err := productService.GetRelatedProducts(3, 1)
+		assert.NoError(t, err)
+		assert.Len(t, related, 1)
+		assert.Equal(t, int64(1), related[0].Id)
+	})
+
+	t.Run("Should return an empty list without siblings or category", func(t *testing.T) {
+		related, err := productService.GetRelatedProducts(4, 4)
+		assert.NoError(t, err)
+		assert.Empty(t, related)
+
+		related, err = productService.GetRelatedProducts(5, 4)
+		assert.NoError(t, err)
+		assert.NotNil(t, related)
+		assert.Empty(t, related)
+	})
+
+	t.Run("Should reject an out of range limit", func(t *testing.T) {
+		_, err := productService.GetRelatedProducts(1, 0)
+		assert.ErrorIs(t, err, service.ErrInvalidPageRequest)
+	})
+}
+
+func Test_GetNewestProducts(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+		{Id: 3, Name: "Lambader", Price: decimal.NewFromInt(2000), Store: "Dekorasyon Sarayı"},
+	})
+	productService := service.NewProductService(fakeRepo, service.ProductSettings{
+		MaxDiscount: service.DefaultMaxDiscount, DefaultCurrency: service.DefaultCurrency, MaxNewestProducts: 2,
+	})
+
+	t.Run("Should return the newest products first", func(t *testing.T) {
+		newest, err := productService.GetNewestProducts(1)
+		assert.NoError(t, err)
+		assert.Len(t, newest, 1)
+		assert.Equal(t, int64(3), newest[0].Id)
+	})
+
+	t.Run("Should cap the limit at the configured maximum", func(t *testing.T) {
+		newest, err := productService.GetNewestProducts(10)
+		assert.NoError(t, err)
+		assert.Len(t, newest, 2)
+	})
+
+	t.Run("Should return an empty list without products", func(t *testing.T) {
+		emptyService := service.NewProductService(NewFakeProductRepository([]domain.Product{}), service.DefaultProductSettings)
+		newest, err := emptyService.GetNewestProducts(10)
+		assert.NoError(t, err)
+		assert.NotNil(t, newest)
+		assert.Empty(t, newest)
+	})
+
+	t.Run("Should reject a non-positive limit", func(t *testing.T) {
+		_, err := productService.GetNewestProducts(0)
+		assert.ErrorIs(t, err, service.ErrInvalidPageRequest)
+	})
+}
+
+func Test_GetProductsCreatedBetween(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+		{Id: 3, Name: "Lambader", Price: decimal.NewFromInt(2000), Store: "Dekorasyon Sarayı"},
+	}).(*FakeProductRepository)
+	weekStart := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	fakeRepo.SetCreatedAt(1, weekStart.Add(-24*time.Hour))
+	fakeRepo.SetCreatedAt(2, weekStart)
+	fakeRepo.SetCreatedAt(3, weekStart.Add(3*24*time.Hour))
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	t.Run("Should return products created inside the window, bounds included", func(t *testing.T) {
+		products, err := productService.GetProductsCreatedBetween(weekStart, weekStart.Add(3*24*time.Hour))
+		assert.NoError(t, err)
+		assert.Len(t, products, 2)
+		assert.Equal(t, int64(3), products[0].Id)
+		assert.Equal(t, int64(2), products[1].Id)
+	})
+
+	t.Run("Should reject a window that ends before it starts", func(t *testing.T) {
+		_, err := productService.GetProductsCreatedBetween(weekStart, weekStart.Add(-time.Hour))
+		assert.ErrorIs(t, err, service.ErrInvalidDateRange)
+	})
+}
+
+func Test_GetProductChanges(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+		{Id: 3, Name: "Lambader", Price: decimal.NewFromInt(2000), Store: "Dekorasyon Sarayı", Status: domain.ProductStatusDiscontinued},
+		{Id: 4, Name: "Halı", Price: decimal.NewFromInt(4000), Store: "Dekorasyon Sarayı"},
+	}).(*FakeProductRepository)
+	lastSync := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	fakeRepo.SetUpdatedAt(1, lastSync.Add(-time.Hour))
+	fakeRepo.SetUpdatedAt(2, lastSync.Add(2*time.Hour))
+	fakeRepo.SetUpdatedAt(3, lastSync.Add(time.Hour))
+	fakeRepo.SetUpdatedAt(4, lastSync.Add(time.Hour))
+	assert.NoError(t, fakeRepo.DeleteById(4))
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+	since := model.ChangeCursor{ChangedAt: lastSync, ProductId: math.MaxInt64}
+
+	t.Run("Should list changes after the cursor, oldest first", func(t *testing.T) {
+		changes, next, err := productService.GetProductChanges(since, 10)
+		assert.NoError(t, err)
+		assert.Nil(t, next)
+		assert.Len(t, changes, 3)
+
+		assert.Equal(t, int64(3), changes[0].ProductId)
+		assert.Equal(t, int64(2), changes[1].ProductId)
+		assert.False(t, changes[1].Deleted)
+		assert.Equal(t, "Ütü", changes[1].Product.Name)
+		assert.Equal(t, int64(4), changes[2].ProductId)
+	})
+
+	t.Run("Should flag deleted and no longer active products as deleted", func(t *testing.T) {
+		changes, _, err := productService.GetProductChanges(since, 10)
+		assert.NoError(t, err)
+		assert.True(t, changes[0].Deleted)
+		assert.Nil(t, changes[0].Product)
+		assert.True(t, changes[2].Deleted)
+		assert.Nil(t, changes[2].Product)
+	})
+
+	t.Run("Should page with the returned cursor", func(t *testing.T) {
+		first, next, err := productService.GetProductChanges(since, 2)
+		assert.NoError(t, err)
+		assert.Len(t, first, 2)
+		assert.Equal(t, &model.ChangeCursor{ChangedAt: lastSync.Add(2 * time.Hour), ProductId: 2}, next)
+
+		rest, next, err := productService.GetProductChanges(*next, 2)
+		assert.NoError(t, err)
+		assert.Nil(t, next)
+		assert.Len(t, rest, 1)
+		assert.Equal(t, int64(4), rest[0].ProductId)
+	})
+
+	t.Run("Should reject a non-positive limit", func(t *testing.T) {
+		_, _, err := productService.GetProductChanges(since, 0)
+		assert.ErrorIs(t, err, service.ErrInvalidPageRequest)
+	})
+
+	t.Run("Should not report a product the replica has not caught up on as deleted", func(t *testing.T) {
+		productService := service.NewProductService(laggingReplicaRepository{fakeRepo}, service.DefaultProductSettings)
+		changes, _, err := productService.GetProductChanges(since, 10)
+		assert.NoError(t, err)
+		assert.False(t, changes[1].Deleted)
+		assert.Equal(t, "Ütü", changes[1].Product.Name)
+	})
+}
+
+// laggingReplicaRepository answers listings like a replica that has none of the products yet.
+type laggingReplicaRepository struct {
+	persistence.IProductRepository
+}
+
+func (laggingReplicaRepository) Find(model.ProductFilter) ([]domain.Product, int64, error) {
+	return []domain.Product{}, 0, nil
+}
+
+func Test_GetProductStats(t *testing.T) {
+	t.Run("Should count products, stores and discounts", func(t *testing.T) {
+		productService := service.NewProductService(NewFakeProductRepository([]domain.Product{
+			{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Discount: decimal.NewFromInt(10), Store: "ABC TECH"},
+			{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+			{Id: 3, Name: "Lambader", Price: decimal.NewFromInt(1500), Discount: decimal.NewFromInt(5), Store: "Dekorasyon Sarayı"},
+		}), service.DefaultProductSettings)
+
+		productStats, err := productService.GetProductStats("")
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ProductStats{
+			TotalProducts:      3,
+			DistinctStores:     2,
+			Currency:           "TRY",
+			AvgPrice:           decimal.NewFromInt(2000),
+			DiscountedProducts: 2,
+		}, productStats)
+	})
+
+	t.Run("Should average only the prices in the requested currency", func(t *testing.T) {
+		productService := service.NewProductService(NewFakeProductRepository([]domain.Product{
+			{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Currency: "TRY", Store: "ABC TECH"},
+			{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(50), Currency: "EUR", Store: "ABC TECH"},
+			{Id: 3, Name: "Lambader", Price: decimal.NewFromInt(70), Currency: "EUR", Store: "Dekorasyon Sarayı"},
+		}), service.DefaultProductSettings)
+
+		productStats, err := productService.GetProductStats("")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), productStats.TotalProducts)
+		assert.Equal(t, decimal.NewFromInt(3000), productStats.AvgPrice)
+
+		productStats, err = productService.GetProductStats("EUR")
+		assert.NoError(t, err)
+		assert.Equal(t, "EUR", productStats.Currency)
+		assert.Equal(t, decimal.NewFromInt(60), productStats.AvgPrice)
+
+		_, err = productService.GetProductStats("XYZ")
+		assert.ErrorIs(t, err, model.ErrUnsupportedCurrency)
+	})
+
+	t.Run("Should return zeros for an empty catalog", func(t *testing.T) {
+		productService := service.NewProductService(NewFakeProductRepository(nil), service.DefaultProductSettings)
+
+		productStats, err := productService.GetProductStats("")
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ProductStats{Currency: "TRY"}, productStats)
+	})
+}
+
+func Test_GetPriceStatsByCategory(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH", CategoryID: 1},
+		{Id: 3, Name: "Lambader", Price: decimal.NewFromInt(2000), Store: "Dekorasyon Sarayı", CategoryID: 2},
+		{Id: 4, Name: "Kettle", Price: decimal.NewFromInt(40), Currency: "EUR", Store: "ABC TECH", CategoryID: 1},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	t.Run("Should aggregate prices of the category in the default currency", func(t *testing.T) {
+		priceStats, err := productService.GetPriceStatsByCategory(1, "")
+		assert.NoError(t, err)
+		assert.True(t, priceStats.HasProducts())
+		assert.Equal(t, decimal.NewFromInt(1500), priceStats.MinPrice)
+		assert.Equal(t, decimal.NewFromInt(3000), priceStats.MaxPrice)
+		assert.Equal(t, decimal.NewFromInt(2250), priceStats.AvgPrice)
+		assert.Equal(t, int64(2), priceStats.ProductCount)
+		assert.Equal(t, "TRY", priceStats.Currency)
+	})
+
+	t.Run("Should aggregate only the prices in the requested currency", func(t *testing.T) {
+		priceStats, err := productService.GetPriceStatsByCategory(1, "EUR")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), priceStats.ProductCount)
+		assert.Equal(t, decimal.NewFromInt(40), priceStats.MinPrice)
+		assert.Equal(t, decimal.NewFromInt(40), priceStats.MaxPrice)
+
+		_, err = productService.GetPriceStatsByCategory(1, "XYZ")
+		assert.ErrorIs(t, err, model.ErrUnsupportedCurrency)
+	})
+
+	t.Run("Should return zeros for an empty category", func(t *testing.T) {
+		priceStats, err := productService.GetPriceStatsByCategory(3, "")
+		assert.NoError(t, err)
+		assert.False(t, priceStats.HasProducts())
+		assert.Equal(t, decimal.NewFromInt(0), priceStats.MinPrice)
+	})
+
+	t.Run("Should return not found for unknown category", func(t *testing.T) {
+		_, err := productService.GetPriceStatsByCategory(99, "")
+		assert.ErrorIs(t, err, domain.ErrCategoryNotFound)
+	})
+}
+
+func Test_CountProductsByCategory(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH", CategoryID: 1},
+		{Id: 3, Name: "Tost Makinesi", Price: decimal.NewFromInt(1200), Store: "ABC TECH", CategoryID: 1, Status: domain.ProductStatusDraft},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	t.Run("Should count the active products of the category", func(t *testing.T) {
+		count, err := productService.CountProductsByCategory(1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("Should return zero for an empty category", func(t *testing.T) {
+		count, err := productService.CountProductsByCategory(3)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("Should return not found for unknown category", func(t *testing.T) {
+		_, err := productService.CountProductsByCategory(99)
+		assert.ErrorIs(t, err, domain.ErrCategoryNotFound)
+	})
+}
+
+func Test_FeaturedProducts(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH"},
+		{Id: 3, Name: "Lambader", Price: decimal.NewFromInt(2000), Store: "Dekorasyon Sarayı"},
+		{Id: 4, Name: "Tost Makinesi", Price: decimal.NewFromInt(1200), Store: "ABC TECH", Status: domain.ProductStatusDraft},
+	})
+	settings := service.DefaultProductSettings
+	settings.MaxFeaturedProducts = 2
+	productService := service.NewProductService(fakeRepo, settings)
+
+	t.Run("Should list nothing before products are flagged", func(t *testing.T) {
+		featured, err := productService.GetFeaturedProducts()
+		assert.NoError(t, err)
+		assert.Empty(t, featured)
+	})
+
+	t.Run("Should toggle the flag", func(t *testing.T) {
+		assert.NoError(t, productService.SetFeatured(2, true))
+		product, _ := productService.GetById(2)
+		assert.True(t, product.IsFeatured)
+
+		assert.NoError(t, productService.SetFeatured(2, false))
+		product, _ = productService.GetById(2)
+		assert.False(t, product.IsFeatured)
+	})
+
+	t.Run("Should list active featured products up to the configured maximum", func(t *testing.T) {
+		for _, productId := range []int64{1, 2, 3, 4} {
+			assert.NoError(t, productService.SetFeatured(productId, true))
+		}
+		featured, err := productService.GetFeaturedProducts()
+		assert.NoError(t, err)
+		assert.Len(t, featured, 2)
+		for _, product := range featured {
+			assert.True(t, product.IsFeatured)
+			assert.Equal(t, domain.ProductStatusActive, product.Status)
+		}
+	})
+
+	t.Run("Should return not found for an unknown product", func(t *testing.T) {
+		assert.ErrorIs(t, productService.SetFeatured(99, true), domain.ErrProductNotFound)
+	})
+}
+
+func Test_ProductStatus(t *testing.T) {
+	fakeRepo := NewFakeProductRepository([]domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH", CategoryID: 1},
+		{Id: 2, Name: "Ütü", Price: decimal.NewFromInt(1500), Store: "ABC TECH", CategoryID: 1, Status: domain.ProductStatusDiscontinued},
+	})
+	productService := service.NewProductService(fakeRepo, service.DefaultProductSettings)
+
+	t.Run("Should hide discontinued products from listings but keep them by id", func(t *testing.T) {
+		products := productService.GetAllProducts()
+		assert.Len(t, products, 1)
+		assert.Equal(t, int64(1), products[0].Id)
+
+		discontinued, err := productService.GetById(2)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ProductStatusDiscontinued, discontinued.Status)
+	})
+
+	t.Run("Should create active products unless another status is given", func(t *testing.T) {
+		activeId, err := productService.Add(model.ProductCreate{Name: "Lamba", Price: decimal.NewFromInt(500), Store: "ABC TECH", CategoryID: 1})
+		assert.NoError(t, err)
+		draftId, err := productService.Add(model.ProductCreate{Name: "Masa", Price: decimal.NewFromInt(900), Store: "ABC TECH", CategoryID: 1, Status: domain.ProductStatusDraft})
+		assert.NoError(t, err)
+
+		active, _ := productService.GetById(activeId)
+		assert.Equal(t, domain.ProductStatusActive, active.Status)
+		draft, _ := productService.GetById(draftId)
+		assert.Equal(t, domain.ProductStatusDraft, draft.Status)
+	})
+
+	t.Run("Should reject an unknown status", func(t *testing.T) {
+		_, err := productService.Add(model.ProductCreate{Name: "Sandalye", Price: decimal.NewFromInt(400), Store: "ABC TECH", CategoryID: 1, Status: "archived"})
+		assert.ErrorIs(t, err, model.ErrInvalidStatus)
+
+		assert.ErrorIs(t, productService.UpdateProductStatus(1, "archived"), model.ErrInvalidStatus)
+		_, err = productService.GetProductsByStatus("archived")
+		assert.ErrorIs(t, err, model.ErrInvalidStatus)
+	})
+
+	t.Run("Should list by a specific status or all", func(t *testing.T) {
+		discontinued, err := productService.GetProductsByStatus(domain.ProductStatusDiscontinued)
+		assert.NoError(t, err)
+		assert.Len(t, discontinued, 1)
+
+		all, err := productService.GetProductsByStatus(model.ProductStatusAll)
+		assert.NoError(t, err)
+		assert.Len(t, all, 4)
+	})
+
+	t.Run("Should change the status and bump the version", func(t *testing.T) {
+		assert.NoError(t, productService.UpdateProductStatus(2, domain.ProductStatusActive))
+		product, _ := productService.GetById(2)
+		assert.Equal(t, domain.ProductStatusActive, product.Status)
+		assert.Equal(t, 1, product.Version)
+
+		assert.ErrorIs(t, productService.UpdateProductStatus(99, domain.ProductStatusActive), domain.ErrProductNotFound)
 	})
 }
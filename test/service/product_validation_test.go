@@ -0,0 +1,150 @@
+package service
+
+import (
+	"product-app/common/decimal"
+	"product-app/service/model"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ProductCreate_Validate(t *testing.T) {
+	valid := model.ProductCreate{Name: "AirFryer", Price: decimal.NewFromInt(3000), Currency: "TRY", Store: "ABC TECH", Discount: decimal.NewFromInt(20)}
+
+	t.Run("Should accept a valid product", func(t *testing.T) {
+		assert.NoError(t, valid.Validate(decimal.NewFromInt(70)))
+	})
+
+	t.Run("Should keep the existing error messages", func(t *testing.T) {
+		productCreate := valid
+		productCreate.Name = ""
+		assert.EqualError(t, productCreate.Validate(decimal.NewFromInt(70)), "product name is required")
+
+		productCreate = valid
+		productCreate.Price = decimal.NewFromInt(0)
+		assert.EqualError(t, productCreate.Validate(decimal.NewFromInt(70)), "product price must be greater than zero")
+
+		productCreate = valid
+		productCreate.Store = "ABC-TECH"
+		assert.EqualError(t, productCreate.Validate(decimal.NewFromInt(70)), "contains invalid characters (only alphanumeric and space allowed)")
+
+		productCreate = valid
+		productCreate.Discount = decimal.NewFromInt(80)
+		assert.EqualError(t, productCreate.Validate(decimal.NewFromInt(70)), "discount must be between 0 and 70.00 percent")
+	})
+
+	t.Run("Should reject an unsupported currency", func(t *testing.T) {
+		productCreate := valid
+		productCreate.Currency = "XYZ"
+		assert.ErrorIs(t, productCreate.Validate(decimal.NewFromInt(70)), model.ErrUnsupportedCurrency)
+	})
+}
+
+func Test_ProductCreate_ValidationCodes(t *testing.T) {
+	// The codes are part of the API contract; changing one of these expectations breaks clients.
+	valid := model.ProductCreate{Name: "AirFryer", Price: decimal.NewFromInt(3000), Currency: "TRY", Store: "ABC TECH", Discount: decimal.NewFromInt(20)}
+	cases := []struct {
+		name   string
+		modify func(productCreate *model.ProductCreate)
+		field  string
+		code   string
+	}{
+		{"missing name", func(p *model.ProductCreate) { p.Name = "" }, "name", "required"},
+		{"name with symbols", func(p *model.ProductCreate) { p.Name = "Air-Fryer" }, "name", "invalid_characters"},
+		{"bad slug", func(p *model.ProductCreate) { p.Slug = "Air Fryer" }, "slug", "invalid_slug"},
+		{"zero price", func(p *model.ProductCreate) { p.Price = decimal.NewFromInt(0) }, "price", "price_not_positive"},
+		{"unknown currency", func(p *model.ProductCreate) { p.Currency = "XYZ" }, "currency", "unsupported_currency"},
+		{"missing store", func(p *model.ProductCreate) { p.Store = "" }, "store", "required"},
+		{"discount above ceiling", func(p *model.ProductCreate) { p.Discount = decimal.NewFromInt(71) }, "discount", "discount_out_of_range"},
+	}
+	for _, testCase := range cases {
+		t.Run("Should report "+testCase.name, func(t *testing.T) {
+			productCreate := valid
+			testCase.modify(&productCreate)
+
+			var validationError *model.ValidationError
+			assert.ErrorAs(t, productCreate.Validate(decimal.NewFromInt(70)), &validationError)
+			assert.Len(t, validationError.Fields, 1)
+			assert.Equal(t, testCase.field, validationError.Fields[0].Field)
+			assert.Equal(t, testCase.code, validationError.Fields[0].Code)
+		})
+	}
+
+	t.Run("Should report description failures", func(t *testing.T) {
+		var validationError *model.ValidationError
+		assert.ErrorAs(t, model.ValidateDescription("<b>bold</b>", 2000), &validationError)
+		assert.Equal(t, "description_has_markup", validationError.Fields[0].Code)
+
+		assert.ErrorAs(t, model.ValidateDescription("too long", 3), &validationError)
+		assert.Equal(t, "description_too_long", validationError.Fields[0].Code)
+	})
+
+	t.Run("Should collect all failing fields and join their messages", func(t *testing.T) {
+		productCreate := valid
+		productCreate.Price = decimal.NewFromInt(0)
+		productCreate.Discount = decimal.NewFromInt(80)
+		err := productCreate.Validate(decimal.NewFromInt(70))
+
+		var validationError *model.ValidationError
+		assert.ErrorAs(t, err, &validationError)
+		assert.Len(t, validationError.Fields, 2)
+		assert.EqualError(t, err, "product price must be greater than zero; discount must be between 0 and 70.00 percent")
+	})
+}
+
+func Test_ProductPatch_Validate(t *testing.T) {
+	t.Run("Should only validate the fields present", func(t *testing.T) {
+		store := "Outlet"
+		assert.NoError(t, model.ProductPatch{Store: &store}.Validate(decimal.NewFromInt(70)))
+	})
+
+	t.Run("Should reject invalid present fields", func(t *testing.T) {
+		price := decimal.NewFromInt(-1)
+		assert.EqualError(t, model.ProductPatch{Price: &price}.Validate(decimal.NewFromInt(70)), "product price must be greater than zero")
+
+		discount := decimal.NewFromInt(71)
+		assert.EqualError(t, model.ProductPatch{Discount: &discount}.Validate(decimal.NewFromInt(70)), "discount must be between 0 and 70.00 percent")
+	})
+}
+
+func Test_ValidateDescription(t *testing.T) {
+	t.Run("Should keep plain text and punctuation", func(t *testing.T) {
+		assert.NoError(t, model.ValidateDescription("Fast & quiet: 5 < 10 minutes, 2x faster (really!) -> \"best\" deal; 100% steel.", 2000))
+	})
+
+	t.Run("Should reject a script payload", func(t *testing.T) {
+		assert.ErrorIs(t, model.ValidateDescription(`Great fryer<script>alert("xss")</script>`, 2000), model.ErrDescriptionHasMarkup)
+		assert.ErrorIs(t, model.ValidateDescription(`<img src=x onerror=alert(1)>`, 2000), model.ErrDescriptionHasMarkup)
+		assert.ErrorIs(t, model.ValidateDescription(`hidden <!-- comment -->`, 2000), model.ErrDescriptionHasMarkup)
+	})
+
+	t.Run("Should reject an overlong description", func(t *testing.T) {
+		err := model.ValidateDescription(strings.Repeat("a", 2001), 2000)
+		assert.ErrorIs(t, err, model.ErrDescriptionTooLong)
+		assert.Contains(t, err.Error(), "at most 2000 characters")
+	})
+
+	t.Run("Should count characters rather than bytes", func(t *testing.T) {
+		assert.NoError(t, model.ValidateDescription(strings.Repeat("ü", 2000), 2000))
+	})
+}
+
+func Test_ImageCreate_Validate(t *testing.T) {
+	width, height, zero := 1200, 800, 0
+	webp, pdf := "image/webp", "application/pdf"
+
+	t.Run("Should accept an image with or without metadata", func(t *testing.T) {
+		assert.NoError(t, model.ImageCreate{Url: "https://example.com/a.webp"}.Validate())
+		assert.NoError(t, model.ImageCreate{Url: "https://example.com/a.webp", Width: &width, Height: &height, MimeType: &webp}.Validate())
+	})
+
+	t.Run("Should reject non-positive dimensions", func(t *testing.T) {
+		assert.ErrorIs(t, model.ImageCreate{Url: "https://example.com/a.webp", Width: &zero}.Validate(), model.ErrInvalidImage)
+	})
+
+	t.Run("Should reject a non-image MIME type and a missing url", func(t *testing.T) {
+		assert.ErrorIs(t, model.ImageCreate{Url: "https://example.com/a.pdf", MimeType: &pdf}.Validate(), model.ErrInvalidImage)
+		assert.ErrorIs(t, model.ImageCreate{Url: " "}.Validate(), model.ErrInvalidImage)
+	})
+}
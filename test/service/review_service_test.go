@@ -0,0 +1,63 @@
+package service
+
+import (
+	"product-app/common/decimal"
+	"product-app/domain"
+	"product-app/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AddReview(t *testing.T) {
+	initialProducts := []domain.Product{
+		{Id: 1, Name: "AirFryer", Price: decimal.NewFromInt(3000), Store: "ABC TECH"},
+	}
+
+	t.Run("Should accept ratings between 1 and 5", func(t *testing.T) {
+		reviewService := service.NewReviewService(NewFakeReviewRepository(), NewFakeProductRepository(initialProducts))
+
+		for _, rating := range []int{1, 5} {
+			review, err := reviewService.AddReview(1, int64(rating), rating, "")
+			assert.NoError(t, err)
+			assert.NotZero(t, review.Id)
+			assert.Equal(t, rating, review.Rating)
+		}
+	})
+
+	t.Run("Should reject ratings outside 1 to 5", func(t *testing.T) {
+		reviewService := service.NewReviewService(NewFakeReviewRepository(), NewFakeProductRepository(initialProducts))
+
+		for _, rating := range []int{0, 6} {
+			_, err := reviewService.AddReview(1, 7, rating, "")
+			assert.EqualError(t, err, "rating must be between 1 and 5")
+		}
+		reviews, _ := reviewService.GetReviewsByProduct(1)
+		assert.Empty(t, reviews)
+	})
+
+	t.Run("Should replace the user's earlier review", func(t *testing.T) {
+		reviewService := service.NewReviewService(NewFakeReviewRepository(), NewFakeProductRepository(initialProducts))
+
+		first, err := reviewService.AddReview(1, 7, 2, "Too loud")
+		assert.NoError(t, err)
+		second, err := reviewService.AddReview(1, 7, 4, "Got used to it")
+		assert.NoError(t, err)
+		assert.Equal(t, first.Id, second.Id)
+
+		reviews, err := reviewService.GetReviewsByProduct(1)
+		assert.NoError(t, err)
+		assert.Len(t, reviews, 1)
+		assert.Equal(t, 4, reviews[0].Rating)
+		assert.Equal(t, "Got used to it", reviews[0].Comment)
+	})
+
+	t.Run("Should return not found for a missing product", func(t *testing.T) {
+		reviewService := service.NewReviewService(NewFakeReviewRepository(), NewFakeProductRepository(initialProducts))
+
+		_, err := reviewService.AddReview(5, 7, 4, "")
+		assert.ErrorIs(t, err, domain.ErrProductNotFound)
+		_, err = reviewService.GetReviewsByProduct(5)
+		assert.ErrorIs(t, err, domain.ErrProductNotFound)
+	})
+}
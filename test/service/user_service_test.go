@@ -0,0 +1,168 @@
+package service
+
+import (
+	"errors"
+	"product-app/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var lowCostHashParams = service.PasswordHashParams{Memory: 8 * 1024, Iterations: 1, Parallelism: 1}
+var targetHashParams = service.PasswordHashParams{Memory: 16 * 1024, Iterations: 2, Parallelism: 1}
+
+func registerLowCostUser(t *testing.T, fakeRepo *FakeUserRepository) {
+	lowCostService := service.NewUserService(fakeRepo, lowCostHashParams)
+	_, err := lowCostService.Register("demo", "demo@example.com", "secret123", "Demo", "User")
+	assert.NoError(t, err)
+}
+
+func Test_Login_ShouldRehashLowCostPassword(t *testing.T) {
+	fakeRepo := NewFakeUserRepository()
+	registerLowCostUser(t, fakeRepo)
+	oldHash := fakeRepo.users[0].PasswordHash
+	assert.True(t, strings.Contains(oldHash, "m=8192,t=1,"))
+
+	userService := service.NewUserService(fakeRepo, targetHashParams)
+
+	t.Run("Should upgrade the stored hash after a successful login", func(t *testing.T) {
+		_, err := userService.Login("demo", "secret123")
+		assert.NoError(t, err)
+
+		newHash := fakeRepo.users[0].PasswordHash
+		assert.NotEqual(t, oldHash, newHash)
+		assert.True(t, strings.Contains(newHash, "m=16384,t=2,"))
+	})
+
+	t.Run("Should still accept the password with the upgraded hash", func(t *testing.T) {
+		_, err := userService.Login("demo@example.com", "secret123")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should not rehash on a failed login", func(t *testing.T) {
+		fakeRepo := NewFakeUserRepository()
+		registerLowCostUser(t, fakeRepo)
+		oldHash := fakeRepo.users[0].PasswordHash
+
+		_, err := service.NewUserService(fakeRepo, targetHashParams).Login("demo", "wrong-password")
+		assert.Error(t, err)
+		assert.Equal(t, oldHash, fakeRepo.users[0].PasswordHash)
+	})
+}
+
+func Test_Login_WhenRehashFails_ShouldStillSucceed(t *testing.T) {
+	fakeRepo := NewFakeUserRepository()
+	registerLowCostUser(t, fakeRepo)
+	oldHash := fakeRepo.users[0].PasswordHash
+	fakeRepo.updatePasswordErr = errors.New("database unavailable")
+
+	user, err := service.NewUserService(fakeRepo, targetHashParams).Login("demo", "secret123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "demo", user.Username)
+	assert.Equal(t, oldHash, fakeRepo.users[0].PasswordHash)
+}
+
+func Test_Login(t *testing.T) {
+	fakeRepo := NewFakeUserRepository()
+	userService := service.NewUserService(fakeRepo, lowCostHashParams)
+	_, err := userService.Register("demo", "Demo@Example.com", "secret123", "Demo", "User")
+	assert.NoError(t, err)
+
+	t.Run("Should login by username", func(t *testing.T) {
+		user, err := userService.Login("demo", "secret123")
+		assert.NoError(t, err)
+		assert.Equal(t, "demo", user.Username)
+	})
+
+	t.Run("Should login by email regardless of case", func(t *testing.T) {
+		user, err := userService.Login("demo@example.com", "secret123")
+		assert.NoError(t, err)
+		assert.Equal(t, "demo", user.Username)
+	})
+
+	t.Run("Should not reveal whether the account exists", func(t *testing.T) {
+		_, unknownErr := userService.Login("nobody", "secret123")
+		_, wrongPasswordErr := userService.Login("demo", "wrong-password")
+		assert.ErrorIs(t, unknownErr, service.ErrInvalidCredentials)
+		assert.ErrorIs(t, wrongPasswordErr, service.ErrInvalidCredentials)
+	})
+}
+
+func Test_Register_EmailCase(t *testing.T) {
+	fakeRepo := NewFakeUserRepository()
+	userService := service.NewUserService(fakeRepo, lowCostHashParams)
+
+	t.Run("Should store the email lowercased", func(t *testing.T) {
+		user, err := userService.Register("bob", "Bob@Example.com", "secret123", "Bob", "User")
+		assert.NoError(t, err)
+		assert.Equal(t, "bob@example.com", user.Email)
+		assert.Equal(t, "bob@example.com", fakeRepo.users[0].Email)
+	})
+
+	t.Run("Should reject an email that differs only in case", func(t *testing.T) {
+		_, err := userService.Register("robert", "BOB@example.com", "secret123", "Robert", "User")
+		assert.EqualError(t, err, "email already exists")
+		assert.Len(t, fakeRepo.users, 1)
+	})
+
+	t.Run("Should lowercase the email on update", func(t *testing.T) {
+		user := fakeRepo.users[0]
+		user.Email = "Bob.New@Example.com"
+		assert.NoError(t, userService.UpdateUser(user))
+		assert.Equal(t, "bob.new@example.com", fakeRepo.users[0].Email)
+	})
+}
+
+func Test_Login_ShouldTrackLastLogin(t *testing.T) {
+	fakeRepo := NewFakeUserRepository()
+	userService := service.NewUserService(fakeRepo, lowCostHashParams)
+	_, err := userService.Register("demo", "demo@example.com", "secret123", "Demo", "User")
+	assert.NoError(t, err)
+	assert.Nil(t, fakeRepo.users[0].LastLoginAt)
+
+	_, err = userService.Login("demo", "secret123")
+	assert.NoError(t, err)
+	firstLogin := *fakeRepo.users[0].LastLoginAt
+
+	time.Sleep(time.Millisecond)
+	user, err := userService.Login("demo", "secret123")
+	assert.NoError(t, err)
+	assert.True(t, fakeRepo.users[0].LastLoginAt.After(firstLogin))
+	assert.NotNil(t, user.LastLoginAt)
+
+	t.Run("Should still login when the update fails", func(t *testing.T) {
+		fakeRepo.touchLastLoginErr = errors.New("database unavailable")
+		_, err := userService.Login("demo", "secret123")
+		assert.NoError(t, err)
+	})
+}
+
+func Test_CheckAvailability(t *testing.T) {
+	fakeRepo := NewFakeUserRepository()
+	userService := service.NewUserService(fakeRepo, lowCostHashParams)
+	_, err := userService.Register("demo", "demo@example.com", "secret123", "Demo", "User")
+	assert.NoError(t, err)
+
+	t.Run("Should report taken and free values", func(t *testing.T) {
+		usernameAvailable, emailAvailable, err := userService.CheckAvailability("demo", "other@example.com")
+		assert.NoError(t, err)
+		assert.False(t, *usernameAvailable)
+		assert.True(t, *emailAvailable)
+	})
+
+	t.Run("Should match emails case-insensitively", func(t *testing.T) {
+		_, emailAvailable, err := userService.CheckAvailability("", "DEMO@example.com")
+		assert.NoError(t, err)
+		assert.False(t, *emailAvailable)
+	})
+
+	t.Run("Should not check empty values", func(t *testing.T) {
+		usernameAvailable, emailAvailable, err := userService.CheckAvailability("", " ")
+		assert.NoError(t, err)
+		assert.Nil(t, usernameAvailable)
+		assert.Nil(t, emailAvailable)
+	})
+}